@@ -3,15 +3,26 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
+	"github.com/charmbracelet/x/term"
+
 	"backup-tui/internal/backup"
 	"backup-tui/internal/cloud"
 	"backup-tui/internal/config"
+	"backup-tui/internal/setup"
+	"backup-tui/internal/stats"
 	"backup-tui/internal/tui"
 	"backup-tui/internal/util"
 )
@@ -29,6 +40,7 @@ const (
 	ExitBackupError  = 2
 	ExitSyncError    = 3
 	ExitRestoreError = 4
+	ExitCancelled    = 5
 )
 
 func main() {
@@ -40,6 +52,9 @@ func main() {
 		showHelp     bool
 		showVer      bool
 		useBubbletea bool
+		readData     bool
+		strict       bool
+		profile      string
 	)
 
 	flag.BoolVar(&verbose, "v", false, "Enable verbose output")
@@ -52,6 +67,9 @@ func main() {
 	flag.BoolVar(&showHelp, "help", false, "Show help")
 	flag.BoolVar(&showVer, "version", false, "Show version")
 	flag.BoolVar(&useBubbletea, "bubbletea", false, "Use new Bubbletea TUI (experimental)")
+	flag.BoolVar(&readData, "read-data", false, "Read all pack data when verifying a restored repository")
+	flag.BoolVar(&strict, "strict", false, "Refuse to run when the configuration has problems")
+	flag.StringVar(&profile, "profile", "", "Apply the [profile:NAME] section of the config")
 
 	flag.Parse()
 
@@ -72,34 +90,58 @@ func main() {
 		command = args[0]
 	}
 
-	// Find config file
-	var err error
-	if configPath == "" {
-		configPath, err = config.FindConfigFile()
-		if err != nil && command != "generate-config" {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			fmt.Fprintf(os.Stderr, "Run '%s generate-config' to create a template\n", Name)
-			os.Exit(ExitConfigError)
-		}
-	}
+	// Signals cancel the running operation; stacks are restarted before exiting
+	ctx, stop := signalContext()
+	defer stop()
 
 	// Handle commands that don't need config
 	switch command {
 	case "generate-config":
 		generateConfigTemplate()
 		os.Exit(ExitSuccess)
+	case "init":
+		runInit(ctx, setupPath(configPath))
+		os.Exit(ExitSuccess)
 	case "help":
 		showUsage()
 		os.Exit(ExitSuccess)
 	}
 
+	// Find config file
+	var err error
+	if configPath == "" {
+		configPath, err = config.FindConfigFile()
+		switch {
+		case err != nil && command == "":
+			// First run of the TUI: set up a config in the current directory
+			configPath = setupPath("")
+			runFirstRun(ctx, configPath)
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Run '%s init' to create one, or '%s generate-config' for a template\n", Name, Name)
+			os.Exit(ExitConfigError)
+		}
+	}
+
 	// Load configuration
-	cfg, err := config.Load(configPath)
+	cfg, err := config.LoadProfile(configPath, profile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
 		os.Exit(ExitConfigError)
 	}
 
+	// Unknown keys, malformed values and the like; validate lists them itself
+	if issues := cfg.Lint(); len(issues) > 0 && command != "validate" {
+		if strict {
+			for _, issue := range issues {
+				fmt.Fprintf(os.Stderr, "Config: %s\n", issue)
+			}
+			fmt.Fprintf(os.Stderr, "Error: %d configuration problem(s), refusing to run in strict mode\n", len(issues))
+			os.Exit(ExitConfigError)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %d configuration problem(s), run '%s validate' for details\n", len(issues), Name)
+	}
+
 	// Initialize logging
 	logPath := filepath.Join(cfg.LogDir, "backup-tui.log")
 	if err := util.InitDefaultLogger(logPath, verbose); err != nil {
@@ -111,32 +153,54 @@ func main() {
 	switch command {
 	case "":
 		// No command - run TUI
-		runTUI(cfg, useBubbletea)
+		runTUI(ctx, cfg, useBubbletea)
 
 	case "backup":
-		runBackup(cfg, dryRun, verbose)
+		runBackup(ctx, cfg, args[1:], dryRun, verbose)
 
 	case "sync":
-		runSync(cfg, dryRun, verbose)
+		runSync(ctx, cfg, dryRun, verbose)
 
 	case "restore":
 		restorePath := ""
 		if len(args) > 1 {
 			restorePath = args[1]
 		}
-		runRestore(cfg, restorePath, dryRun, verbose)
+		runRestore(ctx, cfg, restorePath, dryRun, readData)
 
 	case "status":
 		showStatus(cfg)
 
 	case "validate":
-		validateConfig(cfg)
+		validateConfig(cfg, args[1:], strict)
 
-	case "list-backups":
-		listBackups(cfg)
+	case "snapshots", "list-backups":
+		runSnapshots(ctx, cfg, args[1:])
+
+	case "diff":
+		diffSnapshots(ctx, cfg, args[1:])
+
+	case "mount":
+		snapshotID := ""
+		if len(args) > 1 {
+			snapshotID = args[1]
+		}
+		mountRepository(ctx, cfg, snapshotID)
 
 	case "health":
-		runHealthCheck(cfg)
+		runHealthCheck(ctx, cfg)
+
+	case "check":
+		runCheck(ctx, cfg, args[1:], dryRun, readData)
+
+	case "drill":
+		runDrill(ctx, cfg, args[1:], dryRun)
+
+	case "stats":
+		runStats(ctx, cfg, args[1:])
+
+	case "repo":
+		runRepo(ctx, cfg, args[1:], dryRun)
 
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", command)
@@ -159,12 +223,38 @@ USAGE:
 COMMANDS:
     (no command)      Launch interactive TUI mode
     backup            Run local backup (Stage 1)
+      --only A,B      Back up only these stacks (may be disabled or external paths)
+      --exclude C     Leave these stacks out of this run
     sync              Sync to cloud storage (Stage 2)
     restore [PATH]    Restore from cloud (Stage 3)
     status            Show system status
-    validate          Validate configuration
-    list-backups      List backup snapshots
+    validate          Validate configuration, listing unknown keys and bad values
+      --strict        Fail on any of these problems
+    snapshots         List snapshots with their stack, oldest first (alias: list-backups)
+      --stack NAME    Only this stack (dirlist name, external path or tag)
+      --host NAME     Only snapshots taken on this host
+      --since TIME    Taken at or after TIME (YYYY-MM-DD, "YYYY-MM-DD HH:MM" or an age like 7d)
+      --until TIME    Taken before TIME (a bare date includes that day)
+      --latest N      Only the newest N snapshots of each stack
+      --format F      table, json or csv (default: table)
+    diff ID1 ID2      Compare two snapshots (or: diff STACK for its latest two)
+    mount [SNAPSHOT]  Mount the repository via FUSE until Ctrl+C
     health            Run health diagnostics
+    check             Check the repository, reading the next 1/CHECK_SUBSETS of the data
+      --read-data-subset N/T  Read this slice instead of the next one
+      --read-data     Read all data
+    stats             Measure repository and per-stack usage and show its growth
+      --cached        Show the last measurement instead of running restic
+    drill STACK...    Restore the latest snapshot into a sandbox and validate its compose file
+      --up            Also start it, wait until healthy and tear it down
+      --wait SECONDS  How long --up waits for the services (default: DOCKER_TIMEOUT)
+      --keep          Keep the restored files for inspection
+    repo init         Create the configured repository with the configured password
+    repo key list     List the keys that can open the repository
+    repo key add      Add a key for another password (--password-file, --user, --host)
+    repo key remove ID  Remove a key other than the one in use
+    repo key passwd   Change the password and store it where the config reads it
+    init              Set up config.ini, the dirlist and optionally a new repository
     generate-config   Generate config template
     help              Show this help
 
@@ -172,34 +262,51 @@ FLAGS:
     -v, --verbose     Enable verbose output
     -n, --dry-run     Perform dry run (no changes)
     -c, --config      Path to config file
+    --read-data       Read all data when verifying a restore (slow)
+    --strict          Refuse to run when the configuration has problems
+    --profile NAME    Apply the [profile:NAME] section of the config
     -h, --help        Show help message
     --version         Show version
 
 EXAMPLES:
     %s                          # Launch TUI
     %s backup                   # Run backup
+    %s backup --only nginx,db   # Back up two stacks right now
     %s backup --dry-run         # Preview backup
     %s sync                     # Sync to cloud
     %s restore /tmp/restore     # Restore to path
+    %s --read-data restore      # Restore and fully verify
+    %s diff nginx               # Changes between last two nginx backups
+    %s snapshots --latest 1     # Newest snapshot of every stack
     %s status                   # Show status
+    %s check                    # Read the next slice of the repository data
+    %s drill --up nginx         # Bring the last nginx backup up in a sandbox
     %s validate                 # Check config
 
 CONFIGURATION:
     Default config location: config/config.ini
     Override with -c flag or BACKUP_CONFIG environment variable
+    Without a config the TUI starts the setup wizard, as does '%s init'
 
-`, Name, Version, Name, Name, Name, Name, Name, Name, Name, Name)
+`, Name, Version, Name, Name, Name, Name, Name, Name, Name, Name, Name, Name, Name, Name, Name, Name, Name)
 }
 
-func runTUI(cfg *config.Config, _ bool) {
+func runTUI(ctx context.Context, cfg *config.Config, _ bool) {
 	// Use Bubbletea-based TUI
-	if err := tui.Run(cfg); err != nil {
+	if err := tui.Run(ctx, cfg); err != nil {
 		fmt.Fprintf(os.Stderr, "TUI error: %v\n", err)
 		os.Exit(ExitConfigError)
 	}
 }
 
-func runBackup(cfg *config.Config, dryRun, verbose bool) {
+func runBackup(ctx context.Context, cfg *config.Config, args []string, dryRun, verbose bool) {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	only := fs.String("only", "", "Comma-separated stacks to back up instead of all enabled ones")
+	exclude := fs.String("exclude", "", "Comma-separated stacks to leave out of this run")
+	fs.BoolVar(&dryRun, "n", dryRun, "Perform dry run")
+	fs.BoolVar(&dryRun, "dry-run", dryRun, "Perform dry run")
+	_ = fs.Parse(args) // ExitOnError: exits on invalid flags
+
 	// Validate config
 	if err := cfg.Validate(); err != nil {
 		util.PrintError("Configuration error: %v", err)
@@ -207,13 +314,14 @@ func runBackup(cfg *config.Config, dryRun, verbose bool) {
 	}
 
 	svc := backup.NewService(cfg, dryRun, verbose)
-	if err := svc.Run(); err != nil {
+	svc.SetSelection(splitList(*only), splitList(*exclude))
+	if err := svc.Run(ctx); err != nil {
 		util.PrintError("Backup failed: %v", err)
-		os.Exit(ExitBackupError)
+		os.Exit(failureExit(err, ExitBackupError))
 	}
 }
 
-func runSync(cfg *config.Config, dryRun, _ bool) {
+func runSync(ctx context.Context, cfg *config.Config, dryRun, _ bool) {
 	// Validate config for cloud sync
 	if err := cfg.ValidateForCloudSync(); err != nil {
 		util.PrintError("Configuration error: %v", err)
@@ -227,29 +335,30 @@ func runSync(cfg *config.Config, dryRun, _ bool) {
 	}
 
 	// Validate remote
-	if err := cloud.ValidateRemote(cfg.CloudSync.Remote); err != nil {
+	if err := cloud.ValidateRemote(ctx, cfg.CloudSync.Remote); err != nil {
 		util.PrintError("Remote validation failed: %v", err)
-		os.Exit(ExitSyncError)
+		os.Exit(failureExit(err, ExitSyncError))
 	}
 
 	svc := cloud.NewSyncService(&cfg.CloudSync, cfg.LocalBackup.Repository, dryRun)
 
 	// Test connectivity
-	if err := svc.TestConnectivity(); err != nil {
+	if err := svc.TestConnectivity(ctx); err != nil {
 		util.PrintError("Connectivity test failed: %v", err)
-		os.Exit(ExitSyncError)
+		os.Exit(failureExit(err, ExitSyncError))
 	}
 
 	// Run sync
-	if err := svc.Sync(); err != nil {
+	svc.SetStatusDir(cfg.LogDir)
+	if err := svc.Sync(ctx); err != nil {
 		util.PrintError("Sync failed: %v", err)
-		os.Exit(ExitSyncError)
+		os.Exit(failureExit(err, ExitSyncError))
 	}
 
 	util.PrintSuccess("Sync completed successfully")
 }
 
-func runRestore(cfg *config.Config, restorePath string, dryRun, _ bool) {
+func runRestore(ctx context.Context, cfg *config.Config, restorePath string, dryRun, readData bool) {
 	// Validate config for cloud sync
 	if err := cfg.ValidateForCloudSync(); err != nil {
 		util.PrintError("Configuration error: %v", err)
@@ -268,22 +377,24 @@ func runRestore(cfg *config.Config, restorePath string, dryRun, _ bool) {
 	}
 
 	svc := cloud.NewRestoreService(&cfg.CloudSync, dryRun, false)
+	svc.SetRepositoryCheck(&cfg.LocalBackup, readData)
 
 	// Test connectivity
-	if err := svc.TestConnectivity(); err != nil {
+	if err := svc.TestConnectivity(ctx); err != nil {
 		util.PrintError("Connectivity test failed: %v", err)
-		os.Exit(ExitRestoreError)
+		os.Exit(failureExit(err, ExitRestoreError))
 	}
 
 	// Run restore
-	if err := svc.Restore(restorePath); err != nil {
+	if err := svc.Restore(ctx, restorePath); err != nil {
 		util.PrintError("Restore failed: %v", err)
-		os.Exit(ExitRestoreError)
+		os.Exit(failureExit(err, ExitRestoreError))
 	}
 
-	// Verify
-	if err := svc.Verify(restorePath); err != nil {
-		util.PrintWarning("Verification found issues: %v", err)
+	// Verify the restored repository can be opened and passes restic check
+	if err := svc.Verify(ctx, restorePath); err != nil {
+		util.PrintError("Restore verification failed: %v", err)
+		os.Exit(failureExit(err, ExitRestoreError))
 	}
 
 	svc.PrintNextSteps(restorePath)
@@ -299,11 +410,34 @@ func showStatus(cfg *config.Config) {
 	// Configuration
 	fmt.Println("Configuration:")
 	fmt.Printf("  Config file: %s\n", cfg.ConfigFile)
+	if cfg.Profile != "" {
+		fmt.Printf("  Profile: %s\n", cfg.Profile)
+	}
 	fmt.Printf("  Stacks directory: %s\n", cfg.Docker.StacksDir)
 	fmt.Printf("  Restic repository: %s\n", cfg.LocalBackup.Repository)
 	fmt.Printf("  Cloud remote: %s\n", cfg.CloudSync.Remote)
 	fmt.Println()
 
+	// Effective value of every setting and where it was set
+	fmt.Println("Settings:")
+	section := ""
+	for _, f := range config.Fields() {
+		if f.Section != section {
+			section = f.Section
+			fmt.Printf("  [%s]\n", section)
+		}
+		value := f.Value(cfg)
+		if f.Secret && value != "" {
+			value = "********"
+		}
+		origin := cfg.Origin(f)
+		if ref := cfg.Reference(f); ref != "" && !strings.HasPrefix(ref, "$") {
+			origin += " " + ref
+		}
+		fmt.Printf("    %-22s %-30s %s%s%s\n", f.Key, value, util.ColorGray, origin, util.ColorReset)
+	}
+	fmt.Println()
+
 	// Tools
 	fmt.Println("Tools:")
 	fmt.Printf("  Docker Compose: %s\n", boolStatus(backup.DockerComposeAvailable()))
@@ -312,93 +446,414 @@ func showStatus(cfg *config.Config) {
 	fmt.Println()
 }
 
-func validateConfig(cfg *config.Config) {
+func validateConfig(cfg *config.Config, args []string, strict bool) {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	fs.BoolVar(&strict, "strict", strict, "Fail on any configuration problem")
+	_ = fs.Parse(args) // ExitOnError: exits on invalid flags
+
 	fmt.Println("Validating configuration...")
 
+	issues := cfg.Lint()
+	for _, issue := range issues {
+		util.PrintWarning("%s", issue)
+	}
+
 	if err := cfg.Validate(); err != nil {
 		util.PrintError("Validation failed: %v", err)
 		os.Exit(ExitConfigError)
 	}
+	if len(issues) > 0 {
+		if strict {
+			util.PrintError("Validation failed: %d problem(s) in strict mode", len(issues))
+			os.Exit(ExitConfigError)
+		}
+		util.PrintWarning("Configuration is usable, but has %d problem(s)", len(issues))
+		return
+	}
 
 	util.PrintSuccess("Configuration is valid")
 }
 
-func listBackups(cfg *config.Config) {
+func runSnapshots(ctx context.Context, cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("snapshots", flag.ExitOnError)
+	stack := fs.String("stack", "", "Only snapshots of this stack")
+	host := fs.String("host", "", "Only snapshots taken on this host")
+	since := fs.String("since", "", "Only snapshots taken at or after this time")
+	until := fs.String("until", "", "Only snapshots taken before this time")
+	latest := fs.Int("latest", 0, "Only the newest N snapshots of each stack")
+	format := fs.String("format", backup.FormatTable, "Output format: table, json or csv")
+	_ = fs.Parse(args) // ExitOnError: exits on invalid flags
+
+	switch *format {
+	case backup.FormatTable, backup.FormatJSON, backup.FormatCSV:
+	default:
+		util.PrintError("Unknown format %q, expected table, json or csv", *format)
+		os.Exit(ExitConfigError)
+	}
+	if *latest < 0 {
+		util.PrintError("--latest must not be negative")
+		os.Exit(ExitConfigError)
+	}
+
+	filter := backup.SnapshotFilter{Stack: *stack, Host: *host, Latest: *latest}
+	now := time.Now()
+	var err error
+	if *since != "" {
+		if filter.Since, err = backup.ParseSnapshotTime(*since, now, false); err != nil {
+			util.PrintError("--since: %v", err)
+			os.Exit(ExitConfigError)
+		}
+	}
+	if *until != "" {
+		if filter.Until, err = backup.ParseSnapshotTime(*until, now, true); err != nil {
+			util.PrintError("--until: %v", err)
+			os.Exit(ExitConfigError)
+		}
+	}
+
 	svc := backup.NewService(cfg, true, false)
-	if err := svc.ListBackups(); err != nil {
-		util.PrintError("Cannot list backups: %v", err)
+	snapshots, err := svc.Snapshots(ctx, filter)
+	if err != nil {
+		util.PrintError("Cannot list snapshots: %v", err)
+		os.Exit(failureExit(err, ExitBackupError))
+	}
+	if err := backup.WriteSnapshots(os.Stdout, snapshots, *format); err != nil {
+		util.PrintError("Cannot write snapshots: %v", err)
 		os.Exit(ExitBackupError)
 	}
 }
 
-func runHealthCheck(cfg *config.Config) {
+func diffSnapshots(ctx context.Context, cfg *config.Config, args []string) {
+	if len(args) == 0 || len(args) > 2 {
+		util.PrintError("Usage: %s diff SNAPSHOT1 SNAPSHOT2 | %s diff STACK", Name, Name)
+		os.Exit(ExitConfigError)
+	}
+
 	svc := backup.NewService(cfg, true, false)
-	_ = svc.HealthCheck() // Error intentionally ignored - health check prints its own output
+	if err := svc.Diff(ctx, args); err != nil {
+		util.PrintError("Cannot diff snapshots: %v", err)
+		os.Exit(ExitBackupError)
+	}
 }
 
-func generateConfigTemplate() {
-	template := `# Backup TUI - Unified Configuration
-# Docker Stack 3-Stage Backup System
-
-#===========================================
-# [docker] - Docker Stacks Configuration
-#===========================================
-[docker]
-# Directory containing Docker compose stacks to backup
-DOCKER_STACKS_DIR=/opt/docker-stacks
-
-# Timeout for docker compose commands (seconds)
-DOCKER_TIMEOUT=300
-
-#===========================================
-# [local_backup] - Local Restic Repository
-#===========================================
-[local_backup]
-# Local restic repository path
-RESTIC_REPOSITORY=/mnt/backup/restic-repo
-
-# Password (choose one method)
-RESTIC_PASSWORD=your-secure-password
-# PASSWORD_FILE=/path/to/password-file
-# PASSWORD_COMMAND="pass show backup"
-
-# Backup timeout (seconds)
-BACKUP_TIMEOUT=3600
-
-# Custom hostname for snapshots (optional)
-# HOSTNAME=my-server
-
-# Retention policy
-KEEP_DAILY=7
-KEEP_WEEKLY=4
-KEEP_MONTHLY=6
-KEEP_YEARLY=2
-AUTO_PRUNE=true
-
-# Verification (metadata|files|data)
-ENABLE_VERIFICATION=true
-VERIFICATION_DEPTH=metadata
-
-#===========================================
-# [cloud_sync] - Remote Cloud Storage
-#===========================================
-[cloud_sync]
-# Rclone remote name (from rclone config)
-RCLONE_REMOTE=backblaze
-
-# Remote path for backup storage
-RCLONE_PATH=/backup/restic
-
-# Concurrent transfers
-TRANSFERS=4
-
-# Retry attempts with exponential backoff
-RETRIES=3
-
-# Bandwidth limit (optional, e.g., "10M", "1G")
-# BANDWIDTH=10M
-`
+func mountRepository(ctx context.Context, cfg *config.Config, snapshotID string) {
+	svc := backup.NewService(cfg, false, false)
+	if err := svc.Mount(ctx, snapshotID); err != nil {
+		util.PrintError("Mount failed: %v", err)
+		os.Exit(ExitBackupError)
+	}
+}
+
+func runRepo(ctx context.Context, cfg *config.Config, args []string, dryRun bool) {
+	usage := fmt.Sprintf("Usage: %s repo init | %s repo key list|add|remove ID|passwd", Name, Name)
+	if len(args) == 0 || (args[0] == "key" && len(args) < 2) {
+		util.PrintError("%s", usage)
+		os.Exit(ExitConfigError)
+	}
+	if cfg.LocalBackup.Repository == "" {
+		util.PrintError("RESTIC_REPOSITORY not configured")
+		os.Exit(ExitConfigError)
+	}
+
+	restic := backup.NewResticManager(&cfg.LocalBackup, dryRun, nil)
+	var err error
+	switch {
+	case args[0] == "init":
+		if err = restic.Init(ctx); err == nil {
+			util.PrintSuccess("Repository initialized: %s", cfg.LocalBackup.Repository)
+		}
+	case args[0] == "key" && args[1] == "list":
+		err = listKeys(ctx, restic)
+	case args[0] == "key" && args[1] == "add":
+		err = addKey(ctx, cfg, args[2:], dryRun)
+	case args[0] == "key" && args[1] == "remove":
+		if len(args) != 3 {
+			util.PrintError("Usage: %s repo key remove ID", Name)
+			os.Exit(ExitConfigError)
+		}
+		if err = restic.RemoveKey(ctx, args[2]); err == nil {
+			util.PrintSuccess("Key %s removed", args[2])
+		}
+	case args[0] == "key" && args[1] == "passwd":
+		err = changePassword(ctx, cfg, args[2:], dryRun)
+	default:
+		util.PrintError("%s", usage)
+		os.Exit(ExitConfigError)
+	}
+	restic.Cleanup()
+
+	if err != nil {
+		util.PrintError("%v", err)
+		os.Exit(failureExit(err, ExitBackupError))
+	}
+}
+
+func listKeys(ctx context.Context, restic *backup.ResticManager) error {
+	keys, err := restic.ListKeys(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%-2s%-10s %-30s %s\n", "", "ID", "USER@HOST", "CREATED")
+	for _, k := range keys {
+		current := " "
+		if k.Current {
+			current = "*"
+		}
+		fmt.Printf("%-2s%-10s %-30s %s\n", current, k.ShortID(), k.UserName+"@"+k.HostName, k.Created)
+	}
+	fmt.Printf("\n%d key(s), * marks the key of the configured password\n", len(keys))
+	return nil
+}
+
+func addKey(ctx context.Context, cfg *config.Config, args []string, dryRun bool) error {
+	fs := flag.NewFlagSet("repo key add", flag.ExitOnError)
+	passwordFile := fs.String("password-file", "", "Read the password of the new key from this file")
+	user := fs.String("user", "", "User name recorded with the key")
+	host := fs.String("host", "", "Host name recorded with the key")
+	_ = fs.Parse(args) // ExitOnError: exits on invalid flags
 
+	password, err := readNewPassword(*passwordFile)
+	if err != nil {
+		return err
+	}
+	if err := backup.NewService(cfg, dryRun, false).AddKey(ctx, password, *user, *host); err != nil {
+		return err
+	}
+	util.PrintSuccess("Key added")
+	return nil
+}
+
+func changePassword(ctx context.Context, cfg *config.Config, args []string, dryRun bool) error {
+	fs := flag.NewFlagSet("repo key passwd", flag.ExitOnError)
+	passwordFile := fs.String("password-file", "", "Read the new password from this file")
+	_ = fs.Parse(args) // ExitOnError: exits on invalid flags
+
+	password, err := readNewPassword(*passwordFile)
+	if err != nil {
+		return err
+	}
+	msg, err := backup.NewService(cfg, dryRun, false).ChangePassword(ctx, password)
+	if err != nil {
+		return err
+	}
+	util.PrintSuccess("%s", msg)
+	return nil
+}
+
+// readNewPassword reads a new password from file, or asks for it twice on
+// the terminal without echo; a line on stdin is used when it is not a terminal
+func readNewPassword(file string) (string, error) {
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("cannot read password file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("cannot read the new password: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+
+	var entered [2]string
+	for i, prompt := range []string{"New password: ", "Repeat new password: "} {
+		fmt.Print(prompt)
+		password, err := term.ReadPassword(os.Stdin.Fd())
+		fmt.Println()
+		if err != nil {
+			return "", fmt.Errorf("cannot read the new password: %w", err)
+		}
+		entered[i] = string(password)
+	}
+	if entered[0] != entered[1] {
+		return "", fmt.Errorf("the passwords do not match")
+	}
+	return entered[0], nil
+}
+
+func runHealthCheck(ctx context.Context, cfg *config.Config) {
+	svc := backup.NewService(cfg, true, false)
+	_ = svc.HealthCheck(ctx) // Error intentionally ignored - health check prints its own output
+}
+
+func runCheck(ctx context.Context, cfg *config.Config, args []string, dryRun, readData bool) {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	slice := fs.String("read-data-subset", "", "Read slice N/T of the pack data instead of the next one of the rotation")
+	fs.BoolVar(&readData, "read-data", readData, "Read all pack data")
+	_ = fs.Parse(args) // ExitOnError: exits on invalid flags
+
+	subset, total := 0, cfg.LocalBackup.CheckSubsets
+	switch {
+	case readData:
+		subset, total = 1, 1
+	case *slice != "":
+		var err error
+		if subset, total, err = parseSubset(*slice); err != nil {
+			util.PrintError("%v", err)
+			os.Exit(ExitConfigError)
+		}
+	}
+
+	svc := backup.NewService(cfg, dryRun, false)
+	if err := svc.CheckData(ctx, subset, total); err != nil {
+		util.PrintError("Repository check failed: %v", err)
+		os.Exit(failureExit(err, ExitBackupError))
+	}
+}
+
+func runDrill(ctx context.Context, cfg *config.Config, args []string, dryRun bool) {
+	fs := flag.NewFlagSet("drill", flag.ExitOnError)
+	up := fs.Bool("up", false, "Start the restored stack, wait until it is healthy and tear it down")
+	wait := fs.Int("wait", cfg.Docker.Timeout, "Seconds --up waits for the services")
+	keep := fs.Bool("keep", false, "Keep the restored files for inspection")
+	_ = fs.Parse(args) // ExitOnError: exits on invalid flags
+
+	if fs.NArg() == 0 {
+		util.PrintError("Usage: %s drill [--up] [--wait SECONDS] [--keep] STACK...", Name)
+		os.Exit(ExitConfigError)
+	}
+
+	svc := backup.NewService(cfg, dryRun, false)
+	opts := backup.DrillOptions{Up: *up, Wait: time.Duration(*wait) * time.Second, Keep: *keep}
+	failed := 0
+	for _, stack := range fs.Args() {
+		if _, err := svc.Drill(ctx, stack, opts); err != nil {
+			util.PrintError("Drill of %s failed: %v", stack, err)
+			if ctx.Err() != nil {
+				os.Exit(failureExit(err, ExitRestoreError))
+			}
+			failed++
+		}
+	}
+	if failed > 0 {
+		os.Exit(ExitRestoreError)
+	}
+}
+
+func runStats(ctx context.Context, cfg *config.Config, args []string) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	cached := fs.Bool("cached", false, "Show the last recorded measurement instead of running restic")
+	_ = fs.Parse(args) // ExitOnError: exits on invalid flags
+
+	if !*cached {
+		svc := backup.NewService(cfg, false, false)
+		_, err := svc.CollectStats(ctx, func(tag string, done, total int) {
+			util.LogProgress("Measuring %s (%d/%d)", tag, done+1, total)
+		})
+		if err != nil {
+			util.PrintError("Cannot collect statistics: %v", err)
+			os.Exit(failureExit(err, ExitBackupError))
+		}
+	}
+
+	samples, err := stats.Load(cfg.LogDir)
+	if err != nil {
+		util.PrintError("%v", err)
+		os.Exit(ExitConfigError)
+	}
+	if len(samples) == 0 {
+		util.PrintError("No statistics recorded yet, run '%s stats' without --cached", Name)
+		os.Exit(ExitConfigError)
+	}
+	printStats(samples)
+}
+
+// printStats prints the newest sample with the growth over the last week and month
+func printStats(samples []stats.Sample) {
+	const day = 24 * time.Hour
+	last := samples[len(samples)-1]
+	repo := last.Repository
+
+	fmt.Println()
+	fmt.Printf("%sRepository Statistics%s (%s)\n", util.ColorGreen, util.ColorReset, last.Time.Format("2006-01-02 15:04"))
+	fmt.Println("=====================")
+	fmt.Printf("Snapshots:     %d\n", repo.Snapshots)
+	fmt.Printf("Restore size:  %s in %d files (latest snapshot of each stack)\n", util.FormatSize(repo.RestoreSize), repo.FileCount)
+	fmt.Printf("Stored:        %s after deduplication and compression\n", util.FormatSize(repo.RawData))
+	if repo.CompressionRatio > 0 {
+		fmt.Printf("Uncompressed:  %s (compression ratio %.2fx)\n", util.FormatSize(repo.Uncompressed), repo.CompressionRatio)
+	}
+	fmt.Printf("Growth:        %s over 7 days, %s over 30 days\n",
+		stats.FormatGrowth(stats.GrowthPerDay(samples, 7*day, stats.RepositoryRawData)),
+		stats.FormatGrowth(stats.GrowthPerDay(samples, 30*day, stats.RepositoryRawData)))
+	fmt.Println()
+
+	tags := stats.Largest(last)
+	width := len("STACK")
+	for _, tag := range tags {
+		width = max(width, len(tag))
+	}
+	fmt.Printf("%-*s %12s %12s %10s %18s\n", width, "STACK", "SIZE", "STORED", "SNAPSHOTS", "GROWTH (30 DAYS)")
+	for _, tag := range tags {
+		u := last.Stacks[tag]
+		growth := stats.FormatGrowth(stats.GrowthPerDay(samples, 30*day, stats.StackRawData(tag)))
+		fmt.Printf("%-*s %12s %12s %10d %18s\n", width, tag, util.FormatSize(u.RestoreSize), util.FormatSize(u.RawData), u.Snapshots, growth)
+	}
+	fmt.Println()
+	fmt.Printf("%sStored data of stacks overlaps where they share files, so it adds up to more than the repository.%s\n", util.ColorGray, util.ColorReset)
+	fmt.Println()
+}
+
+// parseSubset parses a --read-data-subset value of the form N/T
+func parseSubset(value string) (int, int, error) {
+	n, t, ok := strings.Cut(value, "/")
+	subset, err1 := strconv.Atoi(n)
+	total, err2 := strconv.Atoi(t)
+	if !ok || err1 != nil || err2 != nil || total < 1 || subset < 1 || subset > total {
+		return 0, 0, fmt.Errorf("invalid --read-data-subset %q, expected N/T with 1 <= N <= T", value)
+	}
+	return subset, total, nil
+}
+
+// setupPath returns where init writes the config: the --config path or config/config.ini in the current directory
+func setupPath(configPath string) string {
+	if configPath != "" {
+		return configPath
+	}
+	cwd, _ := os.Getwd()
+	return filepath.Join(cwd, "config", "config.ini")
+}
+
+func runInit(ctx context.Context, configPath string) {
+	if _, err := os.Stat(configPath); err == nil {
+		util.PrintError("%s already exists, edit it or remove it first", configPath)
+		os.Exit(ExitConfigError)
+	}
+
+	fmt.Printf("Setting up %s (press Enter to accept a default)\n", configPath)
+	answers, err := setup.Prompt(setup.Questions(ctx), os.Stdin, os.Stdout)
+	if err != nil {
+		util.PrintError("%v", err)
+		os.Exit(ExitCancelled)
+	}
+
+	fmt.Println()
+	if err := setup.Run(ctx, configPath, answers, os.Stdout); err != nil {
+		util.PrintError("Setup failed: %v", err)
+		os.Exit(failureExit(err, ExitConfigError))
+	}
+	util.PrintSuccess("Setup complete, run '%s validate' to check the configuration", Name)
+}
+
+func runFirstRun(ctx context.Context, configPath string) {
+	start, err := tui.RunSetup(ctx, configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "TUI error: %v\n", err)
+		os.Exit(ExitConfigError)
+	}
+	if !start {
+		if _, err := os.Stat(configPath); err != nil {
+			os.Exit(ExitCancelled)
+		}
+		os.Exit(ExitSuccess)
+	}
+}
+
+func generateConfigTemplate() {
 	// Determine output path
 	cwd, _ := os.Getwd()
 	outputPath := filepath.Join(cwd, "config", "config.ini.template")
@@ -409,7 +864,7 @@ RETRIES=3
 		os.Exit(ExitConfigError)
 	}
 
-	if err := os.WriteFile(outputPath, []byte(template), 0o600); err != nil {
+	if err := os.WriteFile(outputPath, []byte(config.Template), 0o600); err != nil {
 		fmt.Fprintf(os.Stderr, "Error creating template: %v\n", err)
 		os.Exit(ExitConfigError)
 	}
@@ -418,6 +873,43 @@ RETRIES=3
 	fmt.Println("Copy to config/config.ini and customize for your environment")
 }
 
+// splitList splits a comma separated flag value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// signalContext returns a context cancelled by the first SIGINT, SIGTERM or
+// SIGHUP. A second signal exits at once, for when restarting the stacks or
+// waiting for compose hangs after the first.
+func signalContext() (context.Context, context.CancelFunc) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		<-sigs
+		cancel()
+		<-sigs
+		fmt.Fprintln(os.Stderr, "\nInterrupted again, exiting without waiting for the cleanup")
+		os.Exit(ExitCancelled)
+	}()
+	return ctx, cancel
+}
+
+// failureExit returns ExitCancelled for operations interrupted by a signal and code otherwise
+func failureExit(err error, code int) int {
+	if errors.Is(err, context.Canceled) {
+		return ExitCancelled
+	}
+	return code
+}
+
 func boolStatus(ok bool) string {
 	if ok {
 		return util.ColorGreen + "OK" + util.ColorReset
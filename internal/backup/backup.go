@@ -1,16 +1,18 @@
 package backup
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
-	"os/signal"
 	"path/filepath"
-	"syscall"
+	"strings"
 	"time"
 
 	"backup-tui/internal/config"
 	"backup-tui/internal/dirlist"
+	"backup-tui/internal/status"
 	"backup-tui/internal/util"
 )
 
@@ -30,6 +32,13 @@ type Service struct {
 	backupInProgress bool
 	startTime        time.Time
 
+	only    []string // Ad hoc selection replacing the enabled entries (empty = all enabled)
+	exclude []string // Entries left out of the run
+
+	events       EventFunc // Optional observer for run progress
+	currentIndex int
+	totalDirs    int
+
 	stats BackupStats
 }
 
@@ -82,8 +91,40 @@ func NewServiceWithOutput(cfg *config.Config, dryRun, verbose bool, outputWriter
 	}
 }
 
-// Run executes the full backup workflow
-func (s *Service) Run() error {
+// SetSelection limits the run to the entries in only instead of every enabled entry,
+// then drops those in exclude. Names are dirlist identifiers or external snapshot tags;
+// only may also list disabled entries and absolute paths of external directories
+// that are not in the dirlist. The dirlist file is not modified.
+func (s *Service) SetSelection(only, exclude []string) {
+	s.only = only
+	s.exclude = exclude
+}
+
+// SetEventFunc registers fn to receive typed events while Run progresses.
+// Restic backup progress is reported as EventBackupProgress.
+func (s *Service) SetEventFunc(fn EventFunc) {
+	s.events = fn
+	s.restic.SetProgressFunc(func(p BackupProgress) {
+		s.emit(Event{Kind: EventBackupProgress, Stack: s.currentDir, Progress: &p})
+	})
+}
+
+// emit sends an event to the registered observer, filling in the run position
+func (s *Service) emit(ev Event) {
+	if s.events == nil {
+		return
+	}
+	if ev.Index == 0 {
+		ev.Index = s.currentIndex
+	}
+	ev.Total = s.totalDirs
+	s.events(ev)
+}
+
+// Run executes the full backup workflow.
+// Cancelling ctx interrupts the stack being processed, which is still restarted
+// before Run returns; stacks that have not started yet are reported as skipped.
+func (s *Service) Run(ctx context.Context) error {
 	s.startTime = time.Now()
 	s.stats = BackupStats{StartTime: s.startTime}
 
@@ -92,15 +133,6 @@ func (s *Service) Run() error {
 	util.LogInfo("Start time: %s", s.startTime.Format("2006-01-02 15:04:05"))
 	util.LogProgress("Dry run: %t", s.dryRun)
 
-	// Setup signal handling
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
-	go func() {
-		sig := <-sigChan
-		util.LogWarn("Received signal: %v", sig)
-		s.cleanup()
-		os.Exit(5)
-	}()
 	defer s.cleanup()
 
 	// Create PID file
@@ -115,7 +147,7 @@ func (s *Service) Run() error {
 
 	// Phase 1: Pre-flight checks
 	util.LogHeader("Phase 1: Pre-flight Checks")
-	if err := s.preflight(); err != nil {
+	if err := s.preflight(ctx); err != nil {
 		return err
 	}
 
@@ -125,14 +157,22 @@ func (s *Service) Run() error {
 		return err
 	}
 
+	dirs, err := s.selectDirs()
+	if err != nil {
+		return err
+	}
+
 	// Phase 3: Backup processing
 	util.LogHeader("Phase 3: Sequential Backup Processing")
-	s.processBackups()
+	s.processBackups(ctx, dirs)
 
 	// Summary
 	s.stats.EndTime = time.Now()
 	s.printSummary()
 
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("backup cancelled, %d directories skipped: %w", s.stats.Skipped, err)
+	}
 	if s.stats.Failed > 0 {
 		return fmt.Errorf("backup completed with %d failures", s.stats.Failed)
 	}
@@ -141,7 +181,7 @@ func (s *Service) Run() error {
 	return nil
 }
 
-func (s *Service) preflight() error {
+func (s *Service) preflight(ctx context.Context) error {
 	// Check Docker
 	if !DockerComposeAvailable() {
 		return fmt.Errorf("docker compose is not available")
@@ -149,7 +189,7 @@ func (s *Service) preflight() error {
 	util.LogInfo("Docker compose is available")
 
 	// Check restic
-	if err := s.restic.CheckRepository(); err != nil {
+	if err := s.restic.CheckRepository(ctx); err != nil {
 		return fmt.Errorf("restic check failed: %w", err)
 	}
 	util.LogInfo("Restic is available and configured")
@@ -189,22 +229,78 @@ func (s *Service) scanDirectories() error {
 	return nil
 }
 
-func (s *Service) processBackups() {
-	enabledDirs := s.dirlist.GetEnabled()
+// selectDirs returns the entries to back up: the enabled ones, or the ad hoc
+// selection, minus the excluded ones
+func (s *Service) selectDirs() ([]string, error) {
+	dirs := s.dirlist.GetEnabled()
+
+	if len(s.only) > 0 {
+		dirs = nil
+		seen := make(map[string]bool)
+		for _, name := range s.only {
+			id, err := s.dirlist.Resolve(name)
+			if err != nil && filepath.IsAbs(name) {
+				// External directory that has not been saved to the dirlist
+				id = filepath.Clean(name)
+				if addErr := s.dirlist.AddExternal(id); addErr != nil {
+					return nil, fmt.Errorf("cannot back up %s: %w", name, addErr)
+				}
+				err = nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("cannot back up %s: %w", name, err)
+			}
+			if !seen[id] {
+				seen[id] = true
+				dirs = append(dirs, id)
+			}
+		}
+		util.LogInfo("Backing up selected directories only: %s", strings.Join(dirs, ", "))
+	}
+
+	if len(s.exclude) > 0 {
+		excluded := make(map[string]bool)
+		for _, name := range s.exclude {
+			id, err := s.dirlist.Resolve(name)
+			if err != nil {
+				return nil, fmt.Errorf("cannot exclude %s: %w", name, err)
+			}
+			excluded[id] = true
+		}
+		var kept []string
+		for _, id := range dirs {
+			if excluded[id] {
+				util.LogInfo("Excluded from this run: %s", id)
+				continue
+			}
+			kept = append(kept, id)
+		}
+		dirs = kept
+	}
+
+	return dirs, nil
+}
 
-	if len(enabledDirs) == 0 {
+func (s *Service) processBackups(ctx context.Context, dirs []string) {
+	if len(dirs) == 0 {
+		if len(s.only) > 0 || len(s.exclude) > 0 {
+			util.LogWarn("No directories left to back up after applying the selection")
+			return
+		}
 		util.LogWarn("No directories enabled for backup")
 		util.LogInfo("Edit %s to enable directories", s.dirlist.FilePath())
 		return
 	}
 
-	util.LogProgress("Processing %d enabled directories", len(enabledDirs))
+	util.LogProgress("Processing %d directories", len(dirs))
+	s.totalDirs = len(dirs)
+	s.emit(Event{Kind: EventRunStarted, Stacks: dirs})
 
 	// Store initial states
 	util.LogProgress("Checking initial state of Docker stacks")
-	for _, dirID := range enabledDirs {
+	for _, dirID := range dirs {
 		dirPath := s.dirlist.GetFullPath(dirID)
-		if err := s.docker.StoreInitialState(dirID, dirPath); err != nil {
+		if err := s.docker.StoreInitialState(ctx, dirID, dirPath); err != nil {
 			util.LogWarn("Failed to get initial state for %s: %v", dirID, err)
 		}
 		state := s.docker.GetStoredState(dirID)
@@ -212,21 +308,44 @@ func (s *Service) processBackups() {
 	}
 
 	// Process each directory
-	for i, dirID := range enabledDirs {
-		util.LogProgress("Processing %d of %d: %s", i+1, len(enabledDirs), dirID)
+	for i, dirID := range dirs {
+		if ctx.Err() != nil {
+			util.LogWarn("Backup cancelled, skipping %d remaining directories", len(dirs)-i)
+			for j, skipped := range dirs[i:] {
+				s.stats.Skipped++
+				s.stats.SkippedDirs = append(s.stats.SkippedDirs, skipped)
+				s.emit(Event{Kind: EventStackSkipped, Stack: skipped, Index: i + j + 1})
+			}
+			break
+		}
+
+		util.LogProgress("Processing %d of %d: %s", i+1, len(dirs), dirID)
 		s.stats.Processed++
+		s.currentIndex = i + 1
+		s.emit(Event{Kind: EventStackStarted, Stack: dirID})
 
-		if err := s.processDirectory(dirID); err != nil {
+		start := time.Now()
+		err := s.processDirectory(ctx, dirID)
+		if err != nil {
 			util.LogError("Failed to process %s: %v", dirID, err)
 			s.stats.Failed++
 			s.stats.FailedDirs = append(s.stats.FailedDirs, dirID)
+			s.emit(Event{Kind: EventStackFailed, Stack: dirID, Err: err})
 		} else {
 			s.stats.Succeeded++
+			s.emit(Event{Kind: EventStackDone, Stack: dirID})
+		}
+
+		// An interrupted stack keeps the result of its last complete run
+		if !s.dryRun && !errors.Is(err, context.Canceled) {
+			if err := status.RecordStack(s.config.LogDir, dirID, status.NewResult(start, err)); err != nil {
+				util.LogWarn("Cannot record result for %s: %v", dirID, err)
+			}
 		}
 	}
 }
 
-func (s *Service) processDirectory(dirID string) error {
+func (s *Service) processDirectory(ctx context.Context, dirID string) error {
 	dirPath := s.dirlist.GetFullPath(dirID)
 	if dirPath == "" {
 		return fmt.Errorf("directory not found in dirlist: %s", dirID)
@@ -237,11 +356,7 @@ func (s *Service) processDirectory(dirID string) error {
 	isExternal := entry != nil && entry.IsExternal
 
 	// Determine the tag name for restic
-	// For external paths, use basename + "-external" suffix
-	tagName := dirID
-	if isExternal {
-		tagName = filepath.Base(dirPath) + "-external"
-	}
+	tagName := s.dirlist.SnapshotTag(dirID)
 
 	s.currentDir = dirID
 	s.backupInProgress = true
@@ -260,34 +375,50 @@ func (s *Service) processDirectory(dirID string) error {
 		return fmt.Errorf("directory not found: %s", dirPath)
 	}
 
+	// Restarting must complete even when the run is cancelled
+	restartCtx := context.WithoutCancel(ctx)
+
 	// Stop stack
-	if err := s.docker.SmartStop(dirID, dirPath); err != nil {
+	if err := s.docker.SmartStop(ctx, dirID, dirPath); err != nil {
+		if ctx.Err() != nil {
+			// Interrupted half way down, bring it back up
+			if restartErr := s.docker.SmartStart(restartCtx, dirID, dirPath); restartErr != nil {
+				util.LogError("Failed to restart stack after cancellation: %v", restartErr)
+			}
+		}
 		return err
 	}
+	s.emit(Event{Kind: EventStackStopped, Stack: dirID})
 
 	// Backup
-	if err := s.restic.Backup(dirPath, tagName, s.config.LocalBackup.Hostname); err != nil {
+	if err := s.restic.Backup(ctx, dirPath, tagName, s.config.LocalBackup.Hostname); err != nil {
 		// Try to restart even on failure
-		if restartErr := s.docker.SmartStart(dirID, dirPath); restartErr != nil {
+		if restartErr := s.docker.SmartStart(restartCtx, dirID, dirPath); restartErr != nil {
 			util.LogError("Failed to restart stack after backup failure: %v", restartErr)
 		}
 		return err
 	}
 
-	// Verify
-	if err := s.restic.Verify(tagName); err != nil {
-		util.LogWarn("Verification failed: %v", err)
-	}
+	if ctx.Err() != nil {
+		util.LogWarn("Backup cancelled, skipping verification and retention for %s", dirID)
+	} else {
+		// Verify
+		s.emit(Event{Kind: EventVerifying, Stack: dirID})
+		if err := s.restic.Verify(ctx, tagName); err != nil {
+			util.LogWarn("Verification failed: %v", err)
+		}
 
-	// Apply retention
-	if err := s.restic.ApplyRetention(tagName, s.config.LocalBackup.Hostname); err != nil {
-		util.LogWarn("Retention failed: %v", err)
+		// Apply retention
+		if err := s.restic.ApplyRetention(ctx, tagName, s.config.LocalBackup.Hostname); err != nil {
+			util.LogWarn("Retention failed: %v", err)
+		}
 	}
 
 	// Restart stack
-	if err := s.docker.SmartStart(dirID, dirPath); err != nil {
+	if err := s.docker.SmartStart(restartCtx, dirID, dirPath); err != nil {
 		return err
 	}
+	s.emit(Event{Kind: EventStackRestarted, Stack: dirID})
 
 	util.LogSuccess("Successfully processed: %s", dirID)
 	return nil
@@ -312,7 +443,7 @@ func (s *Service) cleanup() {
 			util.LogWarn("Attempting to restart interrupted stack: %s (was %s)", s.currentDir, storedState)
 			dirPath := s.dirlist.GetFullPath(s.currentDir)
 			if dirPath != "" {
-				if err := s.docker.ForceStart(s.currentDir, dirPath); err != nil {
+				if err := s.docker.ForceStart(context.Background(), s.currentDir, dirPath); err != nil {
 					util.LogError("Failed to restart stack during cleanup: %v", err)
 				}
 			}
@@ -330,6 +461,9 @@ func (s *Service) printSummary() {
 	util.LogProgress("Directories processed: %d", s.stats.Processed)
 	util.LogProgress("Succeeded: %d", s.stats.Succeeded)
 	util.LogProgress("Failed: %d", s.stats.Failed)
+	if s.stats.Skipped > 0 {
+		util.LogProgress("Skipped: %d", s.stats.Skipped)
+	}
 
 	if len(s.stats.FailedDirs) > 0 {
 		util.LogWarn("Failed directories:")
@@ -344,65 +478,119 @@ func (s *Service) GetStats() BackupStats {
 	return s.stats
 }
 
-// ListBackups lists recent backup snapshots
-func (s *Service) ListBackups() error {
-	if err := s.restic.CheckRepository(); err != nil {
+// RestorePreview shows what would be restored for a directory
+func (s *Service) RestorePreview(ctx context.Context, dirName string) error {
+	if err := s.restic.CheckRepository(ctx); err != nil {
 		return fmt.Errorf("cannot access repository: %w", err)
 	}
 
-	snapshots, err := s.restic.ListSnapshots("", 0)
+	content, err := s.restic.RestorePreview(ctx, dirName)
 	if err != nil {
-		return fmt.Errorf("cannot list snapshots: %w", err)
+		return err
 	}
 
 	fmt.Println()
-	fmt.Printf("%sRecent Backup Snapshots:%s\n", util.ColorGreen, util.ColorReset)
-	fmt.Println("==========================")
-
-	for _, snap := range snapshots {
-		// Find directory tag
-		var dirTag string
-		for _, t := range snap.Tags {
-			if t != "docker-backup" && t != "selective-backup" && len(t) < 20 {
-				dirTag = t
-				break
-			}
+	fmt.Printf("%sRestore Preview for: %s%s\n", util.ColorGreen, dirName, util.ColorReset)
+	fmt.Println("===================================")
+	fmt.Println(content)
+
+	return nil
+}
+
+// Diff compares two snapshots and prints the changed files.
+// With a single argument the two most recent snapshots of that stack are compared;
+// it may be a dirlist name, an external path or a snapshot tag.
+func (s *Service) Diff(ctx context.Context, args []string) error {
+	if err := s.restic.CheckRepository(ctx); err != nil {
+		return fmt.Errorf("cannot access repository: %w", err)
+	}
+	defer s.restic.Cleanup()
+
+	var from, to string
+	switch len(args) {
+	case 1:
+		var err error
+		if from, to, err = s.restic.LatestPair(ctx, s.stackTag(args[0])); err != nil {
+			return err
 		}
+	case 2:
+		from, to = args[0], args[1]
+	default:
+		return fmt.Errorf("expected two snapshot IDs or a stack name")
+	}
 
-		timeStr := snap.Time
-		if len(timeStr) > 19 {
-			timeStr = timeStr[:19]
+	diff, err := s.restic.DiffSnapshots(ctx, from, to)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Printf("%sSnapshot Diff: %s → %s%s\n", util.ColorGreen, diff.From, diff.To, util.ColorReset)
+	fmt.Println("===================================")
+
+	for _, c := range diff.Changes {
+		color := util.ColorYellow
+		switch c.Modifier {
+		case DiffAdded:
+			color = util.ColorGreen
+		case DiffRemoved:
+			color = util.ColorRed
+		}
+		delta := ""
+		if d := c.SizeDelta(); d != 0 {
+			delta = "  (" + FormatSizeDelta(d) + ")"
 		}
+		fmt.Printf("%s%-2s%s %s%s\n", color, c.Modifier, util.ColorReset, c.Path, delta)
+	}
 
-		fmt.Printf("%s%s%s - ID: %s - Time: %s\n",
-			util.ColorCyan, dirTag, util.ColorReset, snap.ShortID, timeStr)
+	if largest := diff.LargestChanges(10); len(largest) > 0 {
+		fmt.Println()
+		fmt.Printf("%sLargest size changes:%s\n", util.ColorCyan, util.ColorReset)
+		for _, c := range largest {
+			fmt.Printf("  %10s  %s\n", FormatSizeDelta(c.SizeDelta()), c.Path)
+		}
 	}
+
+	added, removed, modified := diff.Counts()
+	fmt.Println()
+	fmt.Printf("Added:    %d (%s)\n", added, util.FormatSize(diff.Added.Bytes))
+	fmt.Printf("Removed:  %d (%s)\n", removed, util.FormatSize(diff.Removed.Bytes))
+	fmt.Printf("Modified: %d\n", modified)
+	fmt.Printf("Net size: %s\n", FormatSizeDelta(diff.SizeDelta()))
 	fmt.Println()
 
 	return nil
 }
 
-// RestorePreview shows what would be restored for a directory
-func (s *Service) RestorePreview(dirName string) error {
-	if err := s.restic.CheckRepository(); err != nil {
+// Mount mounts the repository (or a single snapshot) and blocks until ctx is cancelled
+func (s *Service) Mount(ctx context.Context, snapshotID string) error {
+	if err := s.restic.CheckRepository(ctx); err != nil {
 		return fmt.Errorf("cannot access repository: %w", err)
 	}
+	defer s.restic.Cleanup()
 
-	content, err := s.restic.RestorePreview(dirName)
+	mount, err := s.restic.Mount(ctx, snapshotID)
 	if err != nil {
 		return err
 	}
 
 	fmt.Println()
-	fmt.Printf("%sRestore Preview for: %s%s\n", util.ColorGreen, dirName, util.ColorReset)
-	fmt.Println("===================================")
-	fmt.Println(content)
+	fmt.Printf("%sRepository mounted:%s %s\n", util.ColorGreen, util.ColorReset, mount.Path)
+	fmt.Println("Press Ctrl+C to unmount")
+	fmt.Println()
 
-	return nil
+	select {
+	case <-ctx.Done():
+		util.LogInfo("Unmounting")
+	case <-mount.Done():
+		return fmt.Errorf("restic mount exited unexpectedly")
+	}
+
+	return mount.Unmount()
 }
 
 // HealthCheck generates a health report
-func (s *Service) HealthCheck() error {
+func (s *Service) HealthCheck(ctx context.Context) error {
 	fmt.Println()
 	fmt.Printf("%sBackup System Health Check%s\n", util.ColorGreen, util.ColorReset)
 	fmt.Println("============================")
@@ -425,11 +613,13 @@ func (s *Service) HealthCheck() error {
 
 	// Check repository
 	fmt.Print("Repository: ")
-	if err := s.restic.CheckRepository(); err == nil {
+	if err := s.restic.CheckRepository(ctx); err == nil {
 		fmt.Printf("%sOK%s\n", util.ColorGreen, util.ColorReset)
 	} else {
 		fmt.Printf("%sERROR: %v%s\n", util.ColorRed, err, util.ColorReset)
 	}
+	s.printCheckStatus()
+	s.printDrillStatus()
 
 	// Check stacks directory
 	fmt.Print("Stacks Directory: ")
@@ -0,0 +1,76 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"backup-tui/internal/config"
+)
+
+func TestSelectDirs(t *testing.T) {
+	tmpDir := t.TempDir()
+	stacksDir := filepath.Join(tmpDir, "stacks")
+	externalDir := filepath.Join(tmpDir, "external-stack")
+	for _, dir := range []string{
+		filepath.Join(stacksDir, "db"),
+		filepath.Join(stacksDir, "nginx"),
+		filepath.Join(stacksDir, "wiki"),
+		externalDir,
+	} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatalf("Cannot create %s: %v", dir, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte("services: {}\n"), 0o600); err != nil {
+			t.Fatalf("Cannot write compose file: %v", err)
+		}
+	}
+
+	newService := func(only, exclude []string) *Service {
+		cfg := &config.Config{DirlistFile: filepath.Join(tmpDir, "dirlist"), LockDir: tmpDir}
+		cfg.Docker.StacksDir = stacksDir
+		svc := NewService(cfg, true, false)
+		if _, _, err := svc.dirlist.Sync(); err != nil {
+			t.Fatalf("Sync error: %v", err)
+		}
+		svc.dirlist.Set("db", true)
+		svc.dirlist.Set("nginx", true)
+		svc.SetSelection(only, exclude)
+		return svc
+	}
+
+	tests := []struct {
+		name    string
+		only    []string
+		exclude []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "EnabledByDefault", want: []string{"db", "nginx"}},
+		{name: "OnlyIncludesDisabled", only: []string{"wiki", "db", "wiki"}, want: []string{"wiki", "db"}},
+		{name: "Exclude", exclude: []string{"nginx"}, want: []string{"db"}},
+		{name: "OnlyAndExclude", only: []string{"db", "wiki"}, exclude: []string{"db"}, want: []string{"wiki"}},
+		{name: "UnsavedExternal", only: []string{externalDir + "/"}, want: []string{externalDir}},
+		{name: "UnknownOnly", only: []string{"nope"}, wantErr: true},
+		{name: "UnknownExclude", exclude: []string{"nope"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newService(tt.only, tt.exclude).selectDirs()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("selectDirs error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
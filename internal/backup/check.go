@@ -0,0 +1,91 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backup-tui/internal/status"
+	"backup-tui/internal/util"
+)
+
+// CheckData checks the repository and reads slice subset of total of the pack
+// data. With subset 0 the slice comes from the rotation recorded in the status
+// file, so scheduled runs read a different slice each time and all data once
+// every total runs. A slice given by hand is not part of the rotation. A total
+// of 1 reads all data.
+func (s *Service) CheckData(ctx context.Context, subset, total int) error {
+	if total < 1 {
+		return fmt.Errorf("the number of slices must be at least 1")
+	}
+	if subset < 0 || subset > total {
+		return fmt.Errorf("slice %d is outside 1..%d", subset, total)
+	}
+	manual := subset != 0
+	if !manual {
+		st, err := status.Load(s.config.LogDir)
+		if err != nil {
+			return err
+		}
+		subset = st.NextSubset(total)
+	}
+
+	if s.dryRun {
+		util.LogProgress("[DRY RUN] Would check the repository and read data slice %d/%d", subset, total)
+		return nil
+	}
+
+	start := time.Now()
+	err := s.restic.CheckRepository(ctx)
+	if err == nil {
+		err = s.restic.CheckSubset(ctx, subset, total)
+	}
+	s.restic.Cleanup()
+
+	result := status.CheckResult{Result: status.NewResult(start, err), Subset: subset, Total: total, Manual: manual}
+	if recErr := status.RecordCheck(s.config.LogDir, result); recErr != nil {
+		util.LogWarn("Cannot record check result: %v", recErr)
+	}
+	return err
+}
+
+// printCheckStatus prints the last data check and the slice the next one reads
+func (s *Service) printCheckStatus() {
+	fmt.Print("Data Check: ")
+	st, err := status.Load(s.config.LogDir)
+	if err != nil {
+		fmt.Printf("%sERROR: %v%s\n", util.ColorRed, err, util.ColorReset)
+		return
+	}
+	c := st.Check
+	if c == nil {
+		fmt.Printf("%sNEVER RUN%s (run 'check' on a schedule)\n", util.ColorYellow, util.ColorReset)
+		return
+	}
+
+	when := c.Time.Format("2006-01-02 15:04")
+	by := ""
+	if c.Manual {
+		by = " by hand"
+	}
+	if c.Success {
+		fmt.Printf("%sOK%s (slice %d/%d read%s %s", util.ColorGreen, util.ColorReset, c.Subset, c.Total, by, when)
+	} else {
+		fmt.Printf("%sFAILED%s (slice %d/%d%s on %s: %s", util.ColorRed, util.ColorReset, c.Subset, c.Total, by, when, c.Error)
+	}
+	total := s.config.LocalBackup.CheckSubsets
+	fmt.Printf(", next %d/%d)\n", st.NextSubset(total), total)
+
+	fmt.Print("Full Data Pass: ")
+	r := st.Rotation
+	switch {
+	case r != nil && r.PassCompleted != nil:
+		fmt.Printf("completed %s", r.PassCompleted.Format("2006-01-02 15:04"))
+	default:
+		fmt.Printf("%sNOT YET%s", util.ColorYellow, util.ColorReset)
+	}
+	if r != nil && r.Total == total && len(r.Passed) > 0 {
+		fmt.Printf(", %d/%d slices of the current one read", len(r.Passed), total)
+	}
+	fmt.Println()
+}
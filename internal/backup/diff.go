@@ -0,0 +1,224 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"backup-tui/internal/util"
+)
+
+// Diff change modifiers as reported by restic diff
+const (
+	DiffAdded    = "+"
+	DiffRemoved  = "-"
+	DiffModified = "M"
+)
+
+// DiffChange is a single changed path between two snapshots
+type DiffChange struct {
+	Path     string
+	Modifier string
+	OldSize  int64 // Size in the older snapshot (0 if absent or a directory)
+	NewSize  int64 // Size in the newer snapshot (0 if absent or a directory)
+}
+
+// SizeDelta returns the size change of the path
+func (c DiffChange) SizeDelta() int64 {
+	return c.NewSize - c.OldSize
+}
+
+// DiffStats summarizes added or removed content
+type DiffStats struct {
+	Files     int   `json:"files"`
+	Dirs      int   `json:"dirs"`
+	Others    int   `json:"others"`
+	DataBlobs int   `json:"data_blobs"`
+	TreeBlobs int   `json:"tree_blobs"`
+	Bytes     int64 `json:"bytes"`
+}
+
+// SnapshotDiff is the result of comparing two snapshots
+type SnapshotDiff struct {
+	From         string
+	To           string
+	Changes      []DiffChange
+	ChangedFiles int
+	Added        DiffStats
+	Removed      DiffStats
+}
+
+// Counts returns the number of added, removed and modified paths
+func (d *SnapshotDiff) Counts() (added, removed, modified int) {
+	for _, c := range d.Changes {
+		switch c.Modifier {
+		case DiffAdded:
+			added++
+		case DiffRemoved:
+			removed++
+		default:
+			modified++
+		}
+	}
+	return added, removed, modified
+}
+
+// SizeDelta returns the total size change over all changed files
+func (d *SnapshotDiff) SizeDelta() int64 {
+	var delta int64
+	for _, c := range d.Changes {
+		delta += c.SizeDelta()
+	}
+	return delta
+}
+
+// LargestChanges returns up to n changes with the biggest absolute size delta
+func (d *SnapshotDiff) LargestChanges(n int) []DiffChange {
+	changes := make([]DiffChange, 0, len(d.Changes))
+	for _, c := range d.Changes {
+		if c.SizeDelta() != 0 {
+			changes = append(changes, c)
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		return abs64(changes[i].SizeDelta()) > abs64(changes[j].SizeDelta())
+	})
+	if len(changes) > n {
+		changes = changes[:n]
+	}
+	return changes
+}
+
+// diffMessage is a single line of restic diff --json output
+type diffMessage struct {
+	MessageType  string    `json:"message_type"`
+	Path         string    `json:"path"`
+	Modifier     string    `json:"modifier"`
+	ChangedFiles int       `json:"changed_files"`
+	Added        DiffStats `json:"added"`
+	Removed      DiffStats `json:"removed"`
+}
+
+// parseDiffOutput parses the line-delimited JSON output of restic diff --json
+func parseDiffOutput(output string) (*SnapshotDiff, error) {
+	diff := &SnapshotDiff{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var msg diffMessage
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			return nil, fmt.Errorf("cannot parse diff output: %w", err)
+		}
+		switch msg.MessageType {
+		case "change":
+			diff.Changes = append(diff.Changes, DiffChange{Path: msg.Path, Modifier: msg.Modifier})
+		case "statistics":
+			diff.ChangedFiles = msg.ChangedFiles
+			diff.Added = msg.Added
+			diff.Removed = msg.Removed
+		}
+	}
+	return diff, nil
+}
+
+// DiffSnapshots compares two snapshots and annotates each change with file sizes
+func (r *ResticManager) DiffSnapshots(ctx context.Context, from, to string) (*SnapshotDiff, error) {
+	opts := util.CommandOptions{
+		Timeout:    10 * time.Minute,
+		CaptureOut: true,
+		CaptureErr: true,
+	}
+
+	result, err := r.run(ctx, []string{"diff", "--json", from, to}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("diff failed: %w", err)
+	}
+	if !result.IsSuccess() {
+		return nil, fmt.Errorf("diff failed: %s", result.Stderr)
+	}
+
+	diff, err := parseDiffOutput(result.Stdout)
+	if err != nil {
+		return nil, err
+	}
+	diff.From = from
+	diff.To = to
+
+	// restic diff has no per-file sizes, take them from both listings
+	oldSizes, err := r.fileSizes(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	newSizes, err := r.fileSizes(ctx, to)
+	if err != nil {
+		return nil, err
+	}
+	for i := range diff.Changes {
+		p := strings.TrimSuffix(diff.Changes[i].Path, "/")
+		diff.Changes[i].OldSize = oldSizes[p]
+		diff.Changes[i].NewSize = newSizes[p]
+	}
+
+	return diff, nil
+}
+
+// fileSizes maps each file path in a snapshot to its size
+func (r *ResticManager) fileSizes(ctx context.Context, snapshotID string) (map[string]int64, error) {
+	nodes, err := r.ListFiles(ctx, snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	sizes := make(map[string]int64, len(nodes))
+	for _, n := range nodes {
+		if !n.IsDir() {
+			sizes[n.Path] = n.Size
+		}
+	}
+	return sizes, nil
+}
+
+// LatestPair returns the IDs of the two most recent snapshots with the given tag (older first)
+func (r *ResticManager) LatestPair(ctx context.Context, tag string) (older, newer string, err error) {
+	snapshots, err := r.ListSnapshots(ctx, tag, 0)
+	if err != nil {
+		return "", "", err
+	}
+	if len(snapshots) < 2 {
+		return "", "", fmt.Errorf("need at least two snapshots tagged %s, found %d", tag, len(snapshots))
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshotTime(snapshots[i]).Before(snapshotTime(snapshots[j]))
+	})
+	n := len(snapshots)
+	return snapshots[n-2].ShortID, snapshots[n-1].ShortID, nil
+}
+
+// FormatSizeDelta formats a signed size change
+func FormatSizeDelta(delta int64) string {
+	switch {
+	case delta > 0:
+		return "+" + util.FormatSize(delta)
+	case delta < 0:
+		return "-" + util.FormatSize(-delta)
+	default:
+		return "0"
+	}
+}
+
+// snapshotTime parses a snapshot timestamp, returning the zero time if it is malformed
+func snapshotTime(s Snapshot) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, s.Time)
+	return t
+}
+
+func abs64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
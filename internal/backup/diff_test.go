@@ -0,0 +1,54 @@
+package backup
+
+import "testing"
+
+func TestParseDiffOutput(t *testing.T) {
+	output := `{"message_type":"change","path":"/opt/stacks/app/data.db","modifier":"M"}
+{"message_type":"change","path":"/opt/stacks/app/new.log","modifier":"+"}
+{"message_type":"change","path":"/opt/stacks/app/old/","modifier":"-"}
+{"message_type":"change","path":"/opt/stacks/app/compose.yml","modifier":"U"}
+{"message_type":"statistics","source_snapshot":"aaaa","target_snapshot":"bbbb","changed_files":2,"added":{"files":2,"dirs":0,"others":0,"data_blobs":3,"tree_blobs":1,"bytes":4096},"removed":{"files":1,"dirs":1,"others":0,"data_blobs":1,"tree_blobs":1,"bytes":1024}}
+`
+	diff, err := parseDiffOutput(output)
+	if err != nil {
+		t.Fatalf("parseDiffOutput error: %v", err)
+	}
+
+	if len(diff.Changes) != 4 {
+		t.Fatalf("Expected 4 changes, got %d", len(diff.Changes))
+	}
+	added, removed, modified := diff.Counts()
+	if added != 1 || removed != 1 || modified != 2 {
+		t.Fatalf("Counts: got %d/%d/%d, want 1/1/2", added, removed, modified)
+	}
+	if diff.ChangedFiles != 2 || diff.Added.Bytes != 4096 || diff.Removed.Dirs != 1 {
+		t.Fatalf("Unexpected statistics: %+v", diff)
+	}
+
+	diff.Changes[0].OldSize, diff.Changes[0].NewSize = 100, 1100
+	diff.Changes[1].NewSize = 50
+	diff.Changes[2].OldSize = 2000
+	if got := diff.SizeDelta(); got != -950 {
+		t.Fatalf("SizeDelta: got %d, want -950", got)
+	}
+
+	largest := diff.LargestChanges(2)
+	if len(largest) != 2 || largest[0].Path != "/opt/stacks/app/old/" || largest[1].Path != "/opt/stacks/app/data.db" {
+		t.Fatalf("Unexpected largest changes: %+v", largest)
+	}
+}
+
+func TestParseDiffOutputInvalid(t *testing.T) {
+	if _, err := parseDiffOutput("not json\n"); err == nil {
+		t.Fatalf("Expected error for invalid output")
+	}
+}
+
+func TestFormatSizeDelta(t *testing.T) {
+	tests := map[int64]string{0: "0", 2048: "+2.00 KB", -2048: "-2.00 KB"}
+	for in, want := range tests {
+		if got := FormatSizeDelta(in); got != want {
+			t.Errorf("FormatSizeDelta(%d) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -2,6 +2,7 @@
 package backup
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"strings"
@@ -26,6 +27,7 @@ type DockerManager struct {
 	stackStates  map[string]StackState
 	dryRun       bool
 	outputWriter io.Writer
+	quiet        bool // Progress goes to the log file only
 }
 
 // NewDockerManager creates a new Docker manager
@@ -38,8 +40,23 @@ func NewDockerManager(timeoutSeconds int, dryRun bool, outputWriter io.Writer) *
 	}
 }
 
+// SetQuiet keeps progress messages off the console, for callers such as the
+// TUI that draw the screen themselves and report the outcome on their own
+func (d *DockerManager) SetQuiet(quiet bool) {
+	d.quiet = quiet
+}
+
+// progress logs a progress message, to the log file only when quiet
+func (d *DockerManager) progress(format string, args ...interface{}) {
+	if d.quiet {
+		util.LogFileOnly(util.LevelProgress, format, args...)
+		return
+	}
+	util.LogProgress(format, args...)
+}
+
 // CheckStackStatus checks if a stack has running containers
-func (d *DockerManager) CheckStackStatus(dirPath string) (StackState, error) {
+func (d *DockerManager) CheckStackStatus(ctx context.Context, dirPath string) (StackState, error) {
 	opts := util.CommandOptions{
 		Dir:        dirPath,
 		Timeout:    30 * time.Second,
@@ -47,7 +64,7 @@ func (d *DockerManager) CheckStackStatus(dirPath string) (StackState, error) {
 		CaptureErr: true,
 	}
 
-	result, err := util.RunCommand("docker", []string{
+	result, err := util.RunCommand(ctx, "docker", []string{
 		"compose", "ps", "--services", "--filter", "status=running",
 	}, opts)
 
@@ -71,8 +88,8 @@ func (d *DockerManager) CheckStackStatus(dirPath string) (StackState, error) {
 }
 
 // StoreInitialState saves the initial state of a stack
-func (d *DockerManager) StoreInitialState(name, dirPath string) error {
-	state, err := d.CheckStackStatus(dirPath)
+func (d *DockerManager) StoreInitialState(ctx context.Context, name, dirPath string) error {
+	state, err := d.CheckStackStatus(ctx, dirPath)
 	if err != nil {
 		d.stackStates[name] = StateUnknown
 		return err
@@ -90,18 +107,18 @@ func (d *DockerManager) GetStoredState(name string) StackState {
 }
 
 // SmartStop stops a stack only if it was initially running
-func (d *DockerManager) SmartStop(name, dirPath string) error {
+func (d *DockerManager) SmartStop(ctx context.Context, name, dirPath string) error {
 	state := d.GetStoredState(name)
 
 	if state != StateRunning {
-		util.LogProgress("Skipping stop for stack (was %s): %s", state, name)
+		d.progress("Skipping stop for stack (was %s): %s", state, name)
 		return nil
 	}
 
-	util.LogProgress("Stopping Docker stack: %s", name)
+	d.progress("Stopping Docker stack: %s", name)
 
 	if d.dryRun {
-		util.LogProgress("[DRY RUN] Would stop stack: %s", name)
+		d.progress("[DRY RUN] Would stop stack: %s", name)
 		return nil
 	}
 
@@ -115,7 +132,7 @@ func (d *DockerManager) SmartStop(name, dirPath string) error {
 		OutputWriter: d.outputWriter,
 	}
 
-	result, err := util.RunCommand("docker", []string{
+	result, err := util.RunCommand(ctx, "docker", []string{
 		"compose", "down", "--timeout", fmt.Sprintf("%d", int(d.timeout.Seconds())),
 	}, opts)
 
@@ -126,18 +143,22 @@ func (d *DockerManager) SmartStop(name, dirPath string) error {
 	}
 
 	// Wait for containers to stop
-	time.Sleep(2 * time.Second)
+	if err := util.SleepContext(ctx, 2*time.Second); err != nil {
+		return err
+	}
 
 	// Verify stopped - after down, containers are removed so check for StateStopped or StateNotFound
 	for i := 0; i < 3; i++ {
-		state, _ := d.CheckStackStatus(dirPath)
+		state, _ := d.CheckStackStatus(ctx, dirPath)
 		if state == StateStopped || state == StateNotFound {
-			util.LogProgress("Successfully stopped stack: %s", name)
+			d.progress("Successfully stopped stack: %s", name)
 			return nil
 		}
 		if i < 2 {
-			util.LogProgress("Waiting for stack to stop: %s (attempt %d/3)", name, i+1)
-			time.Sleep(3 * time.Second)
+			d.progress("Waiting for stack to stop: %s (attempt %d/3)", name, i+1)
+			if err := util.SleepContext(ctx, 3*time.Second); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -145,12 +166,12 @@ func (d *DockerManager) SmartStop(name, dirPath string) error {
 }
 
 // SmartStart starts a stack only if it was initially running
-func (d *DockerManager) SmartStart(name, dirPath string) error {
+func (d *DockerManager) SmartStart(ctx context.Context, name, dirPath string) error {
 	state := d.GetStoredState(name)
 
 	// Skip restart for stacks that were explicitly stopped or not found
 	if state == StateStopped || state == StateNotFound {
-		util.LogProgress("Skipping restart for stack (was %s): %s", state, name)
+		d.progress("Skipping restart for stack (was %s): %s", state, name)
 		return nil
 	}
 
@@ -158,11 +179,11 @@ func (d *DockerManager) SmartStart(name, dirPath string) error {
 	if state == StateUnknown {
 		util.LogWarn("State was unknown for %s, restarting defensively", name)
 	} else {
-		util.LogProgress("Restarting Docker stack: %s", name)
+		d.progress("Restarting Docker stack: %s", name)
 	}
 
 	if d.dryRun {
-		util.LogProgress("[DRY RUN] Would restart stack: %s", name)
+		d.progress("[DRY RUN] Would restart stack: %s", name)
 		return nil
 	}
 
@@ -175,7 +196,7 @@ func (d *DockerManager) SmartStart(name, dirPath string) error {
 		OutputWriter: d.outputWriter,
 	}
 
-	result, err := util.RunCommand("docker", []string{"compose", "up", "-d"}, opts)
+	result, err := util.RunCommand(ctx, "docker", []string{"compose", "up", "-d"}, opts)
 
 	if result.TimedOut {
 		return fmt.Errorf("up command timed out after %v", timeout)
@@ -185,18 +206,22 @@ func (d *DockerManager) SmartStart(name, dirPath string) error {
 	}
 
 	// Wait for containers to start
-	time.Sleep(2 * time.Second)
+	if err := util.SleepContext(ctx, 2*time.Second); err != nil {
+		return err
+	}
 
 	// Verify started
 	for i := 0; i < 3; i++ {
-		currentState, _ := d.CheckStackStatus(dirPath)
+		currentState, _ := d.CheckStackStatus(ctx, dirPath)
 		if currentState == StateRunning {
-			util.LogProgress("Successfully restarted stack: %s", name)
+			d.progress("Successfully restarted stack: %s", name)
 			return nil
 		}
 		if i < 2 {
-			util.LogProgress("Waiting for stack to start: %s (attempt %d/3)", name, i+1)
-			time.Sleep(3 * time.Second)
+			d.progress("Waiting for stack to start: %s (attempt %d/3)", name, i+1)
+			if err := util.SleepContext(ctx, 3*time.Second); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -204,11 +229,11 @@ func (d *DockerManager) SmartStart(name, dirPath string) error {
 }
 
 // ForceStart unconditionally starts a stack (for recovery)
-func (d *DockerManager) ForceStart(name, dirPath string) error {
-	util.LogProgress("Force starting Docker stack: %s", name)
+func (d *DockerManager) ForceStart(ctx context.Context, name, dirPath string) error {
+	d.progress("Force starting Docker stack: %s", name)
 
 	if d.dryRun {
-		util.LogProgress("[DRY RUN] Would force start stack: %s", name)
+		d.progress("[DRY RUN] Would force start stack: %s", name)
 		return nil
 	}
 
@@ -220,19 +245,82 @@ func (d *DockerManager) ForceStart(name, dirPath string) error {
 		OutputWriter: d.outputWriter,
 	}
 
-	_, err := util.RunCommand("docker", []string{"compose", "up", "-d"}, opts)
+	_, err := util.RunCommand(ctx, "docker", []string{"compose", "up", "-d"}, opts)
 	return err
 }
 
+// Stop takes a stack down regardless of its initial state (for manual control)
+func (d *DockerManager) Stop(ctx context.Context, name, dirPath string) error {
+	d.progress("Stopping Docker stack: %s", name)
+
+	if d.dryRun {
+		d.progress("[DRY RUN] Would stop stack: %s", name)
+		return nil
+	}
+
+	return d.compose(ctx, dirPath, "down", "--timeout", fmt.Sprintf("%d", int(d.timeout.Seconds())))
+}
+
+// Start brings a stack up regardless of its initial state and fails if compose does
+func (d *DockerManager) Start(ctx context.Context, name, dirPath string) error {
+	d.progress("Starting Docker stack: %s", name)
+
+	if d.dryRun {
+		d.progress("[DRY RUN] Would start stack: %s", name)
+		return nil
+	}
+
+	return d.compose(ctx, dirPath, "up", "-d")
+}
+
+// Restart takes a stack down and brings it up again, picking up compose file changes
+func (d *DockerManager) Restart(ctx context.Context, name, dirPath string) error {
+	if err := d.Stop(ctx, name, dirPath); err != nil {
+		return err
+	}
+	return d.Start(ctx, name, dirPath)
+}
+
+// compose runs a docker compose subcommand in dirPath and fails on a non-zero exit
+func (d *DockerManager) compose(ctx context.Context, dirPath string, args ...string) error {
+	timeout := d.timeout + 30*time.Second
+	opts := util.CommandOptions{
+		Dir:          dirPath,
+		Timeout:      timeout,
+		StreamOut:    true,
+		StreamErr:    true,
+		CaptureErr:   true,
+		OutputWriter: d.outputWriter,
+	}
+
+	result, err := util.RunCommand(ctx, "docker", append([]string{"compose"}, args...), opts)
+	if err != nil {
+		return fmt.Errorf("docker compose %s: %w", args[0], err)
+	}
+	if !result.IsSuccess() {
+		if msg := lastLine(result.Stderr); msg != "" {
+			return fmt.Errorf("docker compose %s failed: %s", args[0], msg)
+		}
+		return fmt.Errorf("docker compose %s failed with exit code %d", args[0], result.ExitCode)
+	}
+	return nil
+}
+
+// lastLine returns the last non-empty line of s
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
 // GetStackServices returns the list of services in a stack
-func (d *DockerManager) GetStackServices(dirPath string) ([]string, error) {
+func (d *DockerManager) GetStackServices(ctx context.Context, dirPath string) ([]string, error) {
 	opts := util.CommandOptions{
 		Dir:        dirPath,
 		Timeout:    30 * time.Second,
 		CaptureOut: true,
 	}
 
-	result, err := util.RunCommand("docker", []string{"compose", "config", "--services"}, opts)
+	result, err := util.RunCommand(ctx, "docker", []string{"compose", "config", "--services"}, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -249,14 +337,14 @@ func (d *DockerManager) GetStackServices(dirPath string) ([]string, error) {
 }
 
 // GetStackContainers returns running container info for a stack
-func (d *DockerManager) GetStackContainers(dirPath string) ([]string, error) {
+func (d *DockerManager) GetStackContainers(ctx context.Context, dirPath string) ([]string, error) {
 	opts := util.CommandOptions{
 		Dir:        dirPath,
 		Timeout:    30 * time.Second,
 		CaptureOut: true,
 	}
 
-	result, err := util.RunCommand("docker", []string{
+	result, err := util.RunCommand(ctx, "docker", []string{
 		"compose", "ps", "--format", "{{.Name}}: {{.Status}}",
 	}, opts)
 	if err != nil {
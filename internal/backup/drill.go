@@ -0,0 +1,426 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"backup-tui/internal/status"
+	"backup-tui/internal/util"
+)
+
+// drillFile is the sandboxed compose file written next to the restored stack
+const drillFile = "drill-compose.json"
+
+// DrillOptions controls how far a restore drill goes
+type DrillOptions struct {
+	Up   bool          // Start the restored stack and wait for it to become healthy
+	Wait time.Duration // How long to wait for the services with Up
+	Keep bool          // Keep the scratch directory for inspection
+}
+
+// DrillStep is one stage of a restore drill
+type DrillStep struct {
+	Name   string // restore, config, up, down
+	Detail string
+	Err    error
+}
+
+// DrillReport is the outcome of a restore drill
+type DrillReport struct {
+	Stack    string
+	Snapshot Snapshot
+	Dir      string // The restored stack directory
+	Project  string // Compose project name used in the sandbox
+	Steps    []DrillStep
+	Warnings []string
+}
+
+// Passed reports whether every step of the drill succeeded
+func (r *DrillReport) Passed() bool {
+	for _, step := range r.Steps {
+		if step.Err != nil {
+			return false
+		}
+	}
+	return len(r.Steps) > 0
+}
+
+// Err returns the error of the first failed step
+func (r *DrillReport) Err() error {
+	for _, step := range r.Steps {
+		if step.Err != nil {
+			return fmt.Errorf("%s: %w", step.Name, step.Err)
+		}
+	}
+	return nil
+}
+
+// step records the result of a drill stage and returns err
+func (r *DrillReport) step(name, detail string, err error) error {
+	r.Steps = append(r.Steps, DrillStep{Name: name, Detail: detail, Err: err})
+	return err
+}
+
+// Drill restores the latest snapshot of a stack into a scratch directory and
+// checks that docker compose accepts it under a project name of its own, with
+// host ports, container names and shared volumes and networks rewritten so
+// nothing collides with the live stack. With opts.Up the sandboxed stack is
+// started, waited for until healthy and torn down again. The report is
+// printed and the result recorded in the status file.
+func (s *Service) Drill(ctx context.Context, name string, opts DrillOptions) (*DrillReport, error) {
+	if err := s.dirlist.Load(); err != nil {
+		return nil, fmt.Errorf("cannot load dirlist: %w", err)
+	}
+	// A stack that is gone from disk, or never made it into the dirlist, is
+	// looked up by its snapshot tag
+	id, tag := name, name
+	if resolved, err := s.dirlist.Resolve(name); err == nil {
+		id, tag = resolved, s.dirlist.SnapshotTag(resolved)
+	}
+
+	if s.dryRun {
+		util.LogProgress("[DRY RUN] Would restore the latest snapshot of %s into a sandbox and validate it", id)
+		if opts.Up {
+			util.LogProgress("[DRY RUN] Would start the sandboxed stack, wait for it and tear it down")
+		}
+		return nil, nil
+	}
+	if !DockerComposeAvailable() {
+		return nil, fmt.Errorf("docker compose is not available")
+	}
+	if err := s.restic.CheckRepository(ctx); err != nil {
+		return nil, fmt.Errorf("cannot access repository: %w", err)
+	}
+	defer s.restic.Cleanup()
+
+	snapshots, err := s.restic.ListSnapshots(ctx, tag, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, fmt.Errorf("no snapshots found for %s", id)
+	}
+	snap := snapshots[0]
+	if len(snap.Paths) != 1 {
+		return nil, fmt.Errorf("snapshot %s holds %d paths, expected the stack directory only", snap.ShortID, len(snap.Paths))
+	}
+
+	start := time.Now()
+	report := &DrillReport{Stack: id, Snapshot: snap, Project: drillProject(tag, start)}
+	s.runDrill(ctx, report, opts)
+
+	printDrillReport(report)
+	result := status.DrillResult{Result: status.NewResult(start, report.Err()), Snapshot: snap.ShortID, Started: opts.Up}
+	if err := status.RecordDrill(s.config.LogDir, id, result); err != nil {
+		util.LogWarn("Cannot record drill result: %v", err)
+	}
+	return report, report.Err()
+}
+
+// runDrill runs the drill stages, stopping at the first that fails
+func (s *Service) runDrill(ctx context.Context, report *DrillReport, opts DrillOptions) {
+	scratch, err := os.MkdirTemp("", "backup-drill-*")
+	if err != nil {
+		report.step("restore", "", fmt.Errorf("cannot create scratch directory: %w", err))
+		return
+	}
+	if opts.Keep {
+		report.Warnings = append(report.Warnings, "Scratch directory kept: "+scratch)
+	} else {
+		defer os.RemoveAll(scratch)
+	}
+
+	// Restore
+	snap := report.Snapshot
+	util.LogProgress("Restoring snapshot %s of %s into %s", snap.ShortID, report.Stack, scratch)
+	report.Dir = filepath.Join(scratch, snap.Paths[0])
+	if report.step("restore", "snapshot "+snap.ShortID, s.restic.restoreSnapshot(ctx, snap.ShortID, scratch)) != nil {
+		return
+	}
+
+	// Render and validate the compose configuration under the drill project name
+	util.LogProgress("Validating the compose configuration as project %s", report.Project)
+	project, err := s.composeConfig(ctx, report.Dir, report.Project)
+	if report.step("config", countServices(project), err) != nil {
+		return
+	}
+	report.Warnings = append(report.Warnings, sandboxProject(project, report.Project, report.Dir, scratch)...)
+
+	if !opts.Up {
+		return
+	}
+	file := filepath.Join(scratch, drillFile)
+	data, err := json.MarshalIndent(project, "", "  ")
+	if err == nil {
+		err = os.WriteFile(file, data, 0o600)
+	}
+	if err != nil {
+		report.step("up", "", fmt.Errorf("cannot write sandboxed compose file: %w", err))
+		return
+	}
+
+	// Start and wait, then always tear down, even when cancelled
+	util.LogProgress("Starting %s and waiting up to %s for its services", report.Project, opts.Wait)
+	upErr := s.drillCompose(ctx, report.Dir, opts.Wait, "-p", report.Project, "-f", file, "up", "-d", "--wait")
+	report.step("up", "services running and healthy", upErr)
+
+	util.LogProgress("Tearing down %s", report.Project)
+	downErr := s.drillCompose(context.WithoutCancel(ctx), report.Dir, s.docker.timeout,
+		"-p", report.Project, "-f", file, "down", "--volumes", "--remove-orphans")
+	report.step("down", "containers, networks and volumes removed", downErr)
+}
+
+// restoreSnapshot restores a whole snapshot into targetDir without streaming its output
+func (r *ResticManager) restoreSnapshot(ctx context.Context, snapshotID, targetDir string) error {
+	opts := util.CommandOptions{
+		Timeout:    time.Duration(r.config.Timeout) * time.Second,
+		CaptureOut: true,
+		CaptureErr: true,
+	}
+	result, err := r.run(ctx, []string{"restore", snapshotID, "--target", targetDir}, opts)
+	if err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+	if !result.IsSuccess() {
+		return fmt.Errorf("restore failed: %s", lastLine(result.Stderr))
+	}
+	return nil
+}
+
+// composeConfig renders the compose configuration in dir as JSON, with
+// resource names derived from project
+func (s *Service) composeConfig(ctx context.Context, dir, project string) (map[string]any, error) {
+	opts := util.CommandOptions{
+		Dir:        dir,
+		Timeout:    time.Minute,
+		CaptureOut: true,
+		CaptureErr: true,
+	}
+	result, err := util.RunCommand(ctx, "docker", []string{"compose", "-p", project, "config", "--format", "json"}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("docker compose config: %w", err)
+	}
+	if !result.IsSuccess() {
+		return nil, fmt.Errorf("docker compose config failed: %s", lastLine(result.Stderr))
+	}
+
+	var config map[string]any
+	if err := json.Unmarshal([]byte(result.Stdout), &config); err != nil {
+		return nil, fmt.Errorf("cannot parse compose configuration: %w", err)
+	}
+	return config, nil
+}
+
+// drillCompose runs docker compose in dir with output captured, failing on a non-zero exit
+func (s *Service) drillCompose(ctx context.Context, dir string, timeout time.Duration, args ...string) error {
+	opts := util.CommandOptions{
+		Dir:        dir,
+		Timeout:    timeout,
+		CaptureOut: true,
+		CaptureErr: true,
+	}
+	result, err := util.RunCommand(ctx, "docker", append([]string{"compose"}, args...), opts)
+	if err != nil {
+		return err
+	}
+	if !result.IsSuccess() {
+		return fmt.Errorf("docker compose failed: %s", lastLine(result.Stderr))
+	}
+	return nil
+}
+
+// drillProject returns a compose project name for a drill of the stack tagged tag
+func drillProject(tag string, start time.Time) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return '-'
+	}, tag)
+	return fmt.Sprintf("drill-%s-%s", name, start.Format("20060102150405"))
+}
+
+// countServices describes the number of services in a rendered compose configuration
+func countServices(project map[string]any) string {
+	services, _ := project["services"].(map[string]any)
+	return fmt.Sprintf("%d service(s)", len(services))
+}
+
+// sandboxProject rewrites a rendered compose configuration so it can run next
+// to the live stack and cannot touch its data:
+//
+//   - the project is renamed, so containers, networks and volumes get new names
+//   - published host ports are dropped, docker picks free ones
+//   - fixed container names are dropped
+//   - host and container network, pid and ipc modes are dropped, so the services
+//     get their own namespaces on the project network instead of the host's ports
+//   - external volumes and networks, and those with a fixed name, become new, empty ones
+//   - writable bind mounts outside the restored directory point to empty
+//     directories below scratch, as their data is not part of the backup
+//
+// It returns a warning for each change that makes the sandbox differ from production.
+func sandboxProject(project map[string]any, name, dir, scratch string) []string {
+	var warnings []string
+	project["name"] = name
+
+	for _, kind := range []string{"volumes", "networks"} {
+		resources, _ := project[kind].(map[string]any)
+		for _, key := range sortedKeys(resources) {
+			res, ok := resources[key].(map[string]any)
+			if !ok {
+				continue
+			}
+			current, _ := res["name"].(string)
+			switch {
+			case res["external"] == true:
+				warnings = append(warnings, fmt.Sprintf("external %s %s replaced with an empty one", strings.TrimSuffix(kind, "s"), current))
+				delete(res, "external")
+			case strings.HasPrefix(current, name+"_"):
+				continue // Named after the drill project already
+			default:
+				warnings = append(warnings, fmt.Sprintf("%s %s renamed, its name is fixed in the compose file", strings.TrimSuffix(kind, "s"), current))
+			}
+			res["name"] = name + "_" + key
+		}
+	}
+
+	services, _ := project["services"].(map[string]any)
+	for _, svcName := range sortedKeys(services) {
+		svc, ok := services[svcName].(map[string]any)
+		if !ok {
+			continue
+		}
+		delete(svc, "container_name")
+
+		for _, key := range []string{"network_mode", "pid", "ipc"} {
+			mode, _ := svc[key].(string)
+			if mode == "host" || strings.HasPrefix(mode, "container:") {
+				warnings = append(warnings, fmt.Sprintf("%s: %s %s dropped, the sandbox must not share it with the live stack", svcName, key, mode))
+				delete(svc, key)
+			}
+		}
+
+		ports, _ := svc["ports"].([]any)
+		for _, p := range ports {
+			if port, ok := p.(map[string]any); ok && port["published"] != nil {
+				delete(port, "published")
+				delete(port, "host_ip")
+			}
+		}
+
+		mounts, _ := svc["volumes"].([]any)
+		for _, m := range mounts {
+			mount, ok := m.(map[string]any)
+			if !ok || mount["type"] != "bind" {
+				continue
+			}
+			source, _ := mount["source"].(string)
+			if source == "" || source == dir || strings.HasPrefix(source, dir+string(filepath.Separator)) {
+				continue
+			}
+			if mount["read_only"] == true {
+				warnings = append(warnings, fmt.Sprintf("%s: read-only bind mount %s is shared with the host", svcName, source))
+				continue
+			}
+			empty := filepath.Join(scratch, "binds", filepath.Clean(source))
+			warnings = append(warnings, fmt.Sprintf("%s: bind mount %s is not in the backup, replaced with an empty directory", svcName, source))
+			mount["source"] = empty
+			if err := os.MkdirAll(empty, 0o755); err != nil {
+				warnings = append(warnings, fmt.Sprintf("%s: cannot create %s: %v", svcName, empty, err))
+			}
+		}
+	}
+	return warnings
+}
+
+// sortedKeys returns the keys of m in order, so warnings come out the same each run
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// printDrillReport prints the steps and warnings of a drill
+func printDrillReport(r *DrillReport) {
+	fmt.Println()
+	fmt.Printf("%sRestore Drill: %s%s\n", util.ColorGreen, r.Stack, util.ColorReset)
+	fmt.Println("====================")
+	snapTime := r.Snapshot.Time
+	if t, err := time.Parse(time.RFC3339Nano, r.Snapshot.Time); err == nil {
+		snapTime = t.Format("2006-01-02 15:04")
+	}
+	fmt.Printf("Snapshot: %s (%s)\n", r.Snapshot.ShortID, snapTime)
+	fmt.Printf("Sandbox:  %s\n", r.Dir)
+	fmt.Printf("Project:  %s\n", r.Project)
+	fmt.Println()
+
+	for _, step := range r.Steps {
+		if step.Err != nil {
+			fmt.Printf("  %sFAILED%s  %-8s %v\n", util.ColorRed, util.ColorReset, step.Name, step.Err)
+		} else {
+			fmt.Printf("  %sOK%s      %-8s %s\n", util.ColorGreen, util.ColorReset, step.Name, step.Detail)
+		}
+	}
+
+	if len(r.Warnings) > 0 {
+		fmt.Println()
+		fmt.Println("Notes:")
+		for _, w := range r.Warnings {
+			fmt.Printf("  %s%s%s\n", util.ColorYellow, w, util.ColorReset)
+		}
+	}
+
+	fmt.Println()
+	if r.Passed() {
+		fmt.Printf("Result: %sPASSED%s\n", util.ColorGreen, util.ColorReset)
+	} else {
+		fmt.Printf("Result: %sFAILED%s\n", util.ColorRed, util.ColorReset)
+	}
+	fmt.Println()
+}
+
+// printDrillStatus prints the last restore drill of each stack
+func (s *Service) printDrillStatus() {
+	fmt.Print("Restore Drills: ")
+	st, err := status.Load(s.config.LogDir)
+	if err != nil {
+		fmt.Printf("%sERROR: %v%s\n", util.ColorRed, err, util.ColorReset)
+		return
+	}
+	if len(st.Drills) == 0 {
+		fmt.Printf("%sNEVER RUN%s (run 'drill STACK')\n", util.ColorYellow, util.ColorReset)
+		return
+	}
+	fmt.Println()
+
+	names := make([]string, 0, len(st.Drills))
+	for name := range st.Drills {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		d := st.Drills[name]
+		kind := "config only"
+		if d.Started {
+			kind = "started"
+		}
+		when := d.Time.Format("2006-01-02 15:04")
+		if d.Success {
+			fmt.Printf("  %-20s %sOK%s (%s, snapshot %s, %s)\n", name, util.ColorGreen, util.ColorReset, kind, d.Snapshot, when)
+		} else {
+			fmt.Printf("  %-20s %sFAILED%s (%s, snapshot %s, %s: %s)\n", name, util.ColorRed, util.ColorReset, kind, d.Snapshot, when, d.Error)
+		}
+	}
+}
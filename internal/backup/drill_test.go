@@ -0,0 +1,97 @@
+package backup
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSandboxProject(t *testing.T) {
+	dir := "/tmp/drill/opt/stacks/web"
+	scratch := t.TempDir()
+	var project map[string]any
+	data := `{
+		"name": "drill-web-1",
+		"services": {
+			"app": {
+				"container_name": "web",
+				"ports": [{"target": 80, "published": "8080", "host_ip": "0.0.0.0", "protocol": "tcp"}],
+				"volumes": [
+					{"type": "bind", "source": "/tmp/drill/opt/stacks/web/data", "target": "/data"},
+					{"type": "bind", "source": "/srv/media", "target": "/media"},
+					{"type": "bind", "source": "/etc/localtime", "target": "/etc/localtime", "read_only": true},
+					{"type": "volume", "source": "cache", "target": "/cache"}
+				]
+			},
+			"metrics": {"network_mode": "host", "pid": "host", "ipc": "private"},
+			"sidecar": {"network_mode": "container:web"}
+		},
+		"volumes": {
+			"cache": {"name": "drill-web-1_cache"},
+			"db": {"name": "web-db"}
+		},
+		"networks": {
+			"default": {"name": "drill-web-1_default"},
+			"proxy": {"name": "proxy", "external": true}
+		}
+	}`
+	if err := json.Unmarshal([]byte(data), &project); err != nil {
+		t.Fatal(err)
+	}
+
+	warnings := sandboxProject(project, "drill-web-1", dir, scratch)
+
+	app := project["services"].(map[string]any)["app"].(map[string]any)
+	if _, ok := app["container_name"]; ok {
+		t.Error("container_name was kept")
+	}
+	port := app["ports"].([]any)[0].(map[string]any)
+	if _, ok := port["published"]; ok || port["target"] != float64(80) {
+		t.Errorf("Unexpected port %v", port)
+	}
+	mounts := app["volumes"].([]any)
+	sources := make([]string, len(mounts))
+	for i, m := range mounts {
+		sources[i] = m.(map[string]any)["source"].(string)
+	}
+	want := []string{dir + "/data", filepath.Join(scratch, "binds", "srv/media"), "/etc/localtime", "cache"}
+	for i := range want {
+		if sources[i] != want[i] {
+			t.Errorf("Mount %d has source %s, want %s", i, sources[i], want[i])
+		}
+	}
+
+	volumes := project["volumes"].(map[string]any)
+	if name := volumes["db"].(map[string]any)["name"]; name != "drill-web-1_db" {
+		t.Errorf("Volume with a fixed name is %v", name)
+	}
+	proxy := project["networks"].(map[string]any)["proxy"].(map[string]any)
+	if _, ok := proxy["external"]; ok || proxy["name"] != "drill-web-1_proxy" {
+		t.Errorf("External network kept: %v", proxy)
+	}
+
+	metrics := project["services"].(map[string]any)["metrics"].(map[string]any)
+	if _, ok := metrics["network_mode"]; ok || metrics["pid"] != nil || metrics["ipc"] != "private" {
+		t.Errorf("Host namespaces kept: %v", metrics)
+	}
+
+	joined := strings.Join(warnings, "\n")
+	for _, w := range []string{"volume web-db renamed", "external network proxy", "/srv/media is not in the backup", "/etc/localtime is shared",
+		"metrics: network_mode host", "metrics: pid host", "sidecar: network_mode container:web"} {
+		if !strings.Contains(joined, w) {
+			t.Errorf("Missing warning %q in:\n%s", w, joined)
+		}
+	}
+	if len(warnings) != 7 {
+		t.Errorf("Expected 7 warnings, got:\n%s", joined)
+	}
+}
+
+func TestDrillProject(t *testing.T) {
+	start := time.Date(2026, 10, 18, 4, 5, 6, 0, time.UTC)
+	if got := drillProject("My.Stack-external", start); got != "drill-my-stack-external-20261018040506" {
+		t.Errorf("drillProject = %q", got)
+	}
+}
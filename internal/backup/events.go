@@ -0,0 +1,210 @@
+package backup
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"backup-tui/internal/util"
+)
+
+// EventKind identifies a step in a backup run
+type EventKind int
+
+const (
+	EventRunStarted     EventKind = iota // Stacks lists every stack queued for this run
+	EventStackStarted                    // Processing of Stack has begun
+	EventStackStopped                    // Stack is down and ready to be backed up
+	EventBackupProgress                  // Progress holds restic's latest status
+	EventVerifying                       // Backup done, verification running
+	EventStackRestarted                  // Stack brought back to its initial state
+	EventStackDone                       // Stack processed successfully
+	EventStackFailed                     // Stack failed, Err holds the reason
+	EventStackSkipped                    // Stack not processed because the run was cancelled
+)
+
+// String returns a short human readable name for the event kind
+func (k EventKind) String() string {
+	switch k {
+	case EventRunStarted:
+		return "run started"
+	case EventStackStarted:
+		return "started"
+	case EventStackStopped:
+		return "stopped"
+	case EventBackupProgress:
+		return "backing up"
+	case EventVerifying:
+		return "verifying"
+	case EventStackRestarted:
+		return "restarted"
+	case EventStackDone:
+		return "done"
+	case EventStackFailed:
+		return "failed"
+	case EventStackSkipped:
+		return "skipped"
+	default:
+		return "unknown"
+	}
+}
+
+// BackupProgress is a status update from restic backup --json
+type BackupProgress struct {
+	PercentDone  float64 // 0..1
+	FilesDone    int64
+	TotalFiles   int64
+	BytesDone    int64
+	TotalBytes   int64
+	ErrorCount   int64
+	Elapsed      time.Duration
+	Remaining    time.Duration // Zero when unknown
+	CurrentFiles []string
+}
+
+// Event is a typed notification emitted while a backup run progresses
+type Event struct {
+	Kind     EventKind
+	Stack    string
+	Index    int // 1-based position of Stack in the run
+	Total    int // Number of stacks in the run
+	Stacks   []string
+	Progress *BackupProgress
+	Err      error
+}
+
+// EventFunc receives backup events. It is called from the goroutine running the backup.
+type EventFunc func(Event)
+
+// resticMessage is a single line of restic backup --json output
+type resticMessage struct {
+	MessageType      string   `json:"message_type"`
+	PercentDone      float64  `json:"percent_done"`
+	TotalFiles       int64    `json:"total_files"`
+	FilesDone        int64    `json:"files_done"`
+	TotalBytes       int64    `json:"total_bytes"`
+	BytesDone        int64    `json:"bytes_done"`
+	ErrorCount       int64    `json:"error_count"`
+	SecondsElapsed   float64  `json:"seconds_elapsed"`
+	SecondsRemaining float64  `json:"seconds_remaining"`
+	CurrentFiles     []string `json:"current_files"`
+
+	// summary
+	FilesNew        int64   `json:"files_new"`
+	FilesChanged    int64   `json:"files_changed"`
+	FilesUnmodified int64   `json:"files_unmodified"`
+	DataAdded       int64   `json:"data_added"`
+	TotalDuration   float64 `json:"total_duration"`
+	SnapshotID      string  `json:"snapshot_id"`
+
+	// error
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+	During string `json:"during"`
+	Item   string `json:"item"`
+}
+
+// backupProgressWriter parses restic backup --json output line by line.
+// Status lines go to the progress callback, summary and error lines are
+// rendered as text, and anything that is not JSON is passed through.
+type backupProgressWriter struct {
+	mu       sync.Mutex
+	out      io.Writer
+	progress func(BackupProgress)
+	buf      bytes.Buffer
+}
+
+// newBackupProgressWriter creates a writer that feeds fn and forwards readable output to out
+func newBackupProgressWriter(out io.Writer, fn func(BackupProgress)) *backupProgressWriter {
+	return &backupProgressWriter{out: out, progress: fn}
+}
+
+func (w *backupProgressWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// Incomplete line, keep it for the next write
+			w.buf.Reset()
+			w.buf.WriteString(line)
+			break
+		}
+		w.handleLine(strings.TrimRight(line, "\r\n"))
+	}
+	return len(p), nil
+}
+
+// Flush processes any buffered partial line
+func (w *backupProgressWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf.Len() > 0 {
+		w.handleLine(w.buf.String())
+		w.buf.Reset()
+	}
+}
+
+func (w *backupProgressWriter) handleLine(line string) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return
+	}
+
+	var msg resticMessage
+	if !strings.HasPrefix(trimmed, "{") || json.Unmarshal([]byte(trimmed), &msg) != nil {
+		w.print(line + "\n")
+		return
+	}
+
+	switch msg.MessageType {
+	case "status":
+		if w.progress != nil {
+			w.progress(BackupProgress{
+				PercentDone:  msg.PercentDone,
+				FilesDone:    msg.FilesDone,
+				TotalFiles:   msg.TotalFiles,
+				BytesDone:    msg.BytesDone,
+				TotalBytes:   msg.TotalBytes,
+				ErrorCount:   msg.ErrorCount,
+				Elapsed:      time.Duration(msg.SecondsElapsed * float64(time.Second)),
+				Remaining:    time.Duration(msg.SecondsRemaining * float64(time.Second)),
+				CurrentFiles: msg.CurrentFiles,
+			})
+		}
+	case "summary":
+		w.print(fmt.Sprintf("Files: %d new, %d changed, %d unmodified\n", msg.FilesNew, msg.FilesChanged, msg.FilesUnmodified))
+		w.print(fmt.Sprintf("Added to the repository: %s in %.1fs\n", util.FormatSize(msg.DataAdded), msg.TotalDuration))
+		if msg.SnapshotID != "" {
+			w.print(fmt.Sprintf("snapshot %s saved\n", shortID(msg.SnapshotID)))
+		}
+	case "error":
+		item := msg.Item
+		if item == "" {
+			item = msg.During
+		}
+		w.print(fmt.Sprintf("ERROR: %s: %s\n", item, msg.Error.Message))
+	}
+}
+
+func (w *backupProgressWriter) print(s string) {
+	if w.out != nil {
+		_, _ = io.WriteString(w.out, s)
+	}
+}
+
+// shortID shortens a snapshot ID the way restic displays it
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
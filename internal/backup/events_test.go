@@ -0,0 +1,61 @@
+package backup
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBackupProgressWriter(t *testing.T) {
+	var out strings.Builder
+	var updates []BackupProgress
+	w := newBackupProgressWriter(&out, func(p BackupProgress) { updates = append(updates, p) })
+
+	status := `{"message_type":"status","seconds_elapsed":4,"seconds_remaining":12,"percent_done":0.25,"total_files":40,"files_done":10,"total_bytes":4096,"bytes_done":1024,"current_files":["/opt/stacks/app/data.db"]}`
+	errLine := `{"message_type":"error","error":{"message":"permission denied"},"during":"archival","item":"/opt/stacks/app/secret"}`
+	summary := `{"message_type":"summary","files_new":3,"files_changed":1,"files_unmodified":36,"data_added":2048,"total_duration":5.5,"snapshot_id":"0123456789abcdef"}`
+
+	input := status + "\n" + errLine + "\nusing parent snapshot 1a2b3c4d\n" + summary
+	for i := 0; i < len(input); i += 11 {
+		end := i + 11
+		if end > len(input) {
+			end = len(input)
+		}
+		if _, err := w.Write([]byte(input[i:end])); err != nil {
+			t.Fatalf("Write error: %v", err)
+		}
+	}
+	w.Flush()
+
+	if len(updates) != 1 {
+		t.Fatalf("Expected 1 progress update, got %d", len(updates))
+	}
+	p := updates[0]
+	if p.PercentDone != 0.25 || p.FilesDone != 10 || p.TotalBytes != 4096 {
+		t.Fatalf("Unexpected progress: %+v", p)
+	}
+	if p.Remaining != 12*time.Second || len(p.CurrentFiles) != 1 {
+		t.Fatalf("Unexpected progress timing or files: %+v", p)
+	}
+
+	text := out.String()
+	if strings.Contains(text, "percent_done") {
+		t.Fatalf("Status lines should not be forwarded as text: %q", text)
+	}
+	for _, want := range []string{
+		"ERROR: /opt/stacks/app/secret: permission denied",
+		"using parent snapshot 1a2b3c4d",
+		"Files: 3 new, 1 changed, 36 unmodified",
+		"snapshot 01234567 saved",
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("Output missing %q: %q", want, text)
+		}
+	}
+}
+
+func TestEventKindString(t *testing.T) {
+	if EventStackFailed.String() != "failed" || EventKind(99).String() != "unknown" {
+		t.Fatalf("Unexpected event kind names")
+	}
+}
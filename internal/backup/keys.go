@@ -0,0 +1,238 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"backup-tui/internal/config"
+	"backup-tui/internal/util"
+)
+
+// keyTimeout bounds restic key commands, which only touch the keys/ directory
+const keyTimeout = 2 * time.Minute
+
+// Key is a key that can open the repository (from restic key list --json)
+type Key struct {
+	Current  bool   `json:"current"` // The key the configured password opens
+	ID       string `json:"id"`
+	UserName string `json:"userName"`
+	HostName string `json:"hostName"`
+	Created  string `json:"created"`
+}
+
+// ShortID returns the first 8 characters of the key ID, as restic prints them
+func (k Key) ShortID() string {
+	return shortID(k.ID)
+}
+
+// runKeyCommand runs restic key with args and returns its output
+func (r *ResticManager) runKeyCommand(ctx context.Context, args ...string) (string, error) {
+	if !util.CommandExists("restic") {
+		return "", fmt.Errorf("restic not found in PATH")
+	}
+	if err := r.SetupEnv(); err != nil {
+		return "", err
+	}
+
+	opts := util.CommandOptions{
+		Timeout:    keyTimeout,
+		CaptureOut: true,
+		CaptureErr: true,
+	}
+	result, err := r.run(ctx, append([]string{"key"}, args...), opts)
+	if err != nil {
+		return "", fmt.Errorf("restic key %s failed: %w", args[0], err)
+	}
+	if !result.IsSuccess() {
+		return "", fmt.Errorf("restic key %s failed: %s", args[0], strings.TrimSpace(result.Stderr))
+	}
+	return result.Stdout, nil
+}
+
+// ListKeys returns the keys of the repository
+func (r *ResticManager) ListKeys(ctx context.Context) ([]Key, error) {
+	out, err := r.runKeyCommand(ctx, "list", "--json")
+	if err != nil {
+		return nil, err
+	}
+	var keys []Key
+	if err := json.Unmarshal([]byte(out), &keys); err != nil {
+		return nil, fmt.Errorf("cannot parse keys: %w", err)
+	}
+	return keys, nil
+}
+
+// AddKey adds a key opened by the password in passwordFile. User and host
+// are recorded with the key, empty for the current ones.
+func (r *ResticManager) AddKey(ctx context.Context, passwordFile, user, host string) error {
+	if r.dryRun {
+		util.LogProgress("[DRY RUN] Would add a repository key")
+		return nil
+	}
+	args := []string{"add", "--new-password-file", passwordFile}
+	if user != "" {
+		args = append(args, "--user", user)
+	}
+	if host != "" {
+		args = append(args, "--host", host)
+	}
+	_, err := r.runKeyCommand(ctx, args...)
+	return err
+}
+
+// RemoveKey removes a key; restic refuses to remove the key in use
+func (r *ResticManager) RemoveKey(ctx context.Context, id string) error {
+	if r.dryRun {
+		util.LogProgress("[DRY RUN] Would remove repository key %s", id)
+		return nil
+	}
+	_, err := r.runKeyCommand(ctx, "remove", id)
+	return err
+}
+
+// ChangePassword replaces the password of the key in use with the one in passwordFile
+func (r *ResticManager) ChangePassword(ctx context.Context, passwordFile string) error {
+	if r.dryRun {
+		util.LogProgress("[DRY RUN] Would change the repository password")
+		return nil
+	}
+	_, err := r.runKeyCommand(ctx, "passwd", "--new-password-file", passwordFile)
+	return err
+}
+
+// writeSecret writes password to a new file only the owner can read, in dir
+// or the temporary directory when dir is empty
+func writeSecret(dir, pattern, password string) (string, error) {
+	f, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return "", fmt.Errorf("cannot create password file: %w", err)
+	}
+	if err := f.Chmod(0o600); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", fmt.Errorf("cannot set password file permissions: %w", err)
+	}
+	if _, err := f.WriteString(password + "\n"); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", fmt.Errorf("cannot write password file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("cannot write password file: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// AddKey adds a repository key for newPassword, for another user or machine
+func (s *Service) AddKey(ctx context.Context, newPassword, user, host string) error {
+	file, err := writeSecret("", "restic-newpass-*", newPassword)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file)
+	defer s.restic.Cleanup()
+	return s.restic.AddKey(ctx, file, user, host)
+}
+
+// ChangePassword rotates the repository password and stores the new one
+// where the configuration reads it from, so later runs keep working:
+//
+//   - PASSWORD_FILE: the file is replaced once restic accepted the new password
+//   - RESTIC_PASSWORD written in config.ini: the line is updated
+//   - PASSWORD_COMMAND, or a password set by a reference or override: the
+//     tool cannot store it, so a second key is added instead and the old one
+//     stays until the secret behind the command has been updated
+//
+// It returns what was done and what is left to do.
+func (s *Service) ChangePassword(ctx context.Context, newPassword string) (string, error) {
+	if newPassword == "" {
+		return "", fmt.Errorf("the new password is empty")
+	}
+	defer s.restic.Cleanup()
+	lb := &s.config.LocalBackup
+
+	// Same order as SetupEnv, which decides the password restic is given
+	switch {
+	case lb.PasswordFile != "":
+		// Written next to the password file so the final rename cannot cross file systems
+		next, err := writeSecret(filepath.Dir(lb.PasswordFile), ".restic-password-*", newPassword)
+		if err != nil {
+			return "", err
+		}
+		err = s.restic.ChangePassword(ctx, next)
+		if err != nil || s.dryRun {
+			os.Remove(next)
+		}
+		if err != nil {
+			return "", err
+		}
+		if s.dryRun {
+			return "Dry run, the password was not changed", nil
+		}
+		// Keep the file on failure, it holds the only copy of the new password
+		if err := os.Rename(next, lb.PasswordFile); err != nil {
+			return "", fmt.Errorf("the repository password was changed, but %s could not be replaced with it (%w); "+
+				"the new password is in %s", lb.PasswordFile, err, next)
+		}
+		return fmt.Sprintf("Password changed and written to %s", lb.PasswordFile), nil
+
+	case lb.PasswordCommand != "":
+		// Handled below
+
+	case lb.Password != "":
+		field := passwordField()
+		if s.config.Reference(field) == "" && s.config.Overridden(field) == "" {
+			return s.changeInlinePassword(ctx, field, newPassword)
+		}
+
+	default:
+		return "", fmt.Errorf("no restic password method configured")
+	}
+
+	// The password comes from somewhere this tool cannot write to
+	if err := s.AddKey(ctx, newPassword, "", ""); err != nil {
+		return "", err
+	}
+	if s.dryRun {
+		return "Dry run, no key was added", nil
+	}
+	return "Added a key for the new password, the current key is kept. Store the new password where " +
+		"the configuration reads it from, then remove the old key with 'repo key remove ID'", nil
+}
+
+// changeInlinePassword changes the password and updates RESTIC_PASSWORD in config.ini
+func (s *Service) changeInlinePassword(ctx context.Context, field config.Field, newPassword string) (string, error) {
+	file, err := writeSecret("", "restic-newpass-*", newPassword)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file)
+	if err := s.restic.ChangePassword(ctx, file); err != nil {
+		return "", err
+	}
+	if s.dryRun {
+		return "Dry run, the password was not changed", nil
+	}
+
+	s.config.LocalBackup.Password = newPassword
+	if err := config.SaveFields(s.config.ConfigFile, s.config, []config.Field{field}); err != nil {
+		return "", fmt.Errorf("the repository password was changed, but config.ini could not be updated: %w", err)
+	}
+	return fmt.Sprintf("Password changed and written to %s", s.config.ConfigFile), nil
+}
+
+// passwordField returns the RESTIC_PASSWORD setting
+func passwordField() config.Field {
+	for _, f := range config.Fields() {
+		if f.Key == "RESTIC_PASSWORD" {
+			return f
+		}
+	}
+	panic("RESTIC_PASSWORD is not a config field")
+}
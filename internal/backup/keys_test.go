@@ -0,0 +1,82 @@
+package backup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"backup-tui/internal/config"
+)
+
+// fakeRestic puts a restic on PATH that records its arguments and the new
+// password it was given, and returns the file the arguments are written to
+func fakeRestic(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	log := filepath.Join(dir, "calls")
+	script := `#!/bin/sh
+echo "$@" >> "` + log + `"
+if [ "$3" = "--new-password-file" ]; then cat "$4" >> "` + log + `"; fi
+`
+	if err := os.WriteFile(filepath.Join(dir, "restic"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return log
+}
+
+func TestChangePasswordFile(t *testing.T) {
+	log := fakeRestic(t)
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "restic.password")
+	if err := os.WriteFile(passwordFile, []byte("old\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.LocalBackup.Repository = dir
+	cfg.LocalBackup.PasswordFile = passwordFile
+	msg, err := NewService(cfg, false, false).ChangePassword(context.Background(), "new-secret")
+	if err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+	if !strings.Contains(msg, passwordFile) {
+		t.Errorf("Unexpected message %q", msg)
+	}
+
+	data, _ := os.ReadFile(passwordFile)
+	if string(data) != "new-secret\n" {
+		t.Errorf("Password file holds %q", data)
+	}
+	calls, _ := os.ReadFile(log)
+	if !strings.HasPrefix(string(calls), "key passwd --new-password-file "+filepath.Join(dir, ".restic-password-")) ||
+		!strings.HasSuffix(string(calls), "\nnew-secret\n") {
+		t.Errorf("Unexpected restic calls:\n%s", calls)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected only the password file to be left, got %d entries", len(entries))
+	}
+}
+
+func TestChangePasswordCommand(t *testing.T) {
+	log := fakeRestic(t)
+
+	cfg := config.DefaultConfig()
+	cfg.LocalBackup.Repository = t.TempDir()
+	cfg.LocalBackup.PasswordCommand = "echo old"
+	msg, err := NewService(cfg, false, false).ChangePassword(context.Background(), "new-secret")
+	if err != nil {
+		t.Fatalf("ChangePassword: %v", err)
+	}
+	if !strings.Contains(msg, "remove the old key") {
+		t.Errorf("Unexpected message %q", msg)
+	}
+
+	// The command cannot be updated, so the old key must keep working
+	calls, _ := os.ReadFile(log)
+	if !strings.HasPrefix(string(calls), "key add --new-password-file ") || strings.Contains(string(calls), "passwd") {
+		t.Errorf("Unexpected restic calls:\n%s", calls)
+	}
+}
@@ -0,0 +1,187 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"backup-tui/internal/util"
+)
+
+const (
+	// mountReadyTimeout is how long to wait for restic mount to expose the repository
+	mountReadyTimeout = 30 * time.Second
+	// unmountTimeout is how long to wait for restic mount to exit after unmounting
+	unmountTimeout = 10 * time.Second
+)
+
+// MountSession is a repository mounted in the background with restic mount
+type MountSession struct {
+	Mountpoint string // Temporary directory the repository is mounted on
+	Path       string // Directory to browse (the snapshot directory when one was requested)
+
+	cmd    *exec.Cmd
+	stderr *bytes.Buffer
+	done   chan struct{}
+	err    error // Exit error of restic mount, valid once done is closed
+	once   sync.Once
+}
+
+// Mount mounts the repository on a temporary mountpoint and waits until it is ready.
+// If snapshotID is set, Path points at that snapshot. The mount is released by
+// Unmount or by the manager's Cleanup. Cancelling ctx only aborts the wait for the mount.
+func (r *ResticManager) Mount(ctx context.Context, snapshotID string) (*MountSession, error) {
+	if !util.CommandExists("fusermount") && !util.CommandExists("fusermount3") && !util.CommandExists("umount") {
+		return nil, fmt.Errorf("FUSE is not available (fusermount not found)")
+	}
+
+	env, err := r.environ()
+	if err != nil {
+		return nil, err
+	}
+
+	mountpoint, err := os.MkdirTemp("", "restic-mount-*")
+	if err != nil {
+		return nil, fmt.Errorf("cannot create mountpoint: %w", err)
+	}
+
+	m := &MountSession{
+		Mountpoint: mountpoint,
+		Path:       mountpoint,
+		stderr:     &bytes.Buffer{},
+		done:       make(chan struct{}),
+	}
+	if snapshotID != "" {
+		m.Path = filepath.Join(mountpoint, "ids", snapshotID)
+	}
+
+	m.cmd = exec.Command("restic", "mount", mountpoint)
+	m.cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	m.cmd.Env = env
+	m.cmd.Stderr = m.stderr
+
+	if err := m.cmd.Start(); err != nil {
+		os.Remove(mountpoint)
+		return nil, fmt.Errorf("cannot start restic mount: %w", err)
+	}
+	go func() {
+		m.err = m.cmd.Wait()
+		close(m.done)
+	}()
+
+	if err := m.waitReady(ctx); err != nil {
+		_ = m.Unmount()
+		return nil, err
+	}
+
+	r.cleanupFuncs = append(r.cleanupFuncs, func() {
+		if err := m.Unmount(); err != nil {
+			util.LogWarn("Unmount failed: %v", err)
+		}
+	})
+
+	util.LogInfo("Repository mounted at %s", mountpoint)
+	return m, nil
+}
+
+// waitReady polls the mountpoint until restic has populated it
+func (m *MountSession) waitReady(ctx context.Context) error {
+	deadline := time.Now().Add(mountReadyTimeout)
+	for time.Now().Before(deadline) {
+		select {
+		case <-m.done:
+			msg := strings.TrimSpace(m.stderr.String())
+			if msg == "" && m.err != nil {
+				msg = m.err.Error()
+			}
+			return fmt.Errorf("restic mount exited: %s", msg)
+		default:
+		}
+
+		if _, err := os.Stat(filepath.Join(m.Mountpoint, "snapshots")); err == nil {
+			if _, err := os.Stat(m.Path); err != nil {
+				return fmt.Errorf("snapshot not found in mount: %s", filepath.Base(m.Path))
+			}
+			return nil
+		}
+		if err := util.SleepContext(ctx, 200*time.Millisecond); err != nil {
+			return fmt.Errorf("mount cancelled: %w", err)
+		}
+	}
+	return fmt.Errorf("repository was not mounted within %v", mountReadyTimeout)
+}
+
+// Done is closed when the restic mount process exits
+func (m *MountSession) Done() <-chan struct{} {
+	return m.done
+}
+
+// Active reports whether the restic mount process is still running
+func (m *MountSession) Active() bool {
+	select {
+	case <-m.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Unmount releases the FUSE mount, stops restic and removes the mountpoint.
+// It is safe to call more than once.
+func (m *MountSession) Unmount() error {
+	var err error
+	m.once.Do(func() {
+		err = m.unmount()
+	})
+	return err
+}
+
+func (m *MountSession) unmount() error {
+	if m.Active() {
+		// restic unmounts cleanly on SIGINT; fusermount covers a wedged process
+		_ = m.cmd.Process.Signal(syscall.SIGINT)
+
+		select {
+		case <-m.done:
+		case <-time.After(unmountTimeout):
+			releaseMount(m.Mountpoint)
+			if pgid, err := syscall.Getpgid(m.cmd.Process.Pid); err == nil {
+				_ = syscall.Kill(-pgid, syscall.SIGKILL)
+			} else {
+				_ = m.cmd.Process.Kill()
+			}
+			<-m.done
+		}
+	}
+
+	if err := os.Remove(m.Mountpoint); err != nil && !os.IsNotExist(err) {
+		// Still busy: force the FUSE mount off and try once more
+		releaseMount(m.Mountpoint)
+		if err := os.Remove(m.Mountpoint); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("cannot remove mountpoint %s: %w", m.Mountpoint, err)
+		}
+	}
+
+	util.LogInfo("Repository unmounted from %s", m.Mountpoint)
+	return nil
+}
+
+// releaseMount detaches a FUSE mountpoint with whichever tool is available
+func releaseMount(mountpoint string) {
+	for _, tool := range [][]string{{"fusermount", "-u", "-z"}, {"fusermount3", "-u", "-z"}, {"umount", "-l"}} {
+		if !util.CommandExists(tool[0]) {
+			continue
+		}
+		result, err := util.RunWithTimeout(10*time.Second, tool[0], append(tool[1:], mountpoint)...)
+		if err == nil && result.IsSuccess() {
+			return
+		}
+	}
+}
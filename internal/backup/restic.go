@@ -1,11 +1,13 @@
 package backup
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"backup-tui/internal/config"
@@ -18,6 +20,8 @@ type ResticManager struct {
 	dryRun       bool
 	outputWriter io.Writer
 	cleanupFuncs []func()
+	progress     func(BackupProgress)
+	env          map[string]string // Set by SetupEnv, cleared by Cleanup
 }
 
 // Snapshot represents a restic snapshot
@@ -28,6 +32,53 @@ type Snapshot struct {
 	Hostname string   `json:"hostname"`
 	Tags     []string `json:"tags"`
 	Paths    []string `json:"paths"`
+
+	// Summary of the backup run, only written by restic 0.17 and later
+	Summary *SnapshotSummary `json:"summary,omitempty"`
+}
+
+// SnapshotSummary holds the statistics restic stores with a snapshot
+type SnapshotSummary struct {
+	TotalFilesProcessed int64 `json:"total_files_processed"`
+	TotalBytesProcessed int64 `json:"total_bytes_processed"`
+	DataAdded           int64 `json:"data_added"`
+}
+
+// Size returns the size of the backed up data, or 0 if restic did not record it
+func (s Snapshot) Size() int64 {
+	if s.Summary == nil {
+		return 0
+	}
+	return s.Summary.TotalBytesProcessed
+}
+
+// StackTag returns the tag naming the stack a snapshot belongs to: the first tag
+// that is neither one of the fixed backup tags nor the date tag
+func (s Snapshot) StackTag() string {
+	for _, t := range s.Tags {
+		if t == "docker-backup" || t == "selective-backup" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", t); err == nil {
+			continue
+		}
+		return t
+	}
+	return ""
+}
+
+// SnapshotNode is a file or directory entry inside a snapshot (from restic ls --json)
+type SnapshotNode struct {
+	Name  string    `json:"name"`
+	Type  string    `json:"type"` // file, dir, symlink, ...
+	Path  string    `json:"path"`
+	Size  int64     `json:"size"`
+	Mtime time.Time `json:"mtime"`
+}
+
+// IsDir returns true if the node is a directory
+func (n SnapshotNode) IsDir() bool {
+	return n.Type == "dir"
 }
 
 // NewResticManager creates a new restic manager
@@ -39,60 +90,111 @@ func NewResticManager(cfg *config.LocalBackupConfig, dryRun bool, outputWriter i
 	}
 }
 
-// SetupEnv configures environment variables for restic
+// SetProgressFunc enables structured backup progress.
+// When set, restic backup runs with --json and status updates are passed to fn.
+func (r *ResticManager) SetProgressFunc(fn func(BackupProgress)) {
+	r.progress = fn
+}
+
+// output returns the writer for human readable output
+func (r *ResticManager) output() io.Writer {
+	if r.outputWriter != nil {
+		return r.outputWriter
+	}
+	return os.Stdout
+}
+
+// SetupEnv prepares the repository and password variables given to each restic
+// command this manager runs. The process environment is not touched, so managers
+// for different repositories, or one being cleaned up, do not affect each other.
 func (r *ResticManager) SetupEnv() error {
-	os.Setenv("RESTIC_REPOSITORY", r.config.Repository)
+	if r.env != nil {
+		return nil
+	}
+	env := map[string]string{"RESTIC_REPOSITORY": r.config.Repository}
 
 	switch {
 	case r.config.PasswordFile != "":
 		if _, err := os.Stat(r.config.PasswordFile); err != nil {
 			return fmt.Errorf("password file not found: %s", r.config.PasswordFile)
 		}
-		os.Setenv("RESTIC_PASSWORD_FILE", r.config.PasswordFile)
+		env["RESTIC_PASSWORD_FILE"] = r.config.PasswordFile
 	case r.config.PasswordCommand != "":
-		os.Setenv("RESTIC_PASSWORD_COMMAND", r.config.PasswordCommand)
+		env["RESTIC_PASSWORD_COMMAND"] = r.config.PasswordCommand
 	case r.config.Password != "":
 		// Create temp password file (more secure than env var)
-		if err := r.createTempPasswordFile(); err != nil {
+		path, err := r.createTempPasswordFile()
+		if err != nil {
 			return err
 		}
+		env["RESTIC_PASSWORD_FILE"] = path
 	}
 
+	r.env = env
 	return nil
 }
 
-// createTempPasswordFile creates a temporary file with the password
-func (r *ResticManager) createTempPasswordFile() error {
+// createTempPasswordFile writes the password to a temporary file removed by Cleanup
+func (r *ResticManager) createTempPasswordFile() (string, error) {
 	tmpFile, err := os.CreateTemp("", "restic-pass-*")
 	if err != nil {
-		return fmt.Errorf("cannot create temp password file: %w", err)
+		return "", fmt.Errorf("cannot create temp password file: %w", err)
 	}
+	// Schedule cleanup
+	r.cleanupFuncs = append(r.cleanupFuncs, func() {
+		os.Remove(tmpFile.Name())
+	})
 	if _, err := tmpFile.WriteString(r.config.Password); err != nil {
 		tmpFile.Close()
-		return fmt.Errorf("cannot write to temp password file: %w", err)
+		return "", fmt.Errorf("cannot write to temp password file: %w", err)
 	}
 	tmpFile.Close()
 	if err := os.Chmod(tmpFile.Name(), 0o600); err != nil {
-		return fmt.Errorf("cannot set temp password file permissions: %w", err)
+		return "", fmt.Errorf("cannot set temp password file permissions: %w", err)
 	}
-	os.Setenv("RESTIC_PASSWORD_FILE", tmpFile.Name())
-
-	// Schedule cleanup
-	r.cleanupFuncs = append(r.cleanupFuncs, func() {
-		os.Remove(tmpFile.Name())
-	})
-	return nil
+	return tmpFile.Name(), nil
 }
 
-// Cleanup runs cleanup functions
+// Cleanup runs cleanup functions, most recently registered first
 func (r *ResticManager) Cleanup() {
-	for _, fn := range r.cleanupFuncs {
-		fn()
+	for i := len(r.cleanupFuncs) - 1; i >= 0; i-- {
+		r.cleanupFuncs[i]()
+	}
+	r.cleanupFuncs = nil
+	r.env = nil
+}
+
+// run runs restic with args, adding the repository and password variables to opts.Env
+func (r *ResticManager) run(ctx context.Context, args []string, opts util.CommandOptions) (*util.CommandResult, error) {
+	if err := r.SetupEnv(); err != nil {
+		return nil, err
+	}
+	env := make(map[string]string, len(r.env)+len(opts.Env))
+	for k, v := range r.env {
+		env[k] = v
+	}
+	for k, v := range opts.Env {
+		env[k] = v
+	}
+	opts.Env = env
+	return util.RunCommand(ctx, "restic", args, opts)
+}
+
+// environ returns the process environment with the repository and password variables added,
+// for restic commands started without run
+func (r *ResticManager) environ() ([]string, error) {
+	if err := r.SetupEnv(); err != nil {
+		return nil, err
+	}
+	env := os.Environ()
+	for k, v := range r.env {
+		env = append(env, k+"="+v)
 	}
+	return env, nil
 }
 
 // CheckRepository verifies access to the restic repository
-func (r *ResticManager) CheckRepository() error {
+func (r *ResticManager) CheckRepository(ctx context.Context) error {
 	if !util.CommandExists("restic") {
 		return fmt.Errorf("restic not found in PATH")
 	}
@@ -107,16 +209,55 @@ func (r *ResticManager) CheckRepository() error {
 		CaptureErr: true,
 	}
 
-	result, err := util.RunCommand("restic", []string{"snapshots", "--quiet"}, opts)
-	if err != nil || !result.IsSuccess() {
+	result, err := r.run(ctx, []string{"snapshots", "--quiet"}, opts)
+	if err != nil {
+		return fmt.Errorf("cannot access restic repository: %w", err)
+	}
+	if !result.IsSuccess() {
+		if strings.Contains(result.Stderr, "does not exist") || strings.Contains(result.Stderr, "Is there a repository") {
+			return fmt.Errorf("no restic repository at %s, create it with 'repo init'", r.config.Repository)
+		}
 		return fmt.Errorf("cannot access restic repository")
 	}
 
 	return nil
 }
 
+// Init creates a new repository at the configured location, protected by the configured password
+func (r *ResticManager) Init(ctx context.Context) error {
+	if !util.CommandExists("restic") {
+		return fmt.Errorf("restic not found in PATH")
+	}
+
+	if r.dryRun {
+		util.LogProgress("[DRY RUN] Would initialize repository: %s", r.config.Repository)
+		return nil
+	}
+
+	if err := r.SetupEnv(); err != nil {
+		return err
+	}
+
+	opts := util.CommandOptions{
+		Timeout:    5 * time.Minute,
+		CaptureOut: true,
+		CaptureErr: true,
+	}
+
+	result, err := r.run(ctx, []string{"init"}, opts)
+	if err != nil {
+		return fmt.Errorf("cannot initialize repository: %w", err)
+	}
+	if !result.IsSuccess() {
+		return fmt.Errorf("cannot initialize repository: %s", strings.TrimSpace(result.Stderr))
+	}
+
+	fmt.Fprint(r.output(), result.Stdout)
+	return nil
+}
+
 // Backup performs a backup of the specified directory
-func (r *ResticManager) Backup(dirPath, dirName, hostname string) error {
+func (r *ResticManager) Backup(ctx context.Context, dirPath, dirName, hostname string) error {
 	util.LogProgress("Backing up directory: %s", dirName)
 
 	if r.dryRun {
@@ -124,14 +265,18 @@ func (r *ResticManager) Backup(dirPath, dirName, hostname string) error {
 		return nil
 	}
 
-	args := []string{
-		"backup",
-		"--verbose",
+	args := []string{"backup"}
+	if r.progress != nil {
+		args = append(args, "--json")
+	} else {
+		args = append(args, "--verbose")
+	}
+	args = append(args,
 		"--tag", "docker-backup",
 		"--tag", "selective-backup",
 		"--tag", dirName,
 		"--tag", time.Now().Format("2006-01-02"),
-	}
+	)
 
 	if hostname != "" {
 		args = append(args, "--hostname", hostname)
@@ -146,8 +291,13 @@ func (r *ResticManager) Backup(dirPath, dirName, hostname string) error {
 		StreamErr:    true,
 		OutputWriter: r.outputWriter,
 	}
+	if r.progress != nil {
+		pw := newBackupProgressWriter(r.output(), r.progress)
+		defer pw.Flush()
+		opts.OutputWriter = pw
+	}
 
-	result, err := util.RunCommand("restic", args, opts)
+	result, err := r.run(ctx, args, opts)
 	if err != nil {
 		return fmt.Errorf("backup failed: %w", err)
 	}
@@ -160,7 +310,7 @@ func (r *ResticManager) Backup(dirPath, dirName, hostname string) error {
 }
 
 // Verify verifies a backup
-func (r *ResticManager) Verify(dirName string) error {
+func (r *ResticManager) Verify(ctx context.Context, dirName string) error {
 	if !r.config.EnableVerification {
 		return nil
 	}
@@ -173,7 +323,7 @@ func (r *ResticManager) Verify(dirName string) error {
 	}
 
 	// Get latest snapshot for this directory
-	snapshots, err := r.ListSnapshots(dirName, 1)
+	snapshots, err := r.ListSnapshots(ctx, dirName, 1)
 	if err != nil || len(snapshots) == 0 {
 		return fmt.Errorf("no snapshots found for verification")
 	}
@@ -183,9 +333,15 @@ func (r *ResticManager) Verify(dirName string) error {
 	// Verify based on depth
 	var args []string
 	switch r.config.VerificationDepth {
+	case "files":
+		if err := r.verifyFiles(ctx, snapshots[0]); err != nil {
+			return fmt.Errorf("verification failed: %w", err)
+		}
+		util.LogProgress("Backup verification passed: %s", dirName)
+		return nil
 	case "data":
 		args = []string{"check", "--read-data", snapshotID}
-	default: // metadata, files
+	default: // metadata
 		args = []string{"ls", snapshotID}
 	}
 
@@ -195,7 +351,7 @@ func (r *ResticManager) Verify(dirName string) error {
 		CaptureErr: true,
 	}
 
-	result, err := util.RunCommand("restic", args, opts)
+	result, err := r.run(ctx, args, opts)
 	if err != nil || !result.IsSuccess() {
 		return fmt.Errorf("verification failed")
 	}
@@ -204,8 +360,54 @@ func (r *ResticManager) Verify(dirName string) error {
 	return nil
 }
 
+// Check runs restic check against the repository, optionally reading all pack data
+func (r *ResticManager) Check(ctx context.Context, readData bool) error {
+	util.LogProgress("Checking repository integrity")
+
+	if readData {
+		return r.runCheck(ctx, []string{"check", "--read-data"}, readDataTimeout)
+	}
+	return r.runCheck(ctx, []string{"check"}, time.Duration(r.config.Timeout)*time.Second)
+}
+
+// CheckSubset runs restic check and reads slice subset of total of the pack
+// data, so total runs with subsets 1 to total read all of it once
+func (r *ResticManager) CheckSubset(ctx context.Context, subset, total int) error {
+	if total <= 1 {
+		util.LogProgress("Checking repository integrity, reading all data")
+		return r.runCheck(ctx, []string{"check", "--read-data"}, readDataTimeout)
+	}
+	util.LogProgress("Checking repository integrity, reading data slice %d/%d", subset, total)
+	return r.runCheck(ctx, []string{"check", fmt.Sprintf("--read-data-subset=%d/%d", subset, total)}, readDataTimeout)
+}
+
+// readDataTimeout bounds a check that reads pack data. It downloads and decrypts
+// the data it reads, which takes far longer than BACKUP_TIMEOUT allows for a backup.
+const readDataTimeout = 24 * time.Hour
+
+// runCheck runs restic with the check args, streaming its output
+func (r *ResticManager) runCheck(ctx context.Context, args []string, timeout time.Duration) error {
+	opts := util.CommandOptions{
+		Timeout:      timeout,
+		StreamOut:    true,
+		StreamErr:    true,
+		OutputWriter: r.outputWriter,
+	}
+
+	result, err := r.run(ctx, args, opts)
+	if err != nil {
+		return fmt.Errorf("check failed: %w", err)
+	}
+	if !result.IsSuccess() {
+		return fmt.Errorf("check failed with exit code %d", result.ExitCode)
+	}
+
+	util.LogSuccess("Repository check passed")
+	return nil
+}
+
 // ApplyRetention applies the retention policy
-func (r *ResticManager) ApplyRetention(dirName, hostname string) error {
+func (r *ResticManager) ApplyRetention(ctx context.Context, dirName, hostname string) error {
 	if !r.config.AutoPrune {
 		return nil
 	}
@@ -255,7 +457,7 @@ func (r *ResticManager) ApplyRetention(dirName, hostname string) error {
 		OutputWriter: r.outputWriter,
 	}
 
-	result, err := util.RunCommand("restic", args, opts)
+	result, err := r.run(ctx, args, opts)
 	if err != nil || !result.IsSuccess() {
 		return fmt.Errorf("retention policy failed")
 	}
@@ -265,7 +467,7 @@ func (r *ResticManager) ApplyRetention(dirName, hostname string) error {
 }
 
 // ListSnapshots lists snapshots, optionally filtered by tag
-func (r *ResticManager) ListSnapshots(tag string, limit int) ([]Snapshot, error) {
+func (r *ResticManager) ListSnapshots(ctx context.Context, tag string, limit int) ([]Snapshot, error) {
 	args := []string{"snapshots", "--json"}
 	if tag != "" {
 		args = append(args, "--tag", tag)
@@ -279,7 +481,7 @@ func (r *ResticManager) ListSnapshots(tag string, limit int) ([]Snapshot, error)
 		CaptureOut: true,
 	}
 
-	result, err := util.RunCommand("restic", args, opts)
+	result, err := r.run(ctx, args, opts)
 	if err != nil {
 		return nil, fmt.Errorf("cannot list snapshots: %w", err)
 	}
@@ -292,50 +494,161 @@ func (r *ResticManager) ListSnapshots(tag string, limit int) ([]Snapshot, error)
 	return snapshots, nil
 }
 
-// GetRepositoryStats returns repository statistics
-func (r *ResticManager) GetRepositoryStats() (map[string]interface{}, error) {
+// RestorePreview shows what would be restored
+func (r *ResticManager) RestorePreview(ctx context.Context, dirName string) (string, error) {
+	snapshots, err := r.ListSnapshots(ctx, dirName, 1)
+	if err != nil || len(snapshots) == 0 {
+		return "", fmt.Errorf("no snapshots found for: %s", dirName)
+	}
+
+	snapshotID := snapshots[0].ShortID
+
 	opts := util.CommandOptions{
 		Timeout:    60 * time.Second,
 		CaptureOut: true,
 	}
 
-	result, err := util.RunCommand("restic", []string{"stats", "--json"}, opts)
+	result, err := r.run(ctx, []string{"ls", snapshotID}, opts)
+	if err != nil {
+		return "", fmt.Errorf("cannot list snapshot contents: %w", err)
+	}
+
+	return result.Stdout, nil
+}
+
+// ListFiles returns all file and directory nodes in a snapshot
+func (r *ResticManager) ListFiles(ctx context.Context, snapshotID string) ([]SnapshotNode, error) {
+	opts := util.CommandOptions{
+		Timeout:    5 * time.Minute,
+		CaptureOut: true,
+		CaptureErr: true,
+	}
+
+	result, err := r.run(ctx, []string{"ls", "--json", snapshotID}, opts)
 	if err != nil {
-		return nil, fmt.Errorf("cannot get stats: %w", err)
+		return nil, fmt.Errorf("cannot list snapshot contents: %w", err)
+	}
+	if !result.IsSuccess() {
+		return nil, fmt.Errorf("cannot list snapshot contents: %s", result.Stderr)
+	}
+
+	// Output is one JSON object per line: the snapshot itself followed by its nodes
+	var nodes []SnapshotNode
+	for _, line := range strings.Split(result.Stdout, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		var node SnapshotNode
+		if err := json.Unmarshal([]byte(line), &node); err != nil {
+			return nil, fmt.Errorf("cannot parse snapshot contents: %w", err)
+		}
+		if node.Type == "" || node.Path == "" {
+			continue // Snapshot header line
+		}
+		nodes = append(nodes, node)
 	}
 
-	var stats map[string]interface{}
-	if err := json.Unmarshal([]byte(result.Stdout), &stats); err != nil {
-		return nil, fmt.Errorf("cannot parse stats: %w", err)
+	return nodes, nil
+}
+
+// LocalRepository returns the directory of a repository on a local path,
+// or an empty string for other restic backends
+func LocalRepository(repo string) string {
+	if path, ok := strings.CutPrefix(repo, "local:"); ok {
+		return path
+	}
+	if i := strings.Index(repo, ":"); i > 0 && !strings.ContainsAny(repo[:i], `/\`) {
+		return ""
 	}
+	return repo
+}
 
-	return stats, nil
+// escapePattern escapes the characters restic treats as wildcards in --include, so a path matches only itself
+func escapePattern(path string) string {
+	var b strings.Builder
+	for _, c := range path {
+		if strings.ContainsRune(`*?[\`, c) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
 }
 
-// RestorePreview shows what would be restored
-func (r *ResticManager) RestorePreview(dirName string) (string, error) {
-	snapshots, err := r.ListSnapshots(dirName, 1)
-	if err != nil || len(snapshots) == 0 {
-		return "", fmt.Errorf("no snapshots found for: %s", dirName)
+// writeIncludeFile writes one escaped pattern per path to a temporary file for
+// --include-file, which has no limit on the number of paths. restic trims the
+// lines and expands $VARIABLES, so dollars are doubled and trailing blanks
+// become character classes. The caller removes the file.
+func writeIncludeFile(paths []string) (string, error) {
+	var b strings.Builder
+	for _, p := range paths {
+		line := strings.ReplaceAll(escapePattern(p), "$", "$$")
+		trimmed := strings.TrimRight(line, " \t")
+		b.WriteString(trimmed)
+		for _, c := range line[len(trimmed):] {
+			b.WriteString("[" + string(c) + "]")
+		}
+		b.WriteByte('\n')
 	}
 
-	snapshotID := snapshots[0].ShortID
+	f, err := os.CreateTemp("", "restic-include-*")
+	if err != nil {
+		return "", fmt.Errorf("cannot create include file: %w", err)
+	}
+	_, err = f.WriteString(b.String())
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("cannot write include file: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// RestorePaths restores selected paths from a snapshot into targetDir.
+// Paths are absolute paths inside the snapshot; directories are restored recursively.
+func (r *ResticManager) RestorePaths(ctx context.Context, snapshotID, targetDir string, paths []string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("no paths specified")
+	}
+
+	util.LogProgress("Restoring %d path(s) from snapshot %s to %s", len(paths), snapshotID, targetDir)
+
+	if r.dryRun {
+		for _, p := range paths {
+			util.LogProgress("[DRY RUN] Would restore: %s", p)
+		}
+		return nil
+	}
+
+	args := []string{"restore", snapshotID, "--target", targetDir, "--verbose"}
+	for _, p := range paths {
+		args = append(args, "--include", escapePattern(p))
+	}
 
 	opts := util.CommandOptions{
-		Timeout:    60 * time.Second,
-		CaptureOut: true,
+		Timeout:      time.Duration(r.config.Timeout) * time.Second,
+		StreamOut:    true,
+		StreamErr:    true,
+		OutputWriter: r.outputWriter,
 	}
 
-	result, err := util.RunCommand("restic", []string{"ls", snapshotID}, opts)
+	result, err := r.run(ctx, args, opts)
 	if err != nil {
-		return "", fmt.Errorf("cannot list snapshot contents: %w", err)
+		return fmt.Errorf("restore failed: %w", err)
+	}
+	if !result.IsSuccess() {
+		return fmt.Errorf("restore failed with exit code %d", result.ExitCode)
 	}
 
-	return result.Stdout, nil
+	util.LogSuccess("Restored %d path(s) to %s", len(paths), targetDir)
+	return nil
 }
 
 // ForgetSnapshots deletes specific snapshots by ID
-func (r *ResticManager) ForgetSnapshots(snapshotIDs []string, dryRun bool) error {
+func (r *ResticManager) ForgetSnapshots(ctx context.Context, snapshotIDs []string, dryRun bool) error {
 	if len(snapshotIDs) == 0 {
 		return fmt.Errorf("no snapshots specified")
 	}
@@ -355,7 +668,7 @@ func (r *ResticManager) ForgetSnapshots(snapshotIDs []string, dryRun bool) error
 		OutputWriter: r.outputWriter,
 	}
 
-	result, err := util.RunCommand("restic", args, opts)
+	result, err := r.run(ctx, args, opts)
 	if err != nil {
 		return fmt.Errorf("forget failed: %w", err)
 	}
@@ -368,7 +681,7 @@ func (r *ResticManager) ForgetSnapshots(snapshotIDs []string, dryRun bool) error
 }
 
 // Prune removes unreferenced data from repository
-func (r *ResticManager) Prune(dryRun bool) error {
+func (r *ResticManager) Prune(ctx context.Context, dryRun bool) error {
 	util.LogProgress("Pruning repository")
 
 	args := []string{"prune", "--verbose"}
@@ -383,7 +696,7 @@ func (r *ResticManager) Prune(dryRun bool) error {
 		OutputWriter: r.outputWriter,
 	}
 
-	result, err := util.RunCommand("restic", args, opts)
+	result, err := r.run(ctx, args, opts)
 	if err != nil {
 		return fmt.Errorf("prune failed: %w", err)
 	}
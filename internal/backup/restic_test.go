@@ -0,0 +1,102 @@
+package backup
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"backup-tui/internal/config"
+)
+
+func TestSnapshotStackTag(t *testing.T) {
+	tests := []struct {
+		tags []string
+		want string
+	}{
+		{[]string{"docker-backup", "selective-backup", "nginx", "2026-10-18"}, "nginx"},
+		{[]string{"2026-10-18", "docker-backup", "a-rather-long-stack-name-external"}, "a-rather-long-stack-name-external"},
+		{[]string{"docker-backup", "selective-backup"}, ""},
+		{nil, ""},
+	}
+
+	for _, tt := range tests {
+		if got := (Snapshot{Tags: tt.tags}).StackTag(); got != tt.want {
+			t.Errorf("StackTag(%v) = %q, want %q", tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestSnapshotSize(t *testing.T) {
+	var snapshots []Snapshot
+	data := `[
+		{"short_id": "aaaa1111", "tags": ["nginx"], "summary": {"total_files_processed": 40, "total_bytes_processed": 2048, "data_added": 512}},
+		{"short_id": "bbbb2222", "tags": ["nginx"]}
+	]`
+	if err := json.Unmarshal([]byte(data), &snapshots); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+
+	if got := snapshots[0].Size(); got != 2048 {
+		t.Errorf("Expected size 2048, got %d", got)
+	}
+	if got := snapshots[1].Size(); got != 0 {
+		t.Errorf("Expected size 0 without summary, got %d", got)
+	}
+}
+
+func TestSetupEnvIsPerManager(t *testing.T) {
+	t.Setenv("RESTIC_REPOSITORY", "/srv/live")
+	t.Setenv("RESTIC_PASSWORD_FILE", "")
+
+	live := NewResticManager(&config.LocalBackupConfig{Repository: "/srv/live", Password: "live"}, false, nil)
+	restored := NewResticManager(&config.LocalBackupConfig{Repository: "/tmp/restored", Password: "restored"}, false, nil)
+	if err := live.SetupEnv(); err != nil {
+		t.Fatal(err)
+	}
+	if err := restored.SetupEnv(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := os.Getenv("RESTIC_REPOSITORY"); got != "/srv/live" {
+		t.Errorf("Process RESTIC_REPOSITORY changed to %s", got)
+	}
+	if got := os.Getenv("RESTIC_PASSWORD_FILE"); got != "" {
+		t.Errorf("Process RESTIC_PASSWORD_FILE set to %s", got)
+	}
+	if live.env["RESTIC_REPOSITORY"] != "/srv/live" || restored.env["RESTIC_REPOSITORY"] != "/tmp/restored" {
+		t.Errorf("Unexpected repositories %v and %v", live.env, restored.env)
+	}
+
+	// Cleaning up one manager leaves the password file of the other in place
+	restored.Cleanup()
+	data, err := os.ReadFile(live.env["RESTIC_PASSWORD_FILE"])
+	if err != nil || string(data) != "live" {
+		t.Errorf("Password file of the other manager: %q, %v", data, err)
+	}
+	file := live.env["RESTIC_PASSWORD_FILE"]
+	live.Cleanup()
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Errorf("Password file %s left behind", file)
+	}
+}
+
+func TestEscapePattern(t *testing.T) {
+	if got := escapePattern(`/data/a*b?[c]\d`); got != `/data/a\*b\?\[c]\\d` {
+		t.Errorf("escapePattern = %q", got)
+	}
+}
+
+func TestWriteIncludeFile(t *testing.T) {
+	name, err := writeIncludeFile([]string{"/data/a*b", "/data/$HOME/x", "/data/trailing "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(name)
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "/data/a\\*b\n/data/$$HOME/x\n/data/trailing[ ]\n"; string(data) != want {
+		t.Errorf("Include file holds %q, want %q", data, want)
+	}
+}
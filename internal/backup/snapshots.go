@@ -0,0 +1,215 @@
+package backup
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"backup-tui/internal/util"
+)
+
+// Output formats of WriteSnapshots
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatCSV   = "csv"
+)
+
+// SnapshotFilter selects snapshots; zero fields match everything
+type SnapshotFilter struct {
+	Stack  string    // Dirlist name, external path or snapshot tag
+	Host   string    // Hostname recorded in the snapshot
+	Since  time.Time // Taken at or after
+	Until  time.Time // Taken before
+	Latest int       // Keep only the newest N per stack
+}
+
+// Snapshots returns the snapshots matching f, oldest first
+func (s *Service) Snapshots(ctx context.Context, f SnapshotFilter) ([]Snapshot, error) {
+	tag := ""
+	if f.Stack != "" {
+		tag = s.stackTag(f.Stack)
+	}
+
+	if err := s.restic.CheckRepository(ctx); err != nil {
+		return nil, fmt.Errorf("cannot access repository: %w", err)
+	}
+	defer s.restic.Cleanup()
+
+	snapshots, err := s.restic.ListSnapshots(ctx, tag, 0)
+	if err != nil {
+		return nil, err
+	}
+	return f.apply(snapshots), nil
+}
+
+// stackTag returns the snapshot tag of a dirlist name, external path or tag.
+// Stacks gone from the dirlist are still found by their tag.
+func (s *Service) stackTag(name string) string {
+	tag := name
+	if filepath.IsAbs(name) {
+		tag = filepath.Base(filepath.Clean(name)) + "-external"
+	}
+	if err := s.dirlist.Load(); err == nil {
+		if id, err := s.dirlist.Resolve(name); err == nil {
+			tag = s.dirlist.SnapshotTag(id)
+		}
+	}
+	return tag
+}
+
+// apply filters snapshots by host and time, sorts them oldest first and keeps
+// the newest Latest of each stack
+func (f SnapshotFilter) apply(snapshots []Snapshot) []Snapshot {
+	var matched []Snapshot
+	for _, snap := range snapshots {
+		t := snapshotTime(snap)
+		switch {
+		case f.Host != "" && snap.Hostname != f.Host:
+		case !f.Since.IsZero() && t.Before(f.Since):
+		case !f.Until.IsZero() && !t.Before(f.Until):
+		default:
+			matched = append(matched, snap)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return snapshotTime(matched[i]).Before(snapshotTime(matched[j]))
+	})
+	if f.Latest <= 0 {
+		return matched
+	}
+
+	// Walk newest first, counting per stack, then restore the order
+	count := make(map[string]int)
+	var kept []Snapshot
+	for i := len(matched) - 1; i >= 0; i-- {
+		tag := matched[i].StackTag()
+		if count[tag] < f.Latest {
+			count[tag]++
+			kept = append(kept, matched[i])
+		}
+	}
+	for i, j := 0, len(kept)-1; i < j; i, j = i+1, j-1 {
+		kept[i], kept[j] = kept[j], kept[i]
+	}
+	return kept
+}
+
+// ParseSnapshotTime parses a --since or --until value: a date, a date and time
+// in local time, RFC 3339, or an age such as 36h, 7d or 2w counted back from now.
+// A bare date given as the end of a range includes that whole day.
+func ParseSnapshotTime(value string, now time.Time, end bool) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		if end {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+
+	// time.ParseDuration has no unit longer than an hour
+	if last := len(value) - 1; last > 0 && (value[last] == 'd' || value[last] == 'w') {
+		if days, err := strconv.Atoi(value[:last]); err == nil && days >= 0 {
+			if value[last] == 'w' {
+				days *= 7
+			}
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, expected YYYY-MM-DD, YYYY-MM-DD HH:MM, RFC 3339 or an age like 7d", value)
+}
+
+// snapshotRecord is a snapshot as written by WriteSnapshots in JSON
+type snapshotRecord struct {
+	ID       string    `json:"id"`
+	ShortID  string    `json:"short_id"`
+	Time     time.Time `json:"time"`
+	Hostname string    `json:"hostname"`
+	Stack    string    `json:"stack"`
+	External bool      `json:"external"` // Tagged as an external path rather than a discovered stack
+	Size     int64     `json:"size,omitempty"`
+	Paths    []string  `json:"paths"`
+	Tags     []string  `json:"tags"`
+}
+
+func newSnapshotRecord(snap Snapshot) snapshotRecord {
+	tag := snap.StackTag()
+	return snapshotRecord{
+		ID:       snap.ID,
+		ShortID:  snap.ShortID,
+		Time:     snapshotTime(snap),
+		Hostname: snap.Hostname,
+		Stack:    tag,
+		External: strings.HasSuffix(tag, "-external"),
+		Size:     snap.Size(),
+		Paths:    snap.Paths,
+		Tags:     snap.Tags,
+	}
+}
+
+// WriteSnapshots writes snapshots to w as an aligned table, a JSON array or CSV with a header
+func WriteSnapshots(w io.Writer, snapshots []Snapshot, format string) error {
+	records := make([]snapshotRecord, len(snapshots))
+	for i, snap := range snapshots {
+		records[i] = newSnapshotRecord(snap)
+	}
+
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"id", "short_id", "time", "hostname", "stack", "external", "size", "paths", "tags"})
+		for _, r := range records {
+			cw.Write([]string{
+				r.ID, r.ShortID, r.Time.Format(time.RFC3339), r.Hostname, r.Stack,
+				strconv.FormatBool(r.External), strconv.FormatInt(r.Size, 10),
+				strings.Join(r.Paths, ";"), strings.Join(r.Tags, ";"),
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+
+	case FormatTable, "":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tTIME\tHOST\tSTACK\tSIZE\tPATH")
+		for _, r := range records {
+			size := "-"
+			if r.Size > 0 {
+				size = util.FormatSize(r.Size)
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", r.ShortID, r.Time.Local().Format("2006-01-02 15:04:05"),
+				r.Hostname, r.Stack, size, strings.Join(r.Paths, ", "))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%d snapshot(s)\n", len(records))
+		return nil
+
+	default:
+		return fmt.Errorf("unknown format %q, expected table, json or csv", format)
+	}
+}
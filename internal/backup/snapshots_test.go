@@ -0,0 +1,163 @@
+package backup
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"backup-tui/internal/config"
+)
+
+func testSnapshots() []Snapshot {
+	return []Snapshot{
+		{ID: "c", ShortID: "c", Time: "2026-10-17T02:00:00Z", Hostname: "nas", Tags: []string{"docker-backup", "web", "2026-10-17"}},
+		{ID: "a", ShortID: "a", Time: "2026-10-15T02:00:00Z", Hostname: "nas", Tags: []string{"docker-backup", "web", "2026-10-15"}},
+		{ID: "b", ShortID: "b", Time: "2026-10-16T02:00:00Z", Hostname: "old", Tags: []string{"docker-backup", "a-long-external-stack-name-external", "2026-10-16"}},
+		{ID: "d", ShortID: "d", Time: "2026-10-16T03:00:00Z", Hostname: "nas", Tags: []string{"docker-backup", "db", "2026-10-16"}},
+	}
+}
+
+func snapshotIDs(snapshots []Snapshot) string {
+	ids := make([]string, len(snapshots))
+	for i, s := range snapshots {
+		ids[i] = s.ID
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestSnapshotFilter(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		name   string
+		filter SnapshotFilter
+		want   string
+	}{
+		{"all, oldest first", SnapshotFilter{}, "a,b,d,c"},
+		{"host", SnapshotFilter{Host: "nas"}, "a,d,c"},
+		{"since", SnapshotFilter{Since: day(16)}, "b,d,c"},
+		{"until is exclusive", SnapshotFilter{Until: day(17).Add(2 * time.Hour)}, "a,b,d"},
+		{"range", SnapshotFilter{Since: day(16), Until: day(17)}, "b,d"},
+		{"latest per stack", SnapshotFilter{Latest: 1}, "b,d,c"},
+		{"latest after filters", SnapshotFilter{Latest: 1, Until: day(17)}, "a,b,d"},
+	}
+	for _, tt := range tests {
+		if got := snapshotIDs(tt.filter.apply(testSnapshots())); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStackTag(t *testing.T) {
+	tmpDir := t.TempDir()
+	stacksDir := filepath.Join(tmpDir, "stacks")
+	externalDir := filepath.Join(tmpDir, "apps", "wiki")
+	for _, dir := range []string{filepath.Join(stacksDir, "web"), externalDir} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "docker-compose.yml"), []byte("services: {}\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := &config.Config{DirlistFile: filepath.Join(tmpDir, "dirlist"), LockDir: tmpDir}
+	cfg.Docker.StacksDir = stacksDir
+	svc := NewService(cfg, true, false)
+	if _, _, err := svc.dirlist.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.dirlist.AddExternal(externalDir); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.dirlist.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"web":             "web",
+		externalDir + "/": "wiki-external",
+		"wiki-external":   "wiki-external",
+		"gone":            "gone",
+		"/srv/old-stack":  "old-stack-external",
+	}
+	for name, want := range tests {
+		if got := svc.stackTag(name); got != want {
+			t.Errorf("stackTag(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestParseSnapshotTime(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		value string
+		end   bool
+		want  time.Time
+	}{
+		{"2026-10-01", false, time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)},
+		{"2026-10-01", true, time.Date(2026, 10, 2, 0, 0, 0, 0, time.Local)},
+		{"2026-10-01 08:30", true, time.Date(2026, 10, 1, 8, 30, 0, 0, time.Local)},
+		{"2026-10-01T08:30:00Z", false, time.Date(2026, 10, 1, 8, 30, 0, 0, time.UTC)},
+		{"7d", false, now.AddDate(0, 0, -7)},
+		{"2w", false, now.AddDate(0, 0, -14)},
+		{"36h", false, now.Add(-36 * time.Hour)},
+	}
+	for _, tt := range tests {
+		got, err := ParseSnapshotTime(tt.value, now, tt.end)
+		if err != nil {
+			t.Errorf("%s: %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("%s (end %v): got %v, want %v", tt.value, tt.end, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "d", "yesterday", "-3d", "2026-13-01"} {
+		if _, err := ParseSnapshotTime(bad, now, false); err == nil {
+			t.Errorf("%q was accepted", bad)
+		}
+	}
+}
+
+func TestWriteSnapshots(t *testing.T) {
+	snapshots := testSnapshots()[1:3]
+
+	var buf bytes.Buffer
+	if err := WriteSnapshots(&buf, snapshots, FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	var records []snapshotRecord
+	if err := json.Unmarshal(buf.Bytes(), &records); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(records) != 2 || records[0].Stack != "web" || records[0].External ||
+		records[1].Stack != "a-long-external-stack-name-external" || !records[1].External {
+		t.Errorf("Unexpected records %+v", records)
+	}
+
+	buf.Reset()
+	if err := WriteSnapshots(&buf, snapshots, FormatCSV); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Invalid CSV: %v", err)
+	}
+	if len(rows) != 3 || rows[0][4] != "stack" || rows[2][4] != "a-long-external-stack-name-external" || rows[2][5] != "true" {
+		t.Errorf("Unexpected rows %v", rows)
+	}
+
+	buf.Reset()
+	if err := WriteSnapshots(&buf, nil, FormatJSON); err != nil || strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("Empty list written as %q (%v)", buf.String(), err)
+	}
+	if err := WriteSnapshots(&buf, snapshots, "xml"); err == nil {
+		t.Error("Unknown format was accepted")
+	}
+}
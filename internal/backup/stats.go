@@ -0,0 +1,131 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"backup-tui/internal/stats"
+	"backup-tui/internal/util"
+)
+
+// statsTimeout bounds one restic stats run; raw-data walks every blob of the selected snapshots
+const statsTimeout = 30 * time.Minute
+
+// resticStats is the output of restic stats --json, in restore-size or raw-data mode
+type resticStats struct {
+	TotalSize             int64   `json:"total_size"`
+	TotalFileCount        int64   `json:"total_file_count"`
+	TotalUncompressedSize int64   `json:"total_uncompressed_size"` // raw-data only
+	CompressionRatio      float64 `json:"compression_ratio"`       // raw-data only
+	SnapshotsCount        int     `json:"snapshots_count"`
+}
+
+// runStats runs restic stats in mode on the snapshots selected by args
+func (r *ResticManager) runStats(ctx context.Context, mode string, args ...string) (resticStats, error) {
+	var st resticStats
+	opts := util.CommandOptions{
+		Timeout:    statsTimeout,
+		CaptureOut: true,
+		CaptureErr: true,
+	}
+	result, err := r.run(ctx, append([]string{"stats", "--json", "--mode", mode}, args...), opts)
+	if err != nil {
+		return st, fmt.Errorf("cannot get %s statistics: %w", mode, err)
+	}
+	if !result.IsSuccess() {
+		return st, fmt.Errorf("cannot get %s statistics: %s", mode, lastLine(result.Stderr))
+	}
+	if err := json.Unmarshal([]byte(result.Stdout), &st); err != nil {
+		return st, fmt.Errorf("cannot parse %s statistics: %w", mode, err)
+	}
+	return st, nil
+}
+
+// StackUsage returns the restore size of the latest snapshot tagged tag and
+// the data stored for all of its snapshots
+func (r *ResticManager) StackUsage(ctx context.Context, tag string) (stats.Usage, error) {
+	restore, err := r.runStats(ctx, "restore-size", "--tag", tag, "latest")
+	if err != nil {
+		return stats.Usage{}, err
+	}
+	raw, err := r.runStats(ctx, "raw-data", "--tag", tag)
+	if err != nil {
+		return stats.Usage{}, err
+	}
+	return stats.Usage{
+		RestoreSize:      restore.TotalSize,
+		FileCount:        restore.TotalFileCount,
+		RawData:          raw.TotalSize,
+		Uncompressed:     raw.TotalUncompressedSize,
+		CompressionRatio: raw.CompressionRatio,
+		Snapshots:        raw.SnapshotsCount,
+	}, nil
+}
+
+// RepositoryUsage returns the data stored for all snapshots of the repository.
+// The restore size is left to the caller, restic adds it up per snapshot.
+func (r *ResticManager) RepositoryUsage(ctx context.Context) (stats.Usage, error) {
+	raw, err := r.runStats(ctx, "raw-data")
+	if err != nil {
+		return stats.Usage{}, err
+	}
+	return stats.Usage{
+		RawData:          raw.TotalSize,
+		Uncompressed:     raw.TotalUncompressedSize,
+		CompressionRatio: raw.CompressionRatio,
+		Snapshots:        raw.SnapshotsCount,
+	}, nil
+}
+
+// CollectStats measures the repository and each stack tag found in it,
+// including stacks that are no longer in the dirlist, and records the sample
+// in the statistics series. progress, if set, is called before each stack.
+func (s *Service) CollectStats(ctx context.Context, progress func(tag string, done, total int)) (stats.Sample, error) {
+	sample := stats.Sample{Time: time.Now(), Stacks: make(map[string]stats.Usage)}
+	if err := s.restic.CheckRepository(ctx); err != nil {
+		return sample, fmt.Errorf("cannot access repository: %w", err)
+	}
+	defer s.restic.Cleanup()
+
+	snapshots, err := s.restic.ListSnapshots(ctx, "", 0)
+	if err != nil {
+		return sample, err
+	}
+	seen := make(map[string]bool)
+	var tags []string
+	for _, snap := range snapshots {
+		if tag := snap.StackTag(); tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+
+	if sample.Repository, err = s.restic.RepositoryUsage(ctx); err != nil {
+		return sample, err
+	}
+	for i, tag := range tags {
+		if progress != nil {
+			progress(tag, i, len(tags))
+		}
+		usage, err := s.restic.StackUsage(ctx, tag)
+		if err != nil {
+			return sample, fmt.Errorf("%s: %w", tag, err)
+		}
+		sample.Stacks[tag] = usage
+		sample.Repository.RestoreSize += usage.RestoreSize
+		sample.Repository.FileCount += usage.FileCount
+	}
+	sample.Time = time.Now()
+
+	if s.dryRun {
+		return sample, nil
+	}
+	if err := stats.Record(s.config.LogDir, sample); err != nil {
+		util.LogWarn("Cannot record statistics: %v", err)
+	}
+	return sample, nil
+}
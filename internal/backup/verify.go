@@ -0,0 +1,167 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"backup-tui/internal/util"
+)
+
+// maxReportedFiles limits the problems listed one by one in the log
+const maxReportedFiles = 10
+
+// FileProblem is a file of a snapshot that does not match the stack directory
+type FileProblem struct {
+	Path   string
+	Reason string // "content differs", "missing from the stack", "not restored", ...
+}
+
+// FileReport is the result of comparing restored files with the stack directory
+type FileReport struct {
+	Checked  int // Files compared byte for byte
+	Changed  int // Files modified after the snapshot, not compared
+	Problems []FileProblem
+}
+
+// verifyFiles restores a sample of the files of snap, VERIFY_SAMPLE_FILES of
+// them or all when that is 0, to a scratch directory and compares them with
+// the files in the stack directory, which is still stopped
+func (r *ResticManager) verifyFiles(ctx context.Context, snap Snapshot) error {
+	nodes, err := r.ListFiles(ctx, snap.ShortID)
+	if err != nil {
+		return err
+	}
+	var files []SnapshotNode
+	for _, n := range nodes {
+		if n.Type == "file" {
+			files = append(files, n)
+		}
+	}
+	if len(files) == 0 {
+		util.LogProgress("Snapshot %s holds no files to compare", snap.ShortID)
+		return nil
+	}
+	sample := sampleFiles(files, r.config.VerifySampleFiles)
+
+	scratch, err := os.MkdirTemp("", "restic-verify-*")
+	if err != nil {
+		return fmt.Errorf("cannot create scratch directory: %w", err)
+	}
+	defer os.RemoveAll(scratch)
+
+	args := []string{"restore", snap.ShortID, "--target", scratch}
+	if len(sample) < len(files) {
+		paths := make([]string, len(sample))
+		for i, n := range sample {
+			paths[i] = n.Path
+		}
+		includeFile, err := writeIncludeFile(paths)
+		if err != nil {
+			return err
+		}
+		defer os.Remove(includeFile)
+		args = append(args, "--include-file", includeFile)
+	}
+	opts := util.CommandOptions{
+		Timeout:    time.Duration(r.config.Timeout) * time.Second,
+		CaptureOut: true,
+		CaptureErr: true,
+	}
+	result, err := r.run(ctx, args, opts)
+	if err != nil {
+		return fmt.Errorf("cannot restore files for verification: %w", err)
+	}
+	if !result.IsSuccess() {
+		return fmt.Errorf("cannot restore files for verification: %s", strings.TrimSpace(result.Stderr))
+	}
+
+	report := compareFiles(sample, scratch)
+	util.LogProgress("Compared %d of %d files of snapshot %s with the stack directory", report.Checked, len(files), snap.ShortID)
+	if report.Changed > 0 {
+		util.LogProgress("%d file(s) changed after the snapshot and were not compared", report.Changed)
+	}
+	if len(report.Problems) == 0 {
+		return nil
+	}
+
+	for i, p := range report.Problems {
+		if i == maxReportedFiles {
+			util.LogWarn("... and %d more", len(report.Problems)-maxReportedFiles)
+			break
+		}
+		util.LogWarn("%s: %s", p.Path, p.Reason)
+	}
+	return fmt.Errorf("%d of %d sampled files do not match the stack directory", len(report.Problems), len(sample))
+}
+
+// sampleFiles returns n randomly chosen files, or all of them when n is 0 or
+// not below their number. Each run picks other files, so repeated
+// verifications cover more of the stack over time.
+func sampleFiles(files []SnapshotNode, n int) []SnapshotNode {
+	if n <= 0 || n >= len(files) {
+		return files
+	}
+	sample := make([]SnapshotNode, 0, n)
+	for _, i := range rand.Perm(len(files))[:n] {
+		sample = append(sample, files[i])
+	}
+	return sample
+}
+
+// compareFiles compares each file as restored below root with the file at its
+// original path. Files whose size or modification time no longer match the
+// snapshot were changed after it and are only counted.
+func compareFiles(files []SnapshotNode, root string) FileReport {
+	var report FileReport
+	for _, n := range files {
+		live, err := os.Stat(n.Path)
+		if err != nil {
+			report.Problems = append(report.Problems, FileProblem{Path: n.Path, Reason: "missing from the stack"})
+			continue
+		}
+		if live.Size() != n.Size || !live.ModTime().Equal(n.Mtime) {
+			report.Changed++
+			continue
+		}
+
+		restored, err := fileHash(filepath.Join(root, n.Path))
+		if err != nil {
+			report.Problems = append(report.Problems, FileProblem{Path: n.Path, Reason: "not restored"})
+			continue
+		}
+		current, err := fileHash(n.Path)
+		if err != nil {
+			report.Problems = append(report.Problems, FileProblem{Path: n.Path, Reason: "unreadable in the stack"})
+			continue
+		}
+		report.Checked++
+		if restored != current {
+			report.Problems = append(report.Problems, FileProblem{Path: n.Path, Reason: "content differs"})
+		}
+	}
+	return report
+}
+
+// fileHash returns the SHA-256 of the file at path
+func fileHash(path string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	f, err := os.Open(path)
+	if err != nil {
+		return sum, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return sum, err
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
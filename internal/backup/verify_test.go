@@ -0,0 +1,75 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompareFiles(t *testing.T) {
+	stack := t.TempDir()
+	root := t.TempDir()
+
+	// node writes a file to the stack and, unless restored is nil, its restored copy
+	node := func(name, live string, restored []byte) SnapshotNode {
+		t.Helper()
+		path := filepath.Join(stack, name)
+		if err := os.WriteFile(path, []byte(live), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		info, _ := os.Stat(path)
+		if restored != nil {
+			copyPath := filepath.Join(root, path)
+			if err := os.MkdirAll(filepath.Dir(copyPath), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(copyPath, restored, 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return SnapshotNode{Name: name, Type: "file", Path: path, Size: info.Size(), Mtime: info.ModTime()}
+	}
+
+	same := node("same.txt", "hello", []byte("hello"))
+	corrupt := node("corrupt.txt", "hello", []byte("hellO"))
+	lost := node("lost.txt", "hello", nil)
+	changed := node("changed.txt", "hello", []byte("hello"))
+	changed.Size = 3
+	gone := SnapshotNode{Type: "file", Path: filepath.Join(stack, "gone.txt")}
+
+	report := compareFiles([]SnapshotNode{same, corrupt, lost, changed, gone}, root)
+	if report.Checked != 2 || report.Changed != 1 {
+		t.Errorf("Checked %d and skipped %d changed files, want 2 and 1", report.Checked, report.Changed)
+	}
+	want := map[string]string{
+		corrupt.Path: "content differs",
+		lost.Path:    "not restored",
+		gone.Path:    "missing from the stack",
+	}
+	if len(report.Problems) != len(want) {
+		t.Fatalf("Unexpected problems: %+v", report.Problems)
+	}
+	for _, p := range report.Problems {
+		if want[p.Path] != p.Reason {
+			t.Errorf("%s: got %q, want %q", p.Path, p.Reason, want[p.Path])
+		}
+	}
+}
+
+func TestSampleFiles(t *testing.T) {
+	files := make([]SnapshotNode, 10)
+	for i := range files {
+		files[i].Path = string(rune('a' + i))
+	}
+	if got := sampleFiles(files, 0); len(got) != 10 {
+		t.Errorf("A sample of 0 should hold all files, got %d", len(got))
+	}
+	got := sampleFiles(files, 4)
+	seen := make(map[string]bool)
+	for _, n := range got {
+		seen[n.Path] = true
+	}
+	if len(got) != 4 || len(seen) != 4 {
+		t.Errorf("Expected 4 distinct files, got %v", got)
+	}
+}
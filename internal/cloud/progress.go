@@ -0,0 +1,192 @@
+package cloud
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TransferStats is a snapshot of rclone's transfer statistics
+type TransferStats struct {
+	Bytes          int64
+	TotalBytes     int64
+	Speed          float64       // Bytes per second
+	ETA            time.Duration // Negative when unknown
+	Elapsed        time.Duration
+	Transfers      int64
+	TotalTransfers int64
+	Checks         int64
+	TotalChecks    int64
+	Errors         int64
+	Transferring   []string // Names of files currently in flight
+}
+
+// Percent returns the transferred fraction in the range 0..1
+func (s TransferStats) Percent() float64 {
+	if s.TotalBytes <= 0 {
+		return 0
+	}
+	p := float64(s.Bytes) / float64(s.TotalBytes)
+	if p > 1 {
+		p = 1
+	}
+	return p
+}
+
+// TransferError is a per-file error reported by rclone
+type TransferError struct {
+	Object  string
+	Message string
+}
+
+// ProgressEvent carries either a stats update or a per-file error
+type ProgressEvent struct {
+	Stats *TransferStats
+	Error *TransferError
+}
+
+// ProgressFunc receives structured progress while rclone is running
+type ProgressFunc func(ProgressEvent)
+
+// rcloneLogEntry is a single line of rclone --use-json-log output
+type rcloneLogEntry struct {
+	Level  string       `json:"level"`
+	Msg    string       `json:"msg"`
+	Object string       `json:"object"`
+	Stats  *rcloneStats `json:"stats"`
+}
+
+// rcloneStats mirrors the stats object attached to rclone's JSON stats log lines
+type rcloneStats struct {
+	Bytes          int64    `json:"bytes"`
+	TotalBytes     int64    `json:"totalBytes"`
+	Speed          float64  `json:"speed"`
+	ETA            *float64 `json:"eta"`
+	ElapsedTime    float64  `json:"elapsedTime"`
+	Transfers      int64    `json:"transfers"`
+	TotalTransfers int64    `json:"totalTransfers"`
+	Checks         int64    `json:"checks"`
+	TotalChecks    int64    `json:"totalChecks"`
+	Errors         int64    `json:"errors"`
+	Transferring   []struct {
+		Name string `json:"name"`
+	} `json:"transferring"`
+}
+
+// progressWriter parses rclone JSON log output line by line.
+// Stats lines become ProgressEvents, other JSON lines are rendered as text,
+// and anything that is not JSON is passed through unchanged.
+type progressWriter struct {
+	mu       sync.Mutex
+	out      io.Writer
+	progress ProgressFunc
+	buf      bytes.Buffer
+}
+
+// newProgressWriter creates a writer that feeds fn and forwards readable output to out
+func newProgressWriter(out io.Writer, fn ProgressFunc) *progressWriter {
+	return &progressWriter{out: out, progress: fn}
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// Incomplete line, keep it for the next write
+			w.buf.Reset()
+			w.buf.WriteString(line)
+			break
+		}
+		w.handleLine(strings.TrimRight(line, "\r\n"))
+	}
+	return len(p), nil
+}
+
+// Flush processes any buffered partial line
+func (w *progressWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf.Len() > 0 {
+		w.handleLine(w.buf.String())
+		w.buf.Reset()
+	}
+}
+
+func (w *progressWriter) handleLine(line string) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return
+	}
+
+	var entry rcloneLogEntry
+	if !strings.HasPrefix(trimmed, "{") || json.Unmarshal([]byte(trimmed), &entry) != nil {
+		w.print(line + "\n")
+		return
+	}
+
+	if entry.Stats != nil {
+		stats := entry.Stats.toTransferStats()
+		w.emit(ProgressEvent{Stats: &stats})
+		return
+	}
+
+	msg := strings.TrimSpace(entry.Msg)
+	if entry.Object != "" {
+		msg = entry.Object + ": " + msg
+	}
+	if entry.Level == "error" || entry.Level == "critical" {
+		w.emit(ProgressEvent{Error: &TransferError{Object: entry.Object, Message: strings.TrimSpace(entry.Msg)}})
+	}
+	w.print(fmt.Sprintf("%s: %s\n", strings.ToUpper(entry.Level), msg))
+}
+
+func (w *progressWriter) emit(ev ProgressEvent) {
+	if w.progress != nil {
+		w.progress(ev)
+	}
+}
+
+func (w *progressWriter) print(s string) {
+	if w.out != nil {
+		_, _ = io.WriteString(w.out, s)
+	}
+}
+
+func (s *rcloneStats) toTransferStats() TransferStats {
+	stats := TransferStats{
+		Bytes:          s.Bytes,
+		TotalBytes:     s.TotalBytes,
+		Speed:          s.Speed,
+		ETA:            -1,
+		Elapsed:        time.Duration(s.ElapsedTime * float64(time.Second)),
+		Transfers:      s.Transfers,
+		TotalTransfers: s.TotalTransfers,
+		Checks:         s.Checks,
+		TotalChecks:    s.TotalChecks,
+		Errors:         s.Errors,
+	}
+	if s.ETA != nil {
+		stats.ETA = time.Duration(*s.ETA * float64(time.Second))
+	}
+	for _, t := range s.Transferring {
+		stats.Transferring = append(stats.Transferring, t.Name)
+	}
+	return stats
+}
+
+// transferArgs returns the rclone output flags for plain or structured progress
+func transferArgs(structured bool) []string {
+	if structured {
+		return []string{"--use-json-log", "--stats", "1s", "--verbose"}
+	}
+	return []string{"--progress", "--stats", "30s", "--stats-one-line", "--verbose"}
+}
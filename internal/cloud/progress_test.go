@@ -0,0 +1,65 @@
+package cloud
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgressWriter(t *testing.T) {
+	var out strings.Builder
+	var events []ProgressEvent
+	w := newProgressWriter(&out, func(ev ProgressEvent) { events = append(events, ev) })
+
+	stats := `{"level":"info","msg":"\nTransferred: ...","stats":{"bytes":512,"totalBytes":1024,"speed":256,"eta":2,"elapsedTime":2.5,"transfers":1,"totalTransfers":4,"errors":0,"transferring":[{"name":"data/ab/abcdef"}]},"time":"2024-01-01T00:00:00Z"}`
+	fileErr := `{"level":"error","msg":"Failed to copy: permission denied","object":"data/cd/cdef01","objectType":"*local.Object","time":"2024-01-01T00:00:01Z"}`
+
+	// Write in awkward chunks to exercise line buffering
+	input := stats + "\n" + fileErr + "\nplain text line\npartial"
+	for i := 0; i < len(input); i += 7 {
+		end := i + 7
+		if end > len(input) {
+			end = len(input)
+		}
+		if _, err := w.Write([]byte(input[i:end])); err != nil {
+			t.Fatalf("Write error: %v", err)
+		}
+	}
+	w.Flush()
+
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+
+	s := events[0].Stats
+	if s == nil {
+		t.Fatalf("First event is not a stats update")
+	}
+	if s.Bytes != 512 || s.TotalBytes != 1024 || s.TotalTransfers != 4 {
+		t.Fatalf("Unexpected stats: %+v", *s)
+	}
+	if s.Percent() != 0.5 {
+		t.Fatalf("Percent: got %v, want 0.5", s.Percent())
+	}
+	if s.ETA != 2*time.Second {
+		t.Fatalf("ETA: got %v, want 2s", s.ETA)
+	}
+	if len(s.Transferring) != 1 || s.Transferring[0] != "data/ab/abcdef" {
+		t.Fatalf("Unexpected transferring list: %v", s.Transferring)
+	}
+
+	e := events[1].Error
+	if e == nil || e.Object != "data/cd/cdef01" || !strings.Contains(e.Message, "permission denied") {
+		t.Fatalf("Unexpected error event: %+v", events[1])
+	}
+
+	text := out.String()
+	if strings.Contains(text, "Transferred") {
+		t.Fatalf("Stats lines should not be forwarded as text: %q", text)
+	}
+	for _, want := range []string{"ERROR: data/cd/cdef01: Failed to copy", "plain text line", "partial"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("Output missing %q: %q", want, text)
+		}
+	}
+}
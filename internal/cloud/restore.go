@@ -1,6 +1,7 @@
 package cloud
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -8,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"backup-tui/internal/backup"
 	"backup-tui/internal/config"
 	"backup-tui/internal/util"
 )
@@ -18,6 +20,11 @@ type RestoreService struct {
 	dryRun       bool
 	force        bool
 	outputWriter io.Writer
+	progress     ProgressFunc
+
+	// Restored repository check (nil = structural checks only)
+	repoCheck *config.LocalBackupConfig
+	readData  bool
 }
 
 // NewRestoreService creates a new restore service
@@ -39,8 +46,29 @@ func NewRestoreServiceWithOutput(cfg *config.CloudSyncConfig, dryRun, force bool
 	}
 }
 
+// SetProgressFunc enables structured progress reporting.
+// When set, rclone runs with JSON logging and stats are passed to fn instead of a progress line.
+func (r *RestoreService) SetProgressFunc(fn ProgressFunc) {
+	r.progress = fn
+}
+
+// SetRepositoryCheck enables a restic check of the restored repository during Verify.
+// The password settings from cfg are used to open it; readData also verifies all pack data.
+func (r *RestoreService) SetRepositoryCheck(cfg *config.LocalBackupConfig, readData bool) {
+	r.repoCheck = cfg
+	r.readData = readData
+}
+
+// output returns the writer for human readable output
+func (r *RestoreService) output() io.Writer {
+	if r.outputWriter != nil {
+		return r.outputWriter
+	}
+	return os.Stdout
+}
+
 // Restore downloads the backup from cloud storage
-func (r *RestoreService) Restore(targetDir string) error {
+func (r *RestoreService) Restore(ctx context.Context, targetDir string) error {
 	source := fmt.Sprintf("%s:%s", r.config.Remote, r.config.Path)
 
 	util.LogProgress("Starting restore from: %s", source)
@@ -53,10 +81,10 @@ func (r *RestoreService) Restore(targetDir string) error {
 	}
 
 	if r.dryRun {
-		return r.dryRunRestore(source, targetDir)
+		return r.dryRunRestore(ctx, source, targetDir)
 	}
 
-	return r.restoreWithRetry(source, targetDir)
+	return r.restoreWithRetry(ctx, source, targetDir)
 }
 
 func (r *RestoreService) prepareDirectory(targetDir string) error {
@@ -102,7 +130,7 @@ func (r *RestoreService) prepareDirectory(targetDir string) error {
 	return nil
 }
 
-func (r *RestoreService) dryRunRestore(source, targetDir string) error {
+func (r *RestoreService) dryRunRestore(ctx context.Context, source, targetDir string) error {
 	util.LogProgress("[DRY RUN] Previewing restore operation...")
 
 	args := []string{
@@ -121,7 +149,7 @@ func (r *RestoreService) dryRunRestore(source, targetDir string) error {
 		OutputWriter: r.outputWriter,
 	}
 
-	result, err := util.RunCommand("rclone", args, opts)
+	result, err := util.RunCommand(ctx, "rclone", args, opts)
 	if err != nil {
 		return fmt.Errorf("dry run failed: %w", err)
 	}
@@ -132,46 +160,29 @@ func (r *RestoreService) dryRunRestore(source, targetDir string) error {
 	return nil
 }
 
-func (r *RestoreService) restoreWithRetry(source, targetDir string) error {
-	retries := r.config.Retries
-	if retries < 1 {
-		retries = 3
-	}
-
-	var lastErr error
-	for attempt := 1; attempt <= retries; attempt++ {
-		util.LogProgress("Restore attempt %d of %d", attempt, retries)
+func (r *RestoreService) restoreWithRetry(ctx context.Context, source, targetDir string) error {
+	policy := r.config.RetryPolicy()
 
-		if err := r.doRestore(source, targetDir); err != nil {
-			lastErr = err
-			util.LogWarn("Restore attempt %d failed: %v", attempt, err)
-
-			if attempt < retries {
-				waitTime := time.Duration(attempt*30) * time.Second
-				util.LogInfo("Waiting %v before retry...", waitTime)
-				time.Sleep(waitTime)
-			}
-		} else {
-			util.LogSuccess("Restore completed successfully")
-			return nil
-		}
+	err := policy.Do(ctx, "Restore", func(_ int) error {
+		return r.doRestore(ctx, source, targetDir)
+	})
+	if err != nil {
+		return err
 	}
 
-	return fmt.Errorf("restore failed after %d attempts: %w", retries, lastErr)
+	util.LogSuccess("Restore completed successfully")
+	return nil
 }
 
-func (r *RestoreService) doRestore(source, targetDir string) error {
+func (r *RestoreService) doRestore(ctx context.Context, source, targetDir string) error {
 	args := []string{
 		"copy",
-		"--progress",
 		"--links",
 		"--transfers", fmt.Sprintf("%d", r.config.Transfers),
 		"--retries", "3",
 		"--low-level-retries", "10",
-		"--stats", "30s",
-		"--stats-one-line",
-		"--verbose",
 	}
+	args = append(args, transferArgs(r.progress != nil)...)
 
 	if r.config.Bandwidth != "" {
 		args = append(args, "--bwlimit", r.config.Bandwidth)
@@ -187,19 +198,34 @@ func (r *RestoreService) doRestore(source, targetDir string) error {
 		OutputWriter: r.outputWriter,
 	}
 
-	result, err := util.RunCommand("rclone", args, opts)
+	if r.progress != nil {
+		pw := newProgressWriter(r.output(), r.progress)
+		defer pw.Flush()
+		opts.OutputWriter = pw
+	}
+
+	result, err := util.RunCommand(ctx, "rclone", args, opts)
+	if result != nil && result.TimedOut {
+		return &util.RcloneExitError{Operation: "restore", ExitCode: result.ExitCode, TimedOut: true}
+	}
 	if err != nil {
 		return err
 	}
 	if !result.IsSuccess() {
-		return fmt.Errorf("restore exited with code %d", result.ExitCode)
+		return &util.RcloneExitError{Operation: "restore", ExitCode: result.ExitCode}
 	}
 
 	return nil
 }
 
-// Verify verifies the restored data
-func (r *RestoreService) Verify(targetDir string) error {
+// Verify verifies the restored data.
+// When a repository check is configured, an unreadable repository is an error.
+func (r *RestoreService) Verify(ctx context.Context, targetDir string) error {
+	if r.dryRun {
+		util.LogInfo("[DRY RUN] Skipping verification of restored data")
+		return nil
+	}
+
 	util.LogInfo("Verifying restored data...")
 
 	// Check directory has content
@@ -212,14 +238,16 @@ func (r *RestoreService) Verify(targetDir string) error {
 		return fmt.Errorf("restore directory is empty")
 	}
 
-	// Count files
+	// Count files and total size
 	fileCount := 0
+	var totalSize int64
 	err = filepath.Walk(targetDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
 		if !info.IsDir() {
 			fileCount++
+			totalSize += info.Size()
 		}
 		return nil
 	})
@@ -227,43 +255,70 @@ func (r *RestoreService) Verify(targetDir string) error {
 		util.LogWarn("Error counting files: %v", err)
 	}
 	util.LogInfo("Restored %d files", fileCount)
+	util.LogInfo("Total restored size: %s", util.FormatSize(totalSize))
 
 	// Check for restic repository structure
-	dataDir := filepath.Join(targetDir, "data")
-	keysDir := filepath.Join(targetDir, "keys")
-	configFile := filepath.Join(targetDir, "config")
-
-	if _, err := os.Stat(dataDir); err == nil {
-		if _, err := os.Stat(keysDir); err == nil {
-			util.LogInfo("Detected restic repository structure")
-
-			if _, err := os.Stat(configFile); err == nil {
-				util.LogInfo("Restic repository config found")
-			} else {
-				util.LogWarn("Restic repository config not found - repository may be incomplete")
-			}
+	isRepo := true
+	for _, name := range []string{"data", "keys", "config"} {
+		if _, err := os.Stat(filepath.Join(targetDir, name)); err != nil {
+			util.LogWarn("Restic repository %s not found - repository may be incomplete", name)
+			isRepo = false
 		}
 	}
+	if isRepo {
+		util.LogInfo("Detected restic repository structure")
+	}
 
-	// Calculate total size
-	var totalSize int64
-	_ = filepath.Walk(targetDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil
-		}
-		if !info.IsDir() {
-			totalSize += info.Size()
-		}
+	if r.repoCheck == nil {
 		return nil
-	})
+	}
+	if !isRepo {
+		return fmt.Errorf("restored data is not a complete restic repository")
+	}
+
+	return r.checkRepository(ctx, targetDir)
+}
 
-	util.LogInfo("Total restored size: %s", formatSize(totalSize))
+// checkRepository opens the restored repository with restic, lists its snapshots and runs restic check
+func (r *RestoreService) checkRepository(ctx context.Context, targetDir string) error {
+	cfg := *r.repoCheck
+	cfg.Repository = targetDir
+
+	restic := backup.NewResticManager(&cfg, false, r.outputWriter)
+	defer restic.Cleanup()
+
+	if err := restic.CheckRepository(ctx); err != nil {
+		return fmt.Errorf("restored repository is not readable: %w", err)
+	}
 
+	snapshots, err := restic.ListSnapshots(ctx, "", 0)
+	if err != nil {
+		return fmt.Errorf("cannot list snapshots in restored repository: %w", err)
+	}
+	if len(snapshots) == 0 {
+		util.LogWarn("Restored repository contains no snapshots")
+	} else {
+		util.LogInfo("Restored repository contains %d snapshots:", len(snapshots))
+		out := r.output()
+		for _, snap := range snapshots {
+			snapTime := snap.Time
+			if t, err := time.Parse(time.RFC3339Nano, snap.Time); err == nil {
+				snapTime = t.Format("2006-01-02 15:04")
+			}
+			fmt.Fprintf(out, "  %s  %s  %s  [%s]\n", snap.ShortID, snapTime, snap.Hostname, strings.Join(snap.Tags, ", "))
+		}
+	}
+
+	if err := restic.Check(ctx, r.readData); err != nil {
+		return fmt.Errorf("restored repository failed integrity check: %w", err)
+	}
+
+	util.LogSuccess("Restored repository verified")
 	return nil
 }
 
 // TestConnectivity tests connection to the remote
-func (r *RestoreService) TestConnectivity() error {
+func (r *RestoreService) TestConnectivity(ctx context.Context) error {
 	util.LogInfo("Testing remote connectivity: %s", r.config.Remote)
 
 	args := []string{"lsd", fmt.Sprintf("%s:", r.config.Remote), "--max-depth", "1"}
@@ -274,7 +329,7 @@ func (r *RestoreService) TestConnectivity() error {
 		CaptureErr: true,
 	}
 
-	result, err := util.RunCommand("rclone", args, opts)
+	result, err := util.RunCommand(ctx, "rclone", args, opts)
 	if err != nil {
 		return fmt.Errorf("cannot connect to remote: %w", err)
 	}
@@ -284,13 +339,13 @@ func (r *RestoreService) TestConnectivity() error {
 
 	// List remote backup contents
 	remotePath := fmt.Sprintf("%s:%s", r.config.Remote, r.config.Path)
-	result, err = util.RunCommand("rclone", []string{"lsd", remotePath, "--max-depth", "1"}, opts)
+	result, err = util.RunCommand(ctx, "rclone", []string{"lsd", remotePath, "--max-depth", "1"}, opts)
 	if err == nil {
 		util.LogInfo("Remote contents:")
 		for _, line := range strings.Split(result.Stdout, "\n") {
 			line = strings.TrimSpace(line)
 			if line != "" {
-				fmt.Printf("  %s\n", line)
+				fmt.Fprintf(r.output(), "  %s\n", line)
 			}
 		}
 	}
@@ -301,34 +356,12 @@ func (r *RestoreService) TestConnectivity() error {
 
 // PrintNextSteps prints instructions for after restore
 func (r *RestoreService) PrintNextSteps(targetDir string) {
-	fmt.Println()
-	fmt.Println("Next steps:")
-	fmt.Println("  1. Verify the restored data in:", targetDir)
-	fmt.Println("  2. If this is a restic repository, use it with:")
-	fmt.Printf("     export RESTIC_REPOSITORY=%s\n", targetDir)
-	fmt.Println("     restic snapshots")
-	fmt.Println()
-}
-
-// formatSize formats bytes to human readable size
-func formatSize(bytes int64) string {
-	const (
-		KB = 1024
-		MB = KB * 1024
-		GB = MB * 1024
-		TB = GB * 1024
-	)
-
-	switch {
-	case bytes >= TB:
-		return fmt.Sprintf("%.2f TB", float64(bytes)/TB)
-	case bytes >= GB:
-		return fmt.Sprintf("%.2f GB", float64(bytes)/GB)
-	case bytes >= MB:
-		return fmt.Sprintf("%.2f MB", float64(bytes)/MB)
-	case bytes >= KB:
-		return fmt.Sprintf("%.2f KB", float64(bytes)/KB)
-	default:
-		return fmt.Sprintf("%d bytes", bytes)
-	}
+	out := r.output()
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Next steps:")
+	fmt.Fprintln(out, "  1. Verify the restored data in:", targetDir)
+	fmt.Fprintln(out, "  2. If this is a restic repository, use it with:")
+	fmt.Fprintf(out, "     export RESTIC_REPOSITORY=%s\n", targetDir)
+	fmt.Fprintln(out, "     restic snapshots")
+	fmt.Fprintln(out)
 }
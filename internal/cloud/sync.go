@@ -2,12 +2,16 @@
 package cloud
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"os"
 	"strings"
 	"time"
 
 	"backup-tui/internal/config"
+	"backup-tui/internal/status"
 	"backup-tui/internal/util"
 )
 
@@ -17,6 +21,8 @@ type SyncService struct {
 	sourceDir    string // Local restic repository path
 	dryRun       bool
 	outputWriter io.Writer
+	progress     ProgressFunc
+	statusDir    string // Log directory to record the sync result in (empty = don't record)
 }
 
 // NewSyncService creates a new sync service
@@ -38,8 +44,27 @@ func NewSyncServiceWithOutput(cfg *config.CloudSyncConfig, sourceDir string, dry
 	}
 }
 
+// SetProgressFunc enables structured progress reporting.
+// When set, rclone runs with JSON logging and stats are passed to fn instead of a progress line.
+func (s *SyncService) SetProgressFunc(fn ProgressFunc) {
+	s.progress = fn
+}
+
+// SetStatusDir records the result of each real sync in the status file in logDir
+func (s *SyncService) SetStatusDir(logDir string) {
+	s.statusDir = logDir
+}
+
+// output returns the writer for human readable output
+func (s *SyncService) output() io.Writer {
+	if s.outputWriter != nil {
+		return s.outputWriter
+	}
+	return os.Stdout
+}
+
 // Sync performs the cloud sync with retry logic
-func (s *SyncService) Sync() error {
+func (s *SyncService) Sync(ctx context.Context) error {
 	destination := fmt.Sprintf("%s:%s", s.config.Remote, s.config.Path)
 
 	util.LogProgress("Starting sync to: %s", destination)
@@ -47,13 +72,21 @@ func (s *SyncService) Sync() error {
 	util.LogInfo("Transfers: %d", s.config.Transfers)
 
 	if s.dryRun {
-		return s.dryRunSync(destination)
+		return s.dryRunSync(ctx, destination)
 	}
 
-	return s.syncWithRetry(destination)
+	start := time.Now()
+	err := s.syncWithRetry(ctx, destination)
+	// A cancelled sync keeps the result of the last complete one
+	if s.statusDir != "" && !errors.Is(err, context.Canceled) {
+		if recErr := status.RecordSync(s.statusDir, status.NewResult(start, err)); recErr != nil {
+			util.LogWarn("Cannot record sync result: %v", recErr)
+		}
+	}
+	return err
 }
 
-func (s *SyncService) dryRunSync(destination string) error {
+func (s *SyncService) dryRunSync(ctx context.Context, destination string) error {
 	util.LogProgress("[DRY RUN] Previewing sync operation...")
 
 	args := []string{
@@ -72,7 +105,7 @@ func (s *SyncService) dryRunSync(destination string) error {
 		OutputWriter: s.outputWriter,
 	}
 
-	result, err := util.RunCommand("rclone", args, opts)
+	result, err := util.RunCommand(ctx, "rclone", args, opts)
 	if err != nil {
 		return fmt.Errorf("dry run failed: %w", err)
 	}
@@ -83,46 +116,29 @@ func (s *SyncService) dryRunSync(destination string) error {
 	return nil
 }
 
-func (s *SyncService) syncWithRetry(destination string) error {
-	retries := s.config.Retries
-	if retries < 1 {
-		retries = 3
-	}
-
-	var lastErr error
-	for attempt := 1; attempt <= retries; attempt++ {
-		util.LogProgress("Sync attempt %d of %d", attempt, retries)
+func (s *SyncService) syncWithRetry(ctx context.Context, destination string) error {
+	policy := s.config.RetryPolicy()
 
-		if err := s.doSync(destination); err != nil {
-			lastErr = err
-			util.LogWarn("Sync attempt %d failed: %v", attempt, err)
-
-			if attempt < retries {
-				waitTime := time.Duration(attempt*30) * time.Second
-				util.LogInfo("Waiting %v before retry...", waitTime)
-				time.Sleep(waitTime)
-			}
-		} else {
-			util.LogSuccess("Sync completed successfully")
-			return nil
-		}
+	err := policy.Do(ctx, "Sync", func(_ int) error {
+		return s.doSync(ctx, destination)
+	})
+	if err != nil {
+		return err
 	}
 
-	return fmt.Errorf("sync failed after %d attempts: %w", retries, lastErr)
+	util.LogSuccess("Sync completed successfully")
+	return nil
 }
 
-func (s *SyncService) doSync(destination string) error {
+func (s *SyncService) doSync(ctx context.Context, destination string) error {
 	args := []string{
 		"sync",
-		"--progress",
 		"--links",
 		"--transfers", fmt.Sprintf("%d", s.config.Transfers),
 		"--retries", "3",
 		"--low-level-retries", "10",
-		"--stats", "30s",
-		"--stats-one-line",
-		"--verbose",
 	}
+	args = append(args, transferArgs(s.progress != nil)...)
 
 	if s.config.Bandwidth != "" {
 		args = append(args, "--bwlimit", s.config.Bandwidth)
@@ -138,19 +154,28 @@ func (s *SyncService) doSync(destination string) error {
 		OutputWriter: s.outputWriter,
 	}
 
-	result, err := util.RunCommand("rclone", args, opts)
+	if s.progress != nil {
+		pw := newProgressWriter(s.output(), s.progress)
+		defer pw.Flush()
+		opts.OutputWriter = pw
+	}
+
+	result, err := util.RunCommand(ctx, "rclone", args, opts)
+	if result != nil && result.TimedOut {
+		return &util.RcloneExitError{Operation: "sync", ExitCode: result.ExitCode, TimedOut: true}
+	}
 	if err != nil {
 		return err
 	}
 	if !result.IsSuccess() {
-		return fmt.Errorf("sync exited with code %d", result.ExitCode)
+		return &util.RcloneExitError{Operation: "sync", ExitCode: result.ExitCode}
 	}
 
 	return nil
 }
 
 // TestConnectivity tests connection to the remote
-func (s *SyncService) TestConnectivity() error {
+func (s *SyncService) TestConnectivity(ctx context.Context) error {
 	util.LogInfo("Testing remote connectivity: %s", s.config.Remote)
 
 	args := []string{"lsd", fmt.Sprintf("%s:", s.config.Remote), "--max-depth", "1"}
@@ -161,7 +186,7 @@ func (s *SyncService) TestConnectivity() error {
 		CaptureErr: true,
 	}
 
-	result, err := util.RunCommand("rclone", args, opts)
+	result, err := util.RunCommand(ctx, "rclone", args, opts)
 	if err != nil {
 		return fmt.Errorf("cannot connect to remote: %w", err)
 	}
@@ -174,7 +199,7 @@ func (s *SyncService) TestConnectivity() error {
 }
 
 // GetRemoteSize returns the size of the remote backup
-func (s *SyncService) GetRemoteSize() (string, error) {
+func (s *SyncService) GetRemoteSize(ctx context.Context) (string, error) {
 	remotePath := fmt.Sprintf("%s:%s", s.config.Remote, s.config.Path)
 
 	opts := util.CommandOptions{
@@ -182,7 +207,7 @@ func (s *SyncService) GetRemoteSize() (string, error) {
 		CaptureOut: true,
 	}
 
-	result, err := util.RunCommand("rclone", []string{"size", remotePath}, opts)
+	result, err := util.RunCommand(ctx, "rclone", []string{"size", remotePath}, opts)
 	if err != nil {
 		return "", err
 	}
@@ -191,7 +216,7 @@ func (s *SyncService) GetRemoteSize() (string, error) {
 }
 
 // ListRemoteContents lists the contents of the remote backup location
-func (s *SyncService) ListRemoteContents() ([]string, error) {
+func (s *SyncService) ListRemoteContents(ctx context.Context) ([]string, error) {
 	remotePath := fmt.Sprintf("%s:%s", s.config.Remote, s.config.Path)
 
 	opts := util.CommandOptions{
@@ -199,7 +224,7 @@ func (s *SyncService) ListRemoteContents() ([]string, error) {
 		CaptureOut: true,
 	}
 
-	result, err := util.RunCommand("rclone", []string{"lsd", remotePath, "--max-depth", "1"}, opts)
+	result, err := util.RunCommand(ctx, "rclone", []string{"lsd", remotePath, "--max-depth", "1"}, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -221,7 +246,7 @@ func RcloneAvailable() bool {
 }
 
 // ValidateRemote checks if an rclone remote is configured
-func ValidateRemote(remoteName string) error {
+func ValidateRemote(ctx context.Context, remoteName string) error {
 	if !RcloneAvailable() {
 		return fmt.Errorf("rclone is not installed")
 	}
@@ -231,7 +256,7 @@ func ValidateRemote(remoteName string) error {
 		CaptureOut: true,
 	}
 
-	result, err := util.RunCommand("rclone", []string{"listremotes"}, opts)
+	result, err := util.RunCommand(ctx, "rclone", []string{"listremotes"}, opts)
 	if err != nil {
 		return fmt.Errorf("cannot list remotes: %w", err)
 	}
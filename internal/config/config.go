@@ -6,8 +6,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+
+	"backup-tui/internal/util"
 )
 
 // Config holds all unified configuration for the backup system
@@ -27,6 +31,15 @@ type Config struct {
 	LogDir      string
 	LockDir     string
 	BaseDir     string
+	Profile     string // Selected [profile:NAME] section, empty for none
+
+	// Raw value per "section.KEY" of settings resolved from a reference
+	// or set by an environment variable, see Reference
+	references map[string]string
+
+	// Where each setting was read and the problems found while loading, see Lint
+	positions map[string]position
+	issues    []Issue
 }
 
 // DockerConfig holds Docker-related settings
@@ -54,6 +67,12 @@ type LocalBackupConfig struct {
 	// Verification
 	EnableVerification bool
 	VerificationDepth  string // metadata, files, data
+	VerifySampleFiles  int    // Files restored and compared by the files depth (0 = all)
+	CheckSubsets       int    // Slices of pack data the check command rotates through
+
+	// Dashboard freshness thresholds (hours since the last snapshot)
+	StaleWarningHours  int
+	StaleCriticalHours int
 }
 
 // CloudSyncConfig holds rclone sync settings
@@ -63,6 +82,10 @@ type CloudSyncConfig struct {
 	Transfers int    // Concurrent transfers
 	Retries   int    // Retry attempts
 	Bandwidth string // Bandwidth limit (e.g., "10M")
+
+	// Retry backoff (seconds)
+	RetryDelay    int // Wait before the first retry, doubled per attempt
+	RetryMaxDelay int // Upper bound for a single wait
 }
 
 // DefaultConfig returns a Config with sensible defaults
@@ -81,19 +104,33 @@ func DefaultConfig() *Config {
 			AutoPrune:          true,
 			EnableVerification: true,
 			VerificationDepth:  "metadata",
+			VerifySampleFiles:  50,
+			CheckSubsets:       12,
+			StaleWarningHours:  26,
+			StaleCriticalHours: 72,
 		},
 		CloudSync: CloudSyncConfig{
-			Path:      "/backup/restic",
-			Transfers: 4,
-			Retries:   3,
+			Path:          "/backup/restic",
+			Transfers:     4,
+			Retries:       3,
+			RetryDelay:    30,
+			RetryMaxDelay: 600,
 		},
 	}
 }
 
 // Load reads configuration from an INI-style config file with sections
 func Load(configPath string) (*Config, error) {
+	return LoadProfile(configPath, "")
+}
+
+// LoadProfile reads the config file like Load and then applies the settings
+// of the [profile:NAME] section. Settings are merged onto DefaultConfig in
+// this order: the file with its includes, the profile, the environment.
+func LoadProfile(configPath, profile string) (*Config, error) {
 	cfg := DefaultConfig()
 	cfg.ConfigFile = configPath
+	cfg.Profile = profile
 
 	// Determine base directory from config path
 	cfg.BaseDir = filepath.Dir(filepath.Dir(configPath))
@@ -101,16 +138,82 @@ func Load(configPath string) (*Config, error) {
 	cfg.LogDir = filepath.Join(cfg.BaseDir, "logs")
 	cfg.LockDir = filepath.Join(cfg.BaseDir, "locks")
 
-	file, err := os.Open(configPath)
+	l := &loader{cfg: cfg, dir: filepath.Dir(configPath), profiles: make(map[string][]profileLine)}
+	if err := l.readFile(configPath); err != nil {
+		return nil, err
+	}
+
+	if profile != "" {
+		lines, ok := l.profiles[profile]
+		if !ok {
+			return nil, fmt.Errorf("profile %q not found in config", profile)
+		}
+		for _, pl := range lines {
+			if err := l.apply(pl.section, pl.key, pl.value, pl.literal, pl.pos); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// The environment has the last word, for containers and secret managers
+	if err := cfg.applyEnvOverrides(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// loader reads a config file and the files it includes into a Config
+type loader struct {
+	cfg      *Config
+	dir      string   // Directory of the main config file, includes are shown relative to it
+	open     []string // Files being read, to detect include cycles
+	profiles map[string][]profileLine
+}
+
+// profileLine is a setting of a [profile:NAME] section, applied after the whole file was read
+type profileLine struct {
+	section, key, value string
+	literal             bool
+	pos                 position
+}
+
+// readFile reads one config file; include= lines read other files in place
+func (l *loader) readFile(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	for _, p := range l.open {
+		if p == abs {
+			return fmt.Errorf("include cycle: %s includes itself", path)
+		}
+	}
+	l.open = append(l.open, abs)
+	defer func() { l.open = l.open[:len(l.open)-1] }()
+
+	file, err := os.Open(path)
 	if err != nil {
-		return nil, fmt.Errorf("cannot open config file: %w", err)
+		if len(l.open) > 1 {
+			return fmt.Errorf("cannot open included file: %w", err)
+		}
+		return fmt.Errorf("cannot open config file: %w", err)
 	}
 	defer file.Close()
 
+	source := filepath.Base(path)
+	if rel, err := filepath.Rel(l.dir, path); err == nil && len(l.open) > 1 && !strings.HasPrefix(rel, "..") {
+		source = rel
+	} else if len(l.open) > 1 {
+		source = path
+	}
 	currentSection := ""
+	profile := ""
+	lineNum := 0
 	scanner := bufio.NewScanner(file)
 
 	for scanner.Scan() {
+		lineNum++
 		line := strings.TrimSpace(scanner.Text())
 
 		// Skip empty lines and comments
@@ -120,39 +223,169 @@ func Load(configPath string) (*Config, error) {
 
 		// Check for section header
 		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
-			currentSection = strings.ToLower(strings.Trim(line, "[]"))
+			currentSection = strings.ToLower(strings.TrimSpace(strings.Trim(line, "[]")))
+			profile = ""
+			if name, ok := strings.CutPrefix(currentSection, "profile:"); ok {
+				profile = strings.TrimSpace(name)
+				if profile == "" {
+					l.cfg.addIssue(source, lineNum, "profile section without a name")
+				}
+				if _, seen := l.profiles[profile]; !seen {
+					l.profiles[profile] = nil
+				}
+			} else if !knownSections[currentSection] {
+				l.cfg.addIssue(source, lineNum, "unknown section [%s], its settings are ignored", currentSection)
+			}
 			continue
 		}
 
 		// Parse KEY=VALUE
 		parts := strings.SplitN(line, "=", 2)
 		if len(parts) != 2 {
+			l.cfg.addIssue(source, lineNum, "expected KEY=VALUE, got %q", line)
 			continue
 		}
 
 		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
+		value, literal := parseValue(parts[1])
+		pos := position{source: source, line: lineNum, profile: profile}
 
-		// Remove inline comments
-		if idx := strings.Index(value, "#"); idx != -1 {
-			// Make sure it's not inside quotes
-			if !isInQuotes(value, idx) {
-				value = strings.TrimSpace(value[:idx])
+		if strings.EqualFold(key, "include") {
+			if err := l.include(value, filepath.Dir(path)); err != nil {
+				return fmt.Errorf("%s line %d: %w", source, lineNum, err)
 			}
+			continue
 		}
 
-		// Remove quotes
-		value = strings.Trim(value, `"'`)
+		if strings.HasPrefix(currentSection, "profile:") {
+			if section, name, ok := l.profileSetting(key, pos); ok {
+				l.profiles[profile] = append(l.profiles[profile], profileLine{section: section, key: name, value: value, literal: literal, pos: pos})
+			}
+			continue
+		}
 
-		// Apply value based on section
-		cfg.applyValue(currentSection, key, value)
+		if err := l.apply(currentSection, key, value, literal, pos); err != nil {
+			return err
+		}
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading config: %w", err)
+		return fmt.Errorf("error reading config: %w", err)
 	}
+	return nil
+}
 
-	return cfg, nil
+// include reads the files matched by pattern, relative to dir unless absolute
+func (l *loader) include(pattern, dir string) error {
+	if pattern == "" {
+		return fmt.Errorf("include without a file name")
+	}
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(dir, pattern)
+	}
+
+	paths := []string{pattern}
+	if strings.ContainsAny(pattern, "*?[") {
+		// A pattern may match nothing, conf.d/*.ini with an empty conf.d is fine
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid include pattern %q: %w", pattern, err)
+		}
+		sort.Strings(matches)
+		paths = matches
+	}
+	for _, p := range paths {
+		if err := l.readFile(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// profileSetting splits the key of a profile setting into section and key:
+// keys are written as SECTION.KEY, or without the section when the key names
+// one setting only
+func (l *loader) profileSetting(key string, pos position) (string, string, bool) {
+	if section, name, ok := strings.Cut(key, "."); ok {
+		section = strings.ToLower(section)
+		if !knownSections[section] {
+			l.cfg.addIssue(pos.source, pos.line, "unknown section %s in %s", section, key)
+			return "", "", false
+		}
+		if _, ok := fieldFor(section, name); !ok {
+			l.cfg.unknownKey(pos.source, pos.line, section, name)
+			return "", "", false
+		}
+		return section, name, true
+	}
+
+	var sections []string
+	for _, f := range Fields() {
+		if f.Matches(key) {
+			sections = append(sections, f.Section)
+		}
+	}
+	switch len(sections) {
+	case 0:
+		l.cfg.addIssue(pos.source, pos.line, "unknown key %s in [profile:%s]", key, pos.profile)
+		return "", "", false
+	case 1:
+		return sections[0], key, true
+	}
+	l.cfg.addIssue(pos.source, pos.line, "%s is a key in [%s], write it as SECTION.%s",
+		key, strings.Join(sections, "] and ["), key)
+	return "", "", false
+}
+
+// apply resolves a value and stores it in the setting key names in section
+func (l *loader) apply(section, key, value string, literal bool, pos position) error {
+	cfg := l.cfg
+
+	// Single quoted values are taken literally, everything else may
+	// reference the environment, a file or a command
+	ref := ""
+	if !literal && isReference(value) {
+		ref = value
+		resolved, err := resolveValue(ref)
+		if err != nil {
+			return fmt.Errorf("%s line %d: %s: %w", pos.source, pos.line, key, err)
+		}
+		value = resolved
+	}
+
+	f, ok := fieldFor(section, key)
+	switch {
+	case ok:
+		if !cfg.checkSetting(f, key, value, pos) {
+			return nil // Reported by Lint, the previous value stays in effect
+		}
+		cfg.setReference(f, ref)
+	case section == "":
+		cfg.addIssue(pos.source, pos.line, "%s is outside of a section", key)
+	case knownSections[section]:
+		cfg.unknownKey(pos.source, pos.line, section, key)
+	}
+
+	// Apply value based on section
+	cfg.applyValue(section, key, value)
+	return nil
+}
+
+// parseValue strips an inline comment and quotes from the value part of a line,
+// reporting whether it was single quoted
+func parseValue(raw string) (string, bool) {
+	value := strings.TrimSpace(raw)
+
+	// Remove inline comments
+	if idx := strings.Index(value, "#"); idx != -1 {
+		// Make sure it's not inside quotes
+		if !isInQuotes(value, idx) {
+			value = strings.TrimSpace(value[:idx])
+		}
+	}
+
+	literal := strings.HasPrefix(value, "'")
+	return strings.Trim(value, `"'`), literal
 }
 
 // applyValue sets the appropriate config field based on section and key
@@ -204,6 +437,14 @@ func (c *Config) applyLocalBackupValue(key, value string) {
 		c.LocalBackup.EnableVerification = parseBool(value)
 	case "VERIFICATION_DEPTH":
 		c.LocalBackup.VerificationDepth = value
+	case "VERIFY_SAMPLE_FILES":
+		c.LocalBackup.VerifySampleFiles = parseInt(value, c.LocalBackup.VerifySampleFiles)
+	case "CHECK_SUBSETS":
+		c.LocalBackup.CheckSubsets = parseInt(value, c.LocalBackup.CheckSubsets)
+	case "STALE_WARNING_HOURS":
+		c.LocalBackup.StaleWarningHours = parseInt(value, c.LocalBackup.StaleWarningHours)
+	case "STALE_CRITICAL_HOURS":
+		c.LocalBackup.StaleCriticalHours = parseInt(value, c.LocalBackup.StaleCriticalHours)
 	}
 }
 
@@ -219,6 +460,10 @@ func (c *Config) applyCloudSyncValue(key, value string) {
 		c.CloudSync.Retries = parseInt(value, c.CloudSync.Retries)
 	case "BANDWIDTH", "RCLONE_BANDWIDTH":
 		c.CloudSync.Bandwidth = value
+	case "RETRY_DELAY", "RCLONE_RETRY_DELAY":
+		c.CloudSync.RetryDelay = parseInt(value, c.CloudSync.RetryDelay)
+	case "RETRY_MAX_DELAY", "RCLONE_RETRY_MAX_DELAY":
+		c.CloudSync.RetryMaxDelay = parseInt(value, c.CloudSync.RetryMaxDelay)
 	}
 }
 
@@ -260,6 +505,13 @@ func (c *Config) ValidateForCloudSync() error {
 	return nil
 }
 
+// RetryPolicy returns the backoff policy for cloud sync and restore retries
+func (c *CloudSyncConfig) RetryPolicy() util.RetryPolicy {
+	return util.NewRetryPolicy(c.Retries,
+		time.Duration(c.RetryDelay)*time.Second,
+		time.Duration(c.RetryMaxDelay)*time.Second)
+}
+
 // GetPasswordMethod returns which password method is configured
 func (c *Config) GetPasswordMethod() string {
 	if c.LocalBackup.PasswordCommand != "" {
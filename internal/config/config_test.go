@@ -0,0 +1,106 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadIncludeAndProfile(t *testing.T) {
+	path := writeConfig(t, `include=common.ini
+
+[local_backup]
+KEEP_DAILY=10
+include=conf.d/*.ini
+
+[profile:nas]
+KEEP_DAILY=30
+docker.DOCKER_TIMEOUT=900
+TIMEOUT=5
+
+[profile:laptop]
+AUTO_PRUNE=false
+`)
+	dir := filepath.Dir(path)
+	if err := os.WriteFile(filepath.Join(dir, "common.ini"), []byte("[docker]\nDOCKER_STACKS_DIR=/srv/stacks\nDOCKER_TIMEOUT=60\n\n[local_backup]\nKEEP_WEEKLY=8\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "conf.d"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "conf.d", "retention.ini"), []byte("[local_backup]\nKEEP_MONTHLY=3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	fields := make(map[string]Field)
+	for _, f := range Fields() {
+		fields[f.Key] = f
+	}
+
+	// Without a profile only the file and its includes apply
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Docker.StacksDir != "/srv/stacks" || cfg.Docker.Timeout != 60 || cfg.LocalBackup.KeepDaily != 10 ||
+		cfg.LocalBackup.KeepWeekly != 8 || cfg.LocalBackup.KeepMonthly != 3 || cfg.LocalBackup.KeepYearly != 2 {
+		t.Errorf("Unexpected values without profile: %+v %+v", cfg.Docker, cfg.LocalBackup)
+	}
+
+	cfg, err = LoadProfile(path, "nas")
+	if err != nil {
+		t.Fatalf("LoadProfile: %v", err)
+	}
+	if cfg.Profile != "nas" || cfg.LocalBackup.KeepDaily != 30 || cfg.Docker.Timeout != 900 || !cfg.LocalBackup.AutoPrune {
+		t.Errorf("Unexpected values with profile nas: %+v %+v", cfg.Docker, cfg.LocalBackup)
+	}
+
+	origins := map[string]string{
+		"DOCKER_STACKS_DIR": "common.ini:2",
+		"DOCKER_TIMEOUT":    "config.ini:9 [profile:nas]",
+		"KEEP_DAILY":        "config.ini:8 [profile:nas]",
+		"KEEP_MONTHLY":      filepath.Join("conf.d", "retention.ini") + ":2",
+		"KEEP_YEARLY":       "default",
+	}
+	for key, want := range origins {
+		if got := cfg.Origin(fields[key]); got != want {
+			t.Errorf("Origin(%s) = %q, want %q", key, got, want)
+		}
+	}
+	if got := cfg.Overridden(fields["KEEP_WEEKLY"]); got != "common.ini:6" {
+		t.Errorf("Overridden(KEEP_WEEKLY) = %q", got)
+	}
+	if got := cfg.Overridden(fields["KEEP_YEARLY"]); got != "" {
+		t.Errorf("Overridden(KEEP_YEARLY) = %q, defaults are editable", got)
+	}
+
+	var issues []string
+	for _, issue := range cfg.Lint() {
+		issues = append(issues, issue.String())
+	}
+	want := "config.ini:10: TIMEOUT is a key in [docker] and [local_backup], write it as SECTION.TIMEOUT"
+	if strings.Join(issues, "\n") != want {
+		t.Errorf("Lint() = %v, want %q", issues, want)
+	}
+
+	if _, err := LoadProfile(path, "office"); err == nil || !strings.Contains(err.Error(), `profile "office" not found`) {
+		t.Errorf("Expected an error for an unknown profile, got %v", err)
+	}
+}
+
+func TestLoadIncludeErrors(t *testing.T) {
+	path := writeConfig(t, "[docker]\ninclude=loop.ini\n")
+	dir := filepath.Dir(path)
+	if err := os.WriteFile(filepath.Join(dir, "loop.ini"), []byte("include=config.ini\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Errorf("Expected an include cycle error, got %v", err)
+	}
+
+	path = writeConfig(t, "include=missing.ini\n")
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), "config.ini line 1: cannot open included file") {
+		t.Errorf("Expected a missing include error, got %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FieldKind is the type of value a Field holds
+type FieldKind int
+
+const (
+	KindString FieldKind = iota
+	KindInt
+	KindBool
+	KindChoice
+)
+
+// Field describes one setting of config.ini that can be edited
+type Field struct {
+	Section string   // INI section without brackets
+	Key     string   // Key written when the setting is not in the file yet
+	Aliases []string // Other keys accepted for the same setting
+	Label   string
+	Help    string
+	Kind    FieldKind
+	Choices []string // Allowed values of a KindChoice field
+	Secret  bool     // Mask the value when it is displayed
+
+	str  func(*Config) *string
+	num  func(*Config) *int
+	flag func(*Config) *bool
+}
+
+// Matches reports whether key names this setting
+func (f Field) Matches(key string) bool {
+	if strings.EqualFold(key, f.Key) {
+		return true
+	}
+	for _, alias := range f.Aliases {
+		if strings.EqualFold(key, alias) {
+			return true
+		}
+	}
+	return false
+}
+
+// Value returns the setting of c as it is written to the file
+func (f Field) Value(c *Config) string {
+	switch {
+	case f.num != nil:
+		return strconv.Itoa(*f.num(c))
+	case f.flag != nil:
+		return strconv.FormatBool(*f.flag(c))
+	default:
+		return *f.str(c)
+	}
+}
+
+// Set parses value according to the field kind and stores it in c
+func (f Field) Set(c *Config, value string) error {
+	value = strings.TrimSpace(value)
+	switch f.Kind {
+	case KindInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s must be a whole number", f.Key)
+		}
+		if n < 0 {
+			return fmt.Errorf("%s must not be negative", f.Key)
+		}
+		*f.num(c) = n
+	case KindBool:
+		switch strings.ToLower(value) {
+		case "true", "yes", "1", "on":
+			*f.flag(c) = true
+		case "false", "no", "0", "off":
+			*f.flag(c) = false
+		default:
+			return fmt.Errorf("%s must be true or false", f.Key)
+		}
+	case KindChoice:
+		for _, choice := range f.Choices {
+			if strings.EqualFold(value, choice) {
+				*f.str(c) = choice
+				return nil
+			}
+		}
+		return fmt.Errorf("%s must be one of: %s", f.Key, strings.Join(f.Choices, ", "))
+	default:
+		*f.str(c) = value
+	}
+	return nil
+}
+
+// Fields returns the editable settings of the [docker], [local_backup]
+// and [cloud_sync] sections in file order
+func Fields() []Field {
+	return []Field{
+		{Section: "docker", Key: "DOCKER_STACKS_DIR", Aliases: []string{"STACKS_DIR"}, Label: "Stacks directory",
+			Help: "Directory containing the Docker compose stacks", str: func(c *Config) *string { return &c.Docker.StacksDir }},
+		{Section: "docker", Key: "DOCKER_TIMEOUT", Aliases: []string{"TIMEOUT"}, Label: "Compose timeout", Kind: KindInt,
+			Help: "Seconds to wait for docker compose down/up", num: func(c *Config) *int { return &c.Docker.Timeout }},
+
+		{Section: "local_backup", Key: "RESTIC_REPOSITORY", Aliases: []string{"REPOSITORY"}, Label: "Repository",
+			Help: "Local restic repository path (or sftp:, s3:, ...)", str: func(c *Config) *string { return &c.LocalBackup.Repository }},
+		{Section: "local_backup", Key: "RESTIC_PASSWORD", Aliases: []string{"PASSWORD"}, Label: "Password", Secret: true,
+			Help: "Inline repository password (a password file or command is safer)", str: func(c *Config) *string { return &c.LocalBackup.Password }},
+		{Section: "local_backup", Key: "PASSWORD_FILE", Aliases: []string{"RESTIC_PASSWORD_FILE"}, Label: "Password file",
+			Help: "File containing the repository password", str: func(c *Config) *string { return &c.LocalBackup.PasswordFile }},
+		{Section: "local_backup", Key: "PASSWORD_COMMAND", Aliases: []string{"RESTIC_PASSWORD_COMMAND"}, Label: "Password command",
+			Help: "Command printing the repository password", str: func(c *Config) *string { return &c.LocalBackup.PasswordCommand }},
+		{Section: "local_backup", Key: "BACKUP_TIMEOUT", Aliases: []string{"TIMEOUT"}, Label: "Backup timeout", Kind: KindInt,
+			Help: "Seconds a single restic backup may take", num: func(c *Config) *int { return &c.LocalBackup.Timeout }},
+		{Section: "local_backup", Key: "HOSTNAME", Label: "Hostname",
+			Help: "Hostname recorded in snapshots (empty: system hostname)", str: func(c *Config) *string { return &c.LocalBackup.Hostname }},
+		{Section: "local_backup", Key: "KEEP_DAILY", Label: "Keep daily", Kind: KindInt,
+			Help: "Daily snapshots kept by the retention policy", num: func(c *Config) *int { return &c.LocalBackup.KeepDaily }},
+		{Section: "local_backup", Key: "KEEP_WEEKLY", Label: "Keep weekly", Kind: KindInt,
+			Help: "Weekly snapshots kept by the retention policy", num: func(c *Config) *int { return &c.LocalBackup.KeepWeekly }},
+		{Section: "local_backup", Key: "KEEP_MONTHLY", Label: "Keep monthly", Kind: KindInt,
+			Help: "Monthly snapshots kept by the retention policy", num: func(c *Config) *int { return &c.LocalBackup.KeepMonthly }},
+		{Section: "local_backup", Key: "KEEP_YEARLY", Label: "Keep yearly", Kind: KindInt,
+			Help: "Yearly snapshots kept by the retention policy", num: func(c *Config) *int { return &c.LocalBackup.KeepYearly }},
+		{Section: "local_backup", Key: "AUTO_PRUNE", Label: "Auto prune", Kind: KindBool,
+			Help: "Prune old snapshots after a successful backup", flag: func(c *Config) *bool { return &c.LocalBackup.AutoPrune }},
+		{Section: "local_backup", Key: "ENABLE_VERIFICATION", Aliases: []string{"ENABLE_BACKUP_VERIFICATION"}, Label: "Verify backups", Kind: KindBool,
+			Help: "Check the repository after each backup", flag: func(c *Config) *bool { return &c.LocalBackup.EnableVerification }},
+		{Section: "local_backup", Key: "VERIFICATION_DEPTH", Label: "Verification depth", Kind: KindChoice, Choices: []string{"metadata", "files", "data"},
+			Help: "metadata (fast), files (medium) or data (slow but thorough)", str: func(c *Config) *string { return &c.LocalBackup.VerificationDepth }},
+		{Section: "local_backup", Key: "VERIFY_SAMPLE_FILES", Label: "Verify sample", Kind: KindInt,
+			Help: "Files the files depth restores and compares with the stack (0: all)", num: func(c *Config) *int { return &c.LocalBackup.VerifySampleFiles }},
+		{Section: "local_backup", Key: "CHECK_SUBSETS", Label: "Check slices", Kind: KindInt,
+			Help: "Slices of pack data the check command reads in turn, one per run", num: func(c *Config) *int { return &c.LocalBackup.CheckSubsets }},
+		{Section: "local_backup", Key: "STALE_WARNING_HOURS", Label: "Stale warning", Kind: KindInt,
+			Help: "Hours after the last snapshot before a stack is shown as stale", num: func(c *Config) *int { return &c.LocalBackup.StaleWarningHours }},
+		{Section: "local_backup", Key: "STALE_CRITICAL_HOURS", Label: "Stale critical", Kind: KindInt,
+			Help: "Hours after the last snapshot before a stack is shown as critical", num: func(c *Config) *int { return &c.LocalBackup.StaleCriticalHours }},
+
+		{Section: "cloud_sync", Key: "RCLONE_REMOTE", Aliases: []string{"REMOTE"}, Label: "Remote",
+			Help: "Rclone remote name, see 'rclone listremotes'", str: func(c *Config) *string { return &c.CloudSync.Remote }},
+		{Section: "cloud_sync", Key: "RCLONE_PATH", Aliases: []string{"PATH"}, Label: "Remote path",
+			Help: "Path on the remote where the repository is stored", str: func(c *Config) *string { return &c.CloudSync.Path }},
+		{Section: "cloud_sync", Key: "TRANSFERS", Aliases: []string{"RCLONE_TRANSFERS"}, Label: "Transfers", Kind: KindInt,
+			Help: "Concurrent file transfers", num: func(c *Config) *int { return &c.CloudSync.Transfers }},
+		{Section: "cloud_sync", Key: "RETRIES", Aliases: []string{"RCLONE_RETRIES"}, Label: "Retries", Kind: KindInt,
+			Help: "Attempts before a sync or restore fails", num: func(c *Config) *int { return &c.CloudSync.Retries }},
+		{Section: "cloud_sync", Key: "RETRY_DELAY", Aliases: []string{"RCLONE_RETRY_DELAY"}, Label: "Retry delay", Kind: KindInt,
+			Help: "Seconds before the first retry, doubled per attempt", num: func(c *Config) *int { return &c.CloudSync.RetryDelay }},
+		{Section: "cloud_sync", Key: "RETRY_MAX_DELAY", Aliases: []string{"RCLONE_RETRY_MAX_DELAY"}, Label: "Retry max delay", Kind: KindInt,
+			Help: "Upper bound in seconds for a single retry wait", num: func(c *Config) *int { return &c.CloudSync.RetryMaxDelay }},
+		{Section: "cloud_sync", Key: "BANDWIDTH", Aliases: []string{"RCLONE_BANDWIDTH"}, Label: "Bandwidth limit",
+			Help: "Rclone bandwidth limit such as 10M or 500k (empty: unlimited)", str: func(c *Config) *string { return &c.CloudSync.Bandwidth }},
+	}
+}
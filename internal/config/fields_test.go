@@ -0,0 +1,73 @@
+package config
+
+import "testing"
+
+func TestFieldsMatchLoader(t *testing.T) {
+	for _, f := range Fields() {
+		value := "7"
+		switch f.Kind {
+		case KindBool:
+			value = "false"
+		case KindChoice:
+			value = f.Choices[len(f.Choices)-1]
+		case KindString:
+			value = "/some/" + f.Key
+		}
+
+		for _, key := range append([]string{f.Key}, f.Aliases...) {
+			cfg := DefaultConfig()
+			if f.Kind == KindBool {
+				// Defaults may already be false, start from true to see the change
+				if err := f.Set(cfg, "true"); err != nil {
+					t.Fatalf("%s: %v", f.Key, err)
+				}
+			}
+			cfg.applyValue(f.Section, key, value)
+			if got := f.Value(cfg); got != value {
+				t.Errorf("[%s] %s: loader stored %q, field reads %q", f.Section, key, value, got)
+			}
+		}
+	}
+}
+
+func TestFieldSet(t *testing.T) {
+	fields := make(map[string]Field)
+	for _, f := range Fields() {
+		fields[f.Section+"."+f.Key] = f
+	}
+	cfg := DefaultConfig()
+
+	tests := []struct {
+		field   string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"local_backup.KEEP_DAILY", " 14 ", "14", false},
+		{"local_backup.KEEP_DAILY", "-1", "", true},
+		{"local_backup.KEEP_DAILY", "many", "", true},
+		{"local_backup.AUTO_PRUNE", "off", "false", false},
+		{"local_backup.AUTO_PRUNE", "maybe", "", true},
+		{"local_backup.VERIFICATION_DEPTH", "Files", "files", false},
+		{"local_backup.VERIFICATION_DEPTH", "everything", "", true},
+		{"cloud_sync.BANDWIDTH", "10M", "10M", false},
+	}
+
+	for _, tt := range tests {
+		f := fields[tt.field]
+		err := f.Set(cfg, tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s=%q: expected error", tt.field, tt.value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s=%q: %v", tt.field, tt.value, err)
+			continue
+		}
+		if got := f.Value(cfg); got != tt.want {
+			t.Errorf("%s=%q: got %q, want %q", tt.field, tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// knownSections are the sections Load reads settings from
+var knownSections = map[string]bool{"docker": true, "local_backup": true, "cloud_sync": true}
+
+// Issue is a problem in the configuration that Load works around, such as
+// an unknown key or a value that is not a number
+type Issue struct {
+	Source  string // Config file name, include path or environment variable, empty for defaults
+	Line    int    // 0 when the problem is not tied to a line
+	Message string
+}
+
+func (i Issue) String() string {
+	switch {
+	case i.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", i.Source, i.Line, i.Message)
+	case i.Source != "":
+		return i.Source + ": " + i.Message
+	}
+	return i.Message
+}
+
+// position is where the effective value of a setting was read
+type position struct {
+	source  string
+	line    int
+	profile string // Profile section the line is in
+	invalid bool   // The value could not be parsed, the default was kept
+}
+
+// addIssue records a problem found while loading
+func (c *Config) addIssue(source string, line int, format string, args ...any) {
+	c.issues = append(c.issues, Issue{Source: source, Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// checkSetting records where f was set and whether value parses as f expects,
+// reporting false for a value the caller must not apply
+func (c *Config) checkSetting(f Field, key, value string, pos position) bool {
+	id := f.Section + "." + f.Key
+	if c.positions == nil {
+		c.positions = make(map[string]position)
+	}
+	prev, set := c.positions[id]
+
+	// Parse into a scratch config with Field.Set, which is strict where the loader falls back
+	if err := f.Set(DefaultConfig(), value); err != nil {
+		c.addIssue(pos.source, pos.line, "%v, got %q", err, value)
+		if !set {
+			pos.invalid = true
+			c.positions[id] = pos
+		}
+		return false
+	}
+
+	if set && prev.line > 0 && pos.line > 0 && prev.source == pos.source && prev.profile == pos.profile {
+		c.addIssue(pos.source, pos.line, "%s is also set on line %d, this value wins", key, prev.line)
+	}
+	c.positions[id] = pos
+	return true
+}
+
+// unknownKey records a key that is not a setting of section, suggesting a close match
+func (c *Config) unknownKey(source string, line int, section, key string) {
+	best, bestDist := "", 3
+	for _, f := range Fields() {
+		if f.Section != section {
+			continue
+		}
+		if d := editDistance(strings.ToUpper(key), f.Key); d < bestDist {
+			best, bestDist = f.Key, d
+		}
+	}
+	if best != "" {
+		c.addIssue(source, line, "unknown key %s in [%s], did you mean %s?", key, section, best)
+		return
+	}
+	c.addIssue(source, line, "unknown key %s in [%s]", key, section)
+}
+
+// Lint returns the problems found while loading and settings outside their
+// useful range. Load still succeeds with these; strict mode refuses to run.
+func (c *Config) Lint() []Issue {
+	issues := append([]Issue(nil), c.issues...)
+
+	check := func(section, key string, ok bool, format string, args ...any) {
+		pos := c.positions[section+"."+key]
+		if ok || pos.invalid {
+			return
+		}
+		issues = append(issues, Issue{Source: pos.source, Line: pos.line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	lb, cs := c.LocalBackup, c.CloudSync
+	check("docker", "DOCKER_TIMEOUT", c.Docker.Timeout >= 1, "DOCKER_TIMEOUT must be at least 1 second")
+	check("local_backup", "BACKUP_TIMEOUT", lb.Timeout >= 1, "BACKUP_TIMEOUT must be at least 1 second")
+	check("local_backup", "KEEP_DAILY", !lb.AutoPrune || lb.KeepDaily+lb.KeepWeekly+lb.KeepMonthly+lb.KeepYearly > 0,
+		"all KEEP_* values are 0, AUTO_PRUNE would have no retention policy to apply")
+	check("local_backup", "VERIFY_SAMPLE_FILES", lb.VerifySampleFiles >= 0, "VERIFY_SAMPLE_FILES must not be negative, 0 compares all files")
+	check("local_backup", "CHECK_SUBSETS", lb.CheckSubsets >= 1, "CHECK_SUBSETS must be at least 1")
+	check("local_backup", "STALE_WARNING_HOURS", lb.StaleWarningHours >= 1, "STALE_WARNING_HOURS must be at least 1")
+	check("local_backup", "STALE_CRITICAL_HOURS", lb.StaleCriticalHours > lb.StaleWarningHours,
+		"STALE_CRITICAL_HOURS (%d) must be greater than STALE_WARNING_HOURS (%d)", lb.StaleCriticalHours, lb.StaleWarningHours)
+	check("cloud_sync", "TRANSFERS", cs.Transfers >= 1, "TRANSFERS must be at least 1")
+	check("cloud_sync", "RETRIES", cs.Retries >= 1, "RETRIES must be at least 1, it counts the first attempt")
+	check("cloud_sync", "RETRY_MAX_DELAY", cs.RetryMaxDelay >= cs.RetryDelay,
+		"RETRY_MAX_DELAY (%d) must not be below RETRY_DELAY (%d)", cs.RetryMaxDelay, cs.RetryDelay)
+
+	return issues
+}
+
+// editDistance is the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	path := writeConfig(t, `KEEP_HOURLY=1
+[docker]
+DOCKER_STACKS_DIR=/srv/stacks
+DOCKER_TIMEOUT=soon
+
+[local_backup]
+RESTIC_REPOSITORY=/srv/repo
+KEEP_DAILLY=14
+KEEP_WEEKLY=-2
+AUTO_PRUNE=maybe
+VERIFICATION_DEPTH=everything
+STALE_WARNING_HOURS=48
+STALE_CRITICAL_HOURS=24
+this line has no value
+
+[cloud_sync]
+TRANSFERS=0
+TRANSFERS=8
+RETRY_DELAY=60
+RETRY_MAX_DELAY=30
+
+[clowd_sync]
+REMOTE=b2
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	want := []string{
+		"config.ini:1: KEEP_HOURLY is outside of a section",
+		`config.ini:4: DOCKER_TIMEOUT must be a whole number, got "soon"`,
+		"config.ini:8: unknown key KEEP_DAILLY in [local_backup], did you mean KEEP_DAILY?",
+		`config.ini:9: KEEP_WEEKLY must not be negative, got "-2"`,
+		`config.ini:10: AUTO_PRUNE must be true or false, got "maybe"`,
+		`config.ini:11: VERIFICATION_DEPTH must be one of: metadata, files, data, got "everything"`,
+		`config.ini:14: expected KEY=VALUE, got "this line has no value"`,
+		"config.ini:18: TRANSFERS is also set on line 17, this value wins",
+		"config.ini:22: unknown section [clowd_sync], its settings are ignored",
+		"config.ini:13: STALE_CRITICAL_HOURS (24) must be greater than STALE_WARNING_HOURS (48)",
+		"config.ini:20: RETRY_MAX_DELAY (30) must not be below RETRY_DELAY (60)",
+	}
+	var got []string
+	for _, issue := range cfg.Lint() {
+		got = append(got, issue.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Lint() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// Problems are reported, loading still falls back as before
+	if cfg.Docker.Timeout != 300 || cfg.LocalBackup.KeepDaily != 7 || cfg.CloudSync.Transfers != 8 {
+		t.Errorf("Unexpected fallback values: %+v %+v", cfg.Docker, cfg.CloudSync)
+	}
+}
+
+func TestRejectedValueKeepsDefault(t *testing.T) {
+	t.Setenv("BACKUP_CLOUD_SYNC_TRANSFERS", "many")
+
+	cfg, err := Load(writeConfig(t, `[docker]
+DOCKER_STACKS_DIR=/srv/stacks
+DOCKER_TIMEOUT=-5
+
+[local_backup]
+KEEP_WEEKLY=-2
+AUTO_PRUNE=maybe
+VERIFICATION_DEPTH=everything
+KEEP_DAILY=9
+KEEP_DAILY=soon
+
+[cloud_sync]
+TRANSFERS=2
+`))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	def := DefaultConfig()
+	lb := cfg.LocalBackup
+	if cfg.Docker.Timeout != def.Docker.Timeout || lb.KeepWeekly != def.LocalBackup.KeepWeekly ||
+		lb.AutoPrune != def.LocalBackup.AutoPrune || lb.VerificationDepth != def.LocalBackup.VerificationDepth {
+		t.Errorf("Rejected values were applied: %+v %+v", cfg.Docker, lb)
+	}
+	// The last valid value stays in effect, also under an invalid override
+	if lb.KeepDaily != 9 || cfg.CloudSync.Transfers != 2 {
+		t.Errorf("KEEP_DAILY = %d, TRANSFERS = %d, want 9 and 2", lb.KeepDaily, cfg.CloudSync.Transfers)
+	}
+	if len(cfg.Lint()) != 6 {
+		t.Errorf("Lint() = %v, want the six rejected values", cfg.Lint())
+	}
+	fields := make(map[string]Field)
+	for _, f := range Fields() {
+		fields[f.Key] = f
+	}
+	if got := cfg.Origin(fields["DOCKER_TIMEOUT"]); got != "default (config.ini:3 is invalid)" {
+		t.Errorf("Origin(DOCKER_TIMEOUT) = %q", got)
+	}
+	if got := cfg.Origin(fields["KEEP_DAILY"]); got != "config.ini:9" {
+		t.Errorf("Origin(KEEP_DAILY) = %q", got)
+	}
+}
+
+func TestLintClean(t *testing.T) {
+	t.Setenv("BACKUP_LOCAL_BACKUP_KEEP_DAILY", "x")
+
+	cfg, err := Load(writeConfig(t, "[docker]\nDOCKER_STACKS_DIR=/srv/stacks\n\n[local_backup]\nKEEP_DAILY=3\n"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	issues := cfg.Lint()
+	if len(issues) != 1 || issues[0].String() != `BACKUP_LOCAL_BACKUP_KEEP_DAILY: KEEP_DAILY must be a whole number, got "x"` {
+		t.Errorf("Lint() = %v, want only the environment problem", issues)
+	}
+
+	// Values set in code are checked too
+	cfg.LocalBackup.VerifySampleFiles = -1
+	if issues := cfg.Lint(); len(issues) != 2 || !strings.Contains(issues[1].String(), "VERIFY_SAMPLE_FILES must not be negative") {
+		t.Errorf("Lint() = %v, want the negative sample size", issues)
+	}
+}
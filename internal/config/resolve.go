@@ -0,0 +1,177 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"backup-tui/internal/util"
+)
+
+// EnvPrefix starts the name of an environment variable that overrides a
+// setting, followed by the section and the key: BACKUP_LOCAL_BACKUP_KEEP_DAILY
+const EnvPrefix = "BACKUP_"
+
+// commandTimeout bounds a cmd: value
+const commandTimeout = 30 * time.Second
+
+// envReference matches ${NAME} and ${NAME:-default}
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// resolveValue expands a value read from the config file. A value starting
+// with file: is replaced by the contents of that file and one starting with
+// cmd: by the output of the shell command; ${NAME} references to environment
+// variables are expanded in all of them.
+func resolveValue(value string) (string, error) {
+	expanded, err := expandEnv(value)
+	if err != nil {
+		return "", err
+	}
+	return resolvePrefix(expanded)
+}
+
+// expandEnv replaces ${NAME} and ${NAME:-default} with the environment variable NAME
+func expandEnv(value string) (string, error) {
+	var missing []string
+	expanded := envReference.ReplaceAllStringFunc(value, func(ref string) string {
+		m := envReference.FindStringSubmatch(ref)
+		if v, ok := os.LookupEnv(m[1]); ok && (v != "" || m[2] == "") {
+			return v
+		}
+		if m[2] != "" {
+			return m[3]
+		}
+		missing = append(missing, m[1])
+		return ""
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable %s is not set", strings.Join(missing, ", "))
+	}
+	return expanded, nil
+}
+
+// resolvePrefix reads the secret a file: or cmd: value points to
+func resolvePrefix(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, "file:"):
+		path := strings.TrimSpace(strings.TrimPrefix(value, "file:"))
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("cannot read %s: %w", path, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+
+	case strings.HasPrefix(value, "cmd:"):
+		command := strings.TrimSpace(strings.TrimPrefix(value, "cmd:"))
+		ctx, cancel := context.WithTimeout(context.Background(), commandTimeout)
+		defer cancel()
+		result, err := util.RunCommand(ctx, "sh", []string{"-c", command}, util.DefaultOptions())
+		if err != nil {
+			return "", fmt.Errorf("command %q failed: %w", command, err)
+		}
+		if !result.IsSuccess() {
+			return "", fmt.Errorf("command %q exited with code %d: %s", command, result.ExitCode, strings.TrimSpace(result.Stderr))
+		}
+		return strings.TrimRight(result.Stdout, "\r\n"), nil
+	}
+	return value, nil
+}
+
+// isReference reports whether a raw value is resolved rather than used as written
+func isReference(value string) bool {
+	return strings.HasPrefix(value, "file:") || strings.HasPrefix(value, "cmd:") || envReference.MatchString(value)
+}
+
+// EnvName returns the environment variable that overrides the setting under key
+func (f Field) EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(f.Section) + "_" + strings.ToUpper(key)
+}
+
+// applyEnvOverrides applies settings given as BACKUP_<SECTION>_<KEY>
+// environment variables; the key may be any of the names the file accepts.
+func (c *Config) applyEnvOverrides() error {
+	for _, f := range Fields() {
+		for _, key := range append([]string{f.Key}, f.Aliases...) {
+			name := f.EnvName(key)
+			raw, ok := os.LookupEnv(name)
+			if !ok {
+				continue
+			}
+			value, err := resolvePrefix(raw)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			if c.checkSetting(f, name, value, position{source: name}) {
+				c.applyValue(f.Section, f.Key, value)
+				c.setReference(f, "$"+name)
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// setReference remembers where the value of f came from, an empty ref marks a literal value
+func (c *Config) setReference(f Field, ref string) {
+	if ref == "" {
+		delete(c.references, f.Section+"."+f.Key)
+		return
+	}
+	if c.references == nil {
+		c.references = make(map[string]string)
+	}
+	c.references[f.Section+"."+f.Key] = ref
+}
+
+// Reference returns the reference the value of f was resolved from: the raw
+// file:, cmd: or ${NAME} value, or the overriding environment variable.
+// It is empty for literal values.
+func (c *Config) Reference(f Field) string {
+	return c.references[f.Section+"."+f.Key]
+}
+
+// Origin describes where the effective value of f was set: "default", the
+// file and line such as "config.ini:12" followed by the profile if the line
+// is in one, or the overriding environment variable
+func (c *Config) Origin(f Field) string {
+	pos, ok := c.positions[f.Section+"."+f.Key]
+	if !ok {
+		return "default"
+	}
+	origin := "$" + pos.source
+	if pos.line > 0 {
+		origin = fmt.Sprintf("%s:%d", pos.source, pos.line)
+	}
+	if pos.profile != "" {
+		origin += " [profile:" + pos.profile + "]"
+	}
+	if pos.invalid {
+		return "default (" + origin + " is invalid)"
+	}
+	return origin
+}
+
+// Overridden returns the origin of f when its value is not set in the
+// [section] of the config file itself, so writing that section would not
+// change the effective value. It is empty otherwise.
+func (c *Config) Overridden(f Field) string {
+	pos, ok := c.positions[f.Section+"."+f.Key]
+	if !ok || (pos.line > 0 && pos.profile == "" && pos.source == filepath.Base(c.ConfigFile)) {
+		return ""
+	}
+	return c.Origin(f)
+}
+
+// fieldFor returns the field that key names in section
+func fieldFor(section, key string) (Field, bool) {
+	for _, f := range Fields() {
+		if f.Section == section && f.Matches(key) {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes content to config/config.ini below a temporary directory
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "config")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config.ini")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadReferences(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(secret, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_STACKS", "/srv/stacks")
+	t.Setenv("TEST_SECRET_PATH", secret)
+
+	path := writeConfig(t, `[docker]
+DOCKER_STACKS_DIR=${TEST_STACKS}/prod
+DOCKER_TIMEOUT=${TEST_UNSET_TIMEOUT:-120}
+
+[local_backup]
+RESTIC_REPOSITORY='/literal/${TEST_STACKS}'
+RESTIC_PASSWORD=file:${TEST_SECRET_PATH}
+HOSTNAME=cmd:printf 'host-%s' one
+
+[cloud_sync]
+RCLONE_REMOTE=plain
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if cfg.Docker.StacksDir != "/srv/stacks/prod" {
+		t.Errorf("StacksDir = %q", cfg.Docker.StacksDir)
+	}
+	if cfg.Docker.Timeout != 120 {
+		t.Errorf("Timeout = %d, want the default of the reference", cfg.Docker.Timeout)
+	}
+	if cfg.LocalBackup.Repository != "/literal/${TEST_STACKS}" {
+		t.Errorf("Repository = %q, single quotes should keep the value literal", cfg.LocalBackup.Repository)
+	}
+	if cfg.LocalBackup.Password != "from-file" {
+		t.Errorf("Password = %q", cfg.LocalBackup.Password)
+	}
+	if cfg.LocalBackup.Hostname != "host-one" {
+		t.Errorf("Hostname = %q", cfg.LocalBackup.Hostname)
+	}
+
+	refs := make(map[string]string)
+	for _, f := range Fields() {
+		if ref := cfg.Reference(f); ref != "" {
+			refs[f.Key] = ref
+		}
+	}
+	want := map[string]string{
+		"DOCKER_STACKS_DIR": "${TEST_STACKS}/prod",
+		"DOCKER_TIMEOUT":    "${TEST_UNSET_TIMEOUT:-120}",
+		"RESTIC_PASSWORD":   "file:${TEST_SECRET_PATH}",
+		"HOSTNAME":          "cmd:printf 'host-%s' one",
+	}
+	if len(refs) != len(want) {
+		t.Errorf("References = %v, want %v", refs, want)
+	}
+	for key, ref := range want {
+		if refs[key] != ref {
+			t.Errorf("Reference of %s = %q, want %q", key, refs[key], ref)
+		}
+	}
+}
+
+func TestLoadEnvOverrides(t *testing.T) {
+	t.Setenv("BACKUP_LOCAL_BACKUP_KEEP_DAILY", "14")
+	t.Setenv("BACKUP_DOCKER_STACKS_DIR", "/env/stacks") // Alias of DOCKER_STACKS_DIR
+	t.Setenv("BACKUP_CLOUD_SYNC_RCLONE_REMOTE", "cmd:echo env-remote")
+
+	path := writeConfig(t, `[docker]
+DOCKER_STACKS_DIR=/file/stacks
+
+[local_backup]
+KEEP_DAILY=3
+
+[cloud_sync]
+RCLONE_REMOTE=file-remote
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if cfg.LocalBackup.KeepDaily != 14 {
+		t.Errorf("KeepDaily = %d, want 14", cfg.LocalBackup.KeepDaily)
+	}
+	if cfg.Docker.StacksDir != "/env/stacks" {
+		t.Errorf("StacksDir = %q", cfg.Docker.StacksDir)
+	}
+	if cfg.CloudSync.Remote != "env-remote" {
+		t.Errorf("Remote = %q", cfg.CloudSync.Remote)
+	}
+
+	for _, f := range Fields() {
+		if f.Key == "KEEP_DAILY" {
+			if ref := cfg.Reference(f); ref != "$BACKUP_LOCAL_BACKUP_KEEP_DAILY" {
+				t.Errorf("Reference of KEEP_DAILY = %q", ref)
+			}
+		}
+	}
+}
+
+func TestLoadReferenceErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"unset variable", "[local_backup]\n\nRESTIC_PASSWORD=${TEST_DOES_NOT_EXIST}\n", "line 3: RESTIC_PASSWORD: environment variable TEST_DOES_NOT_EXIST is not set"},
+		{"missing file", "[local_backup]\nRESTIC_PASSWORD=file:/does/not/exist\n", "line 2: RESTIC_PASSWORD: cannot read /does/not/exist"},
+		{"failing command", "[local_backup]\nRESTIC_PASSWORD=cmd:exit 3\n", "exited with code 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Template is the commented config.ini written by generate-config and init
+const Template = `# Backup TUI - Unified Configuration
+# Docker Stack 3-Stage Backup System
+
+#===========================================
+# [docker] - Docker Stacks Configuration
+#===========================================
+[docker]
+# Directory containing Docker compose stacks to backup
+DOCKER_STACKS_DIR=/opt/docker-stacks
+
+# Timeout for docker compose commands (seconds)
+DOCKER_TIMEOUT=300
+
+#===========================================
+# [local_backup] - Local Restic Repository
+#===========================================
+[local_backup]
+# Local restic repository path
+RESTIC_REPOSITORY=/mnt/backup/restic-repo
+
+# Password (choose one method)
+RESTIC_PASSWORD=your-secure-password
+# PASSWORD_FILE=/path/to/password-file
+# PASSWORD_COMMAND="pass show backup"
+
+# Backup timeout (seconds)
+BACKUP_TIMEOUT=3600
+
+# Custom hostname for snapshots (optional)
+# HOSTNAME=my-server
+
+# Retention policy
+KEEP_DAILY=7
+KEEP_WEEKLY=4
+KEEP_MONTHLY=6
+KEEP_YEARLY=2
+AUTO_PRUNE=true
+
+# Verification (metadata|files|data)
+ENABLE_VERIFICATION=true
+VERIFICATION_DEPTH=metadata
+
+# Files the files depth restores and compares with the stack directory (0: all)
+VERIFY_SAMPLE_FILES=50
+
+# The check command reads 1/CHECK_SUBSETS of the pack data per run, a
+# different slice each time (1: read everything on every run)
+CHECK_SUBSETS=12
+
+#===========================================
+# [cloud_sync] - Remote Cloud Storage
+#===========================================
+[cloud_sync]
+# Rclone remote name (from rclone config)
+RCLONE_REMOTE=backblaze
+
+# Remote path for backup storage
+RCLONE_PATH=/backup/restic
+
+# Concurrent transfers
+TRANSFERS=4
+
+# Retry attempts with exponential backoff and jitter
+RETRIES=3
+
+# Backoff: first retry delay and maximum delay (seconds)
+# RETRY_DELAY=30
+# RETRY_MAX_DELAY=600
+
+# Bandwidth limit (optional, e.g., "10M", "1G")
+# BANDWIDTH=10M
+`
+
+// Create writes a new config file at path from Template with the values of
+// fields taken from c; empty ones are commented out. It fails if the file
+// already exists.
+func Create(path string, c *Config, fields []Field) error {
+	lines := strings.Split(Template, "\n")
+	for _, f := range fields {
+		lines = setLine(lines, f, f.Value(c))
+	}
+	// Settings left empty stay in the file as commented out examples
+	for i, line := range lines {
+		if key, value, ok := strings.Cut(line, "="); ok && value == "" && !strings.HasPrefix(key, "#") {
+			lines[i] = "# " + line
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("cannot create config file: %w", err)
+	}
+	if _, err := file.WriteString(strings.Join(lines, "\n")); err != nil {
+		file.Close()
+		return fmt.Errorf("cannot write config file: %w", err)
+	}
+	return file.Close()
+}
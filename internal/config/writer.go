@@ -0,0 +1,159 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// commentedKey matches a commented out KEY=VALUE line such as "# PASSWORD_FILE=/etc/..."
+var commentedKey = regexp.MustCompile(`^#\s*([A-Za-z_][A-Za-z0-9_]*)\s*=`)
+
+// SaveFields writes the values of fields from c into the config file at path.
+// Existing lines are updated in place, keeping their key spelling, quotes and
+// inline comments; settings missing from the file are added to their section,
+// below a commented out example of the key if there is one. Comments, unknown
+// keys and all other lines are left untouched.
+func SaveFields(path string, c *Config, fields []Field) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read config file: %w", err)
+	}
+	lines := strings.Split(string(data), "\n")
+
+	for _, f := range fields {
+		lines = setLine(lines, f, f.Value(c))
+	}
+
+	return writeFileAtomic(path, []byte(strings.Join(lines, "\n")))
+}
+
+// setLine updates or inserts the line of one setting
+func setLine(lines []string, f Field, value string) []string {
+	section := ""
+	sectionStart, sectionEnd := -1, -1
+	lastKey, example := -1, -1
+
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			if section == f.Section {
+				sectionEnd = i
+			}
+			section = strings.ToLower(strings.Trim(line, "[]"))
+			if section == f.Section && sectionStart < 0 {
+				sectionStart = i
+			}
+			continue
+		}
+		if section != f.Section || sectionEnd >= 0 {
+			continue
+		}
+
+		if m := commentedKey.FindStringSubmatch(line); m != nil {
+			if example < 0 && f.Matches(m[1]) {
+				example = i
+			}
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if line == "" || strings.HasPrefix(line, "#") || len(parts) != 2 {
+			continue
+		}
+		if f.Matches(strings.TrimSpace(parts[0])) {
+			lines[i] = replaceValue(raw, value)
+			return lines
+		}
+		lastKey = i
+	}
+
+	// Not in the file yet: a missing empty setting needs no line
+	if value == "" {
+		return lines
+	}
+	entry := f.Key + "=" + quoteValue(value, "")
+	switch {
+	case sectionStart < 0:
+		for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+			lines = lines[:len(lines)-1]
+		}
+		return append(lines, "", "["+f.Section+"]", entry, "")
+	case example >= 0:
+		return insertLine(lines, example+1, entry)
+	case lastKey >= 0:
+		return insertLine(lines, lastKey+1, entry)
+	default:
+		return insertLine(lines, sectionStart+1, entry)
+	}
+}
+
+// replaceValue swaps the value of a KEY=VALUE line, keeping the key, quotes and inline comment
+func replaceValue(raw, value string) string {
+	eq := strings.Index(raw, "=")
+	rest := raw[eq+1:]
+
+	// Split off an inline comment the same way Load does
+	comment := ""
+	if idx := strings.Index(rest, "#"); idx != -1 && !isInQuotes(rest, idx) {
+		end := strings.TrimRight(rest[:idx], " \t")
+		comment = rest[len(end):]
+		rest = end
+	}
+
+	old := strings.TrimSpace(rest)
+	quote := ""
+	if len(old) >= 2 && (old[0] == '"' || old[0] == '\'') && old[len(old)-1] == old[0] {
+		quote = old[:1]
+	}
+	lead := rest[:len(rest)-len(strings.TrimLeft(rest, " \t"))]
+
+	return raw[:eq+1] + lead + quoteValue(value, quote) + comment
+}
+
+// quoteValue quotes value with quote, or with double quotes when Load would otherwise misread it
+func quoteValue(value, quote string) string {
+	if quote == "" && (strings.Contains(value, "#") || strings.TrimSpace(value) != value) {
+		quote = `"`
+	}
+	return quote + value + quote
+}
+
+// insertLine inserts line at index i
+func insertLine(lines []string, i int, line string) []string {
+	lines = append(lines, "")
+	copy(lines[i+1:], lines[i:])
+	lines[i] = line
+	return lines
+}
+
+// writeFileAtomic replaces path with data through a temporary file, keeping its permissions
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0o600)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".config-*.tmp")
+	if err != nil {
+		return fmt.Errorf("cannot write config file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cannot write config file: %w", err)
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cannot write config file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cannot write config file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("cannot write config file: %w", err)
+	}
+	return nil
+}
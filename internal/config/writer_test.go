@@ -0,0 +1,100 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveFields(t *testing.T) {
+	original := `# Top comment
+[docker]
+DOCKER_STACKS_DIR=/opt/stacks
+TIMEOUT = 120   # seconds
+
+[local_backup]
+RESTIC_REPOSITORY="/mnt/repo"
+# PASSWORD_FILE=/etc/backup/restic.password
+RESTIC_PASSWORD=secret
+CUSTOM_KEY=kept
+KEEP_DAILY=7
+
+[custom]
+SOMETHING=else
+`
+	path := filepath.Join(t.TempDir(), "config.ini")
+	if err := os.WriteFile(path, []byte(original), 0o640); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	byKey := make(map[string]Field)
+	for _, f := range Fields() {
+		byKey[f.Key] = f
+	}
+	var changed []Field
+	set := func(key, value string) {
+		f := byKey[key]
+		if err := f.Set(cfg, value); err != nil {
+			t.Fatalf("Set %s: %v", key, err)
+		}
+		changed = append(changed, f)
+	}
+	set("DOCKER_TIMEOUT", "600")
+	set("RESTIC_REPOSITORY", "/mnt/new repo")
+	set("PASSWORD_FILE", "/etc/restic.pw")
+	set("KEEP_WEEKLY", "8")
+	set("RCLONE_REMOTE", "b2#1")
+	set("HOSTNAME", "") // Missing and empty: nothing to add
+
+	if err := SaveFields(path, cfg, changed); err != nil {
+		t.Fatalf("SaveFields: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	want := `# Top comment
+[docker]
+DOCKER_STACKS_DIR=/opt/stacks
+TIMEOUT = 600   # seconds
+
+[local_backup]
+RESTIC_REPOSITORY="/mnt/new repo"
+# PASSWORD_FILE=/etc/backup/restic.password
+PASSWORD_FILE=/etc/restic.pw
+RESTIC_PASSWORD=secret
+CUSTOM_KEY=kept
+KEEP_DAILY=7
+KEEP_WEEKLY=8
+
+[custom]
+SOMETHING=else
+
+[cloud_sync]
+RCLONE_REMOTE="b2#1"
+`
+	if string(data) != want {
+		t.Errorf("Unexpected file content:\n%s\nwant:\n%s", data, want)
+	}
+
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o640 {
+		t.Errorf("Expected mode 0640 to be kept, got %v (%v)", info.Mode().Perm(), err)
+	}
+
+	// The file reads back to the saved values
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	for _, f := range changed {
+		if got, want := f.Value(reloaded), f.Value(cfg); got != want {
+			t.Errorf("%s: reloaded %q, saved %q", f.Key, got, want)
+		}
+	}
+}
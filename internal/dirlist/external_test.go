@@ -200,7 +200,39 @@ func TestExternalPaths(t *testing.T) {
 		}
 	})
 
-	// Test 8: Dirlist file format
+	// Test 8: Resolve by identifier and snapshot tag
+	t.Run("Resolve", func(t *testing.T) {
+		mgr := NewManager(dirlistPath, lockDir, stacksDir)
+		if err := mgr.Load(); err != nil {
+			t.Fatalf("Load error: %v", err)
+		}
+		if _, _, err := mgr.Sync(); err != nil {
+			t.Fatalf("Sync error: %v", err)
+		}
+		if !mgr.Exists(externalDir) {
+			if err := mgr.AddExternal(externalDir); err != nil {
+				t.Fatalf("AddExternal error: %v", err)
+			}
+		}
+
+		cases := map[string]string{
+			"stack1":                  "stack1",
+			externalDir:               externalDir,
+			externalDir + "/":         externalDir,
+			"external-stack-external": externalDir,
+		}
+		for name, want := range cases {
+			got, err := mgr.Resolve(name)
+			if err != nil || got != want {
+				t.Fatalf("Resolve(%q): got %q, %v; want %q", name, got, err, want)
+			}
+		}
+		if _, err := mgr.Resolve("missing"); err == nil {
+			t.Fatalf("Unknown name should not resolve")
+		}
+	})
+
+	// Test 9: Dirlist file format
 	t.Run("DirlistFileFormat", func(t *testing.T) {
 		// Reset and create fresh
 		_ = os.Remove(dirlistPath) // Ignore error if file doesn't exist
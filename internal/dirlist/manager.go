@@ -336,6 +336,43 @@ func (m *Manager) GetFullPath(id string) string {
 	return filepath.Join(m.baseDir, entry.Path)
 }
 
+// SnapshotTag returns the restic tag used for an entry's snapshots.
+// Discovered entries use their name; external paths use basename + "-external".
+func (m *Manager) SnapshotTag(id string) string {
+	entry, exists := m.entries[id]
+	if exists && entry.IsExternal {
+		return filepath.Base(entry.Path) + "-external"
+	}
+	return id
+}
+
+// Resolve maps a user supplied name to an entry identifier.
+// Besides the identifier itself, external entries are matched by their snapshot tag.
+func (m *Manager) Resolve(name string) (string, error) {
+	if filepath.IsAbs(name) {
+		name = filepath.Clean(name)
+	}
+	if _, exists := m.entries[name]; exists {
+		return name, nil
+	}
+
+	var matches []string
+	for id, entry := range m.entries {
+		if entry.IsExternal && m.SnapshotTag(id) == name {
+			matches = append(matches, id)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("not in dirlist: %s", name)
+	case 1:
+		return matches[0], nil
+	default:
+		sort.Strings(matches)
+		return "", fmt.Errorf("%s matches several external paths: %s", name, strings.Join(matches, ", "))
+	}
+}
+
 // GetSelections returns id->enabled map for TUI
 func (m *Manager) GetSelections() map[string]bool {
 	return m.GetAll()
@@ -0,0 +1,147 @@
+// Package history keeps the outcome and output of past operations
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"backup-tui/internal/util"
+)
+
+const (
+	// dirName is the history directory kept in the log directory
+	dirName = "history"
+	// indexName lists the recorded runs, the output of each run is in <id>.log
+	indexName = "index.json"
+	// MaxEntries is the number of runs kept, older runs are removed when a new one is recorded
+	MaxEntries = 200
+)
+
+// Entry is one recorded run
+type Entry struct {
+	ID        string        `json:"id"`
+	Operation string        `json:"operation"` // backup, sync, restore, prune, forget, ...
+	Title     string        `json:"title"`
+	Time      time.Time     `json:"time"` // Start of the run
+	Duration  time.Duration `json:"duration"`
+	Success   bool          `json:"success"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// NewEntry builds an entry for a run of operation that took duration and ended with err
+func NewEntry(operation, title string, duration time.Duration, err error) Entry {
+	start := time.Now().Add(-duration)
+	e := Entry{
+		ID:        start.Format("20060102-150405.000") + "-" + operation,
+		Operation: operation,
+		Title:     title,
+		Time:      start,
+		Duration:  duration,
+		Success:   err == nil,
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	return e
+}
+
+// Dir returns the history directory below logDir
+func Dir(logDir string) string {
+	return filepath.Join(logDir, dirName)
+}
+
+// List returns the recorded runs, newest first. A missing history yields no entries.
+func List(logDir string) ([]Entry, error) {
+	data, err := os.ReadFile(filepath.Join(Dir(logDir), indexName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read history: %w", err)
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("cannot parse history: %w", err)
+	}
+	sortNewest(entries)
+	return entries, nil
+}
+
+// sortNewest orders entries by start time, newest first
+func sortNewest(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.After(entries[j].Time)
+	})
+}
+
+// Output returns the captured output of the run with the given ID
+func Output(logDir, id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return "", fmt.Errorf("invalid history entry: %q", id)
+	}
+	data, err := os.ReadFile(filepath.Join(Dir(logDir), id+".log"))
+	if err != nil {
+		return "", fmt.Errorf("cannot read output of %s: %w", id, err)
+	}
+	return string(data), nil
+}
+
+// Record stores e with its output and drops the oldest runs beyond MaxEntries
+func Record(logDir string, e Entry, output string) error {
+	dir := Dir(logDir)
+	lock, err := util.NewFileLock(dir, "history")
+	if err != nil {
+		return fmt.Errorf("cannot create lock: %w", err)
+	}
+	if err := lock.Acquire(10 * time.Second); err != nil {
+		return fmt.Errorf("cannot acquire lock: %w", err)
+	}
+	defer lock.Release()
+
+	if err := os.WriteFile(filepath.Join(dir, e.ID+".log"), []byte(output), 0o644); err != nil {
+		return fmt.Errorf("cannot write run output: %w", err)
+	}
+
+	entries, err := List(logDir)
+	if err != nil {
+		// A corrupt index should not block recording new runs
+		entries = nil
+	}
+	entries = append(entries, e)
+	sortNewest(entries)
+	if len(entries) > MaxEntries {
+		for _, old := range entries[MaxEntries:] {
+			os.Remove(filepath.Join(dir, old.ID+".log"))
+		}
+		entries = entries[:MaxEntries]
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot encode history: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp(dir, "index-*.tmp")
+	if err != nil {
+		return fmt.Errorf("cannot create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("cannot write history: %w", err)
+	}
+	tmpFile.Close()
+
+	if err := os.Rename(tmpPath, filepath.Join(dir, indexName)); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("cannot save history: %w", err)
+	}
+	return nil
+}
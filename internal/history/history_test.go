@@ -0,0 +1,92 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordAndList(t *testing.T) {
+	dir := t.TempDir()
+
+	entries, err := List(dir)
+	if err != nil {
+		t.Fatalf("List on empty dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("Expected no entries, got %+v", entries)
+	}
+
+	backup := NewEntry("backup", "Running Backup", 2*time.Minute, nil)
+	if err := Record(dir, backup, "Backing up webapp\nCompleted successfully!\n"); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	sync := NewEntry("sync", "Cloud Sync", time.Second, errors.New("remote not found"))
+	if err := Record(dir, sync, "Syncing...\n"); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	entries, err = List(dir)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	// The backup started two minutes ago, the sync a second ago
+	if entries[0].Operation != "sync" || entries[0].Success || entries[0].Error != "remote not found" {
+		t.Errorf("Unexpected first entry: %+v", entries[0])
+	}
+	if entries[1].Operation != "backup" || !entries[1].Success || entries[1].Duration != 2*time.Minute {
+		t.Errorf("Unexpected second entry: %+v", entries[1])
+	}
+
+	out, err := Output(dir, backup.ID)
+	if err != nil {
+		t.Fatalf("Output: %v", err)
+	}
+	if out != "Backing up webapp\nCompleted successfully!\n" {
+		t.Errorf("Unexpected output: %q", out)
+	}
+	if _, err := Output(dir, "../index"); err == nil {
+		t.Error("Expected an error for an ID outside the history directory")
+	}
+}
+
+func TestRecordTrimsOldRuns(t *testing.T) {
+	dir := t.TempDir()
+
+	start := time.Now().Add(-time.Hour)
+	var first Entry
+	for i := 0; i <= MaxEntries; i++ {
+		e := Entry{
+			ID:        fmt.Sprintf("run-%03d", i),
+			Operation: "backup",
+			Time:      start.Add(time.Duration(i) * time.Second),
+			Success:   true,
+		}
+		if i == 0 {
+			first = e
+		}
+		if err := Record(dir, e, "output"); err != nil {
+			t.Fatalf("Record %d: %v", i, err)
+		}
+	}
+
+	entries, err := List(dir)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(entries) != MaxEntries {
+		t.Fatalf("Expected %d entries, got %d", MaxEntries, len(entries))
+	}
+	if entries[len(entries)-1].ID == first.ID {
+		t.Error("Expected the oldest run to be dropped")
+	}
+	if _, err := os.Stat(filepath.Join(Dir(dir), first.ID+".log")); !os.IsNotExist(err) {
+		t.Errorf("Expected the output of the oldest run to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package setup
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+
+	"backup-tui/internal/util"
+)
+
+// Prompt asks the questions line by line on in and out, repeating a
+// question until its answer is accepted. When in is a terminal, secret
+// answers are read without echo and asked twice.
+func Prompt(questions []Question, in io.Reader, out io.Writer) (Answers, error) {
+	var a Answers
+	reader := bufio.NewReader(in)
+	tty, _ := in.(*os.File)
+	if tty != nil && !term.IsTerminal(tty.Fd()) {
+		tty = nil
+	}
+
+	for _, q := range questions {
+		if q.Skip(a) {
+			continue
+		}
+		fmt.Fprintln(out)
+		if q.Help != "" {
+			fmt.Fprintf(out, "%s%s%s\n", util.ColorGray, q.Help, util.ColorReset)
+		}
+
+		prompt := q.Prompt
+		if len(q.Choices) > 0 {
+			prompt += " (" + strings.Join(q.Choices, "/") + ")"
+		}
+		for {
+			if def := q.Default(a); def != "" {
+				fmt.Fprintf(out, "%s [%s]: ", prompt, def)
+			} else {
+				fmt.Fprintf(out, "%s: ", prompt)
+			}
+
+			var line string
+			if q.Secret && tty != nil {
+				value, match, err := readSecret(tty, out, q.Prompt)
+				if err != nil {
+					return a, fmt.Errorf("setup aborted: %w", err)
+				}
+				if !match {
+					fmt.Fprintf(out, "%sThe answers do not match%s\n", util.ColorRed, util.ColorReset)
+					continue
+				}
+				line = value
+			} else {
+				value, err := reader.ReadString('\n')
+				if err != nil && value == "" {
+					fmt.Fprintln(out)
+					return a, fmt.Errorf("setup aborted: %w", err)
+				}
+				line = value
+			}
+			if _, err := q.Answer(&a, line); err != nil {
+				fmt.Fprintf(out, "%s%v%s\n", util.ColorRed, err, util.ColorReset)
+				continue
+			}
+			break
+		}
+	}
+
+	return a, nil
+}
+
+// readSecret reads an answer from the terminal without echo, then asks for it
+// again and reports whether both entries match
+func readSecret(tty *os.File, out io.Writer, prompt string) (string, bool, error) {
+	var entered [2]string
+	for i := range entered {
+		if i > 0 {
+			fmt.Fprintf(out, "Repeat %s: ", strings.ToLower(prompt))
+		}
+		value, err := term.ReadPassword(tty.Fd())
+		fmt.Fprintln(out)
+		if err != nil {
+			return "", false, err
+		}
+		entered[i] = string(value)
+	}
+	return entered[0], entered[0] == entered[1], nil
+}
@@ -0,0 +1,328 @@
+// Package setup implements the first-run wizard that creates config.ini,
+// the dirlist and, if asked to, a new restic repository
+package setup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"backup-tui/internal/backup"
+	"backup-tui/internal/cloud"
+	"backup-tui/internal/config"
+	"backup-tui/internal/dirlist"
+)
+
+// Password methods offered by the wizard, named as Config.GetPasswordMethod names them
+const (
+	PasswordInline  = "inline"
+	PasswordFile    = "file"
+	PasswordCommand = "command"
+)
+
+// Answers are the choices made in the wizard
+type Answers struct {
+	StacksDir      string
+	Repository     string
+	PasswordMethod string
+	Password       string // The password, the password file or the command, depending on PasswordMethod
+	InitRepository bool   // Run restic init before writing the config
+	Remote         string // Rclone remote, empty to set up local backups only
+	RemotePath     string
+	EnableStacks   bool // Enable backup of all discovered stacks
+}
+
+// Question is one step of the wizard. The CLI and the TUI ask the same
+// questions in the same order, skipping those that do not apply.
+type Question struct {
+	Prompt  string
+	Help    string
+	Choices []string // Accepted answers, any unique prefix will do; empty for free text
+	Secret  bool     // Do not echo the answer
+
+	def  func(a Answers) string
+	skip func(a Answers) bool
+	set  func(a *Answers, value string) error
+}
+
+// Default returns the answer used when the question is answered with an empty line
+func (q Question) Default(a Answers) string {
+	if q.def == nil {
+		return ""
+	}
+	return q.def(a)
+}
+
+// Skip reports whether the question does not apply after the answers given so far
+func (q Question) Skip(a Answers) bool {
+	return q.skip != nil && q.skip(a)
+}
+
+// Answer checks value, or the default if value is empty, and stores it in a.
+// It returns the accepted answer, the full choice for a prefix.
+func (q Question) Answer(a *Answers, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		value = q.Default(*a)
+	}
+	if len(q.Choices) > 0 {
+		var matches []string
+		for _, choice := range q.Choices {
+			if strings.HasPrefix(choice, strings.ToLower(value)) {
+				matches = append(matches, choice)
+			}
+		}
+		if value == "" || len(matches) != 1 {
+			return "", fmt.Errorf("answer one of: %s", strings.Join(q.Choices, ", "))
+		}
+		value = matches[0]
+	}
+	return value, q.set(a, value)
+}
+
+// Questions returns the questions of the wizard; ctx bounds the rclone remote check
+func Questions(ctx context.Context) []Question {
+	yes := []string{"yes", "no"}
+	return []Question{
+		{
+			Prompt: "Docker stacks directory",
+			Help:   "Every directory below it with a compose file is a stack that can be backed up",
+			def:    func(Answers) string { return config.DefaultConfig().Docker.StacksDir },
+			set: func(a *Answers, value string) error {
+				path, err := filepath.Abs(value)
+				if err != nil {
+					return err
+				}
+				if info, err := os.Stat(path); err != nil || !info.IsDir() {
+					return fmt.Errorf("%s is not a directory", path)
+				}
+				a.StacksDir = path
+				return nil
+			},
+		},
+		{
+			Prompt: "Restic repository",
+			Help:   "A local path or any restic backend such as sftp:host:/path",
+			set: func(a *Answers, value string) error {
+				if value == "" {
+					return fmt.Errorf("a repository is required")
+				}
+				if backup.LocalRepository(value) == value {
+					path, err := filepath.Abs(value)
+					if err != nil {
+						return err
+					}
+					value = path
+				}
+				a.Repository = value
+				return nil
+			},
+		},
+		{
+			Prompt:  "Create a new repository there",
+			Help:    "Runs restic init; answer no if the repository already exists",
+			Choices: yes,
+			def: func(a Answers) string {
+				if backup.LocalRepository(a.Repository) != "" {
+					return "yes"
+				}
+				return "no"
+			},
+			skip: func(a Answers) bool { return RepositoryExists(a.Repository) },
+			set: func(a *Answers, value string) error {
+				a.InitRepository = value == "yes"
+				return nil
+			},
+		},
+		{
+			Prompt:  "Password method",
+			Help:    "inline stores the password in config.ini, file and command keep it out of it",
+			Choices: []string{PasswordFile, PasswordCommand, PasswordInline},
+			def:     func(Answers) string { return PasswordFile },
+			set: func(a *Answers, value string) error {
+				a.PasswordMethod = value
+				return nil
+			},
+		},
+		{
+			Prompt: "Password file",
+			skip:   func(a Answers) bool { return a.PasswordMethod != PasswordFile },
+			set: func(a *Answers, value string) error {
+				if value == "" {
+					return fmt.Errorf("a password file is required")
+				}
+				path, err := filepath.Abs(value)
+				if err != nil {
+					return err
+				}
+				if info, err := os.Stat(path); err != nil || info.IsDir() {
+					return fmt.Errorf("password file %s not found, create it first", path)
+				}
+				a.Password = path
+				return nil
+			},
+		},
+		{
+			Prompt: "Password command",
+			Help:   "A command printing the password, such as: pass show backup",
+			skip:   func(a Answers) bool { return a.PasswordMethod != PasswordCommand },
+			set: func(a *Answers, value string) error {
+				if value == "" {
+					return fmt.Errorf("a command is required")
+				}
+				a.Password = value
+				return nil
+			},
+		},
+		{
+			Prompt: "Repository password",
+			Secret: true,
+			skip:   func(a Answers) bool { return a.PasswordMethod != PasswordInline },
+			set: func(a *Answers, value string) error {
+				if value == "" {
+					return fmt.Errorf("a password is required")
+				}
+				a.Password = value
+				return nil
+			},
+		},
+		{
+			Prompt: "Rclone remote for cloud sync",
+			Help:   "Name of a remote from 'rclone listremotes', leave empty to skip cloud sync",
+			set: func(a *Answers, value string) error {
+				value = strings.TrimSuffix(value, ":")
+				if value != "" {
+					if err := cloud.ValidateRemote(ctx, value); err != nil {
+						return err
+					}
+				}
+				a.Remote = value
+				return nil
+			},
+		},
+		{
+			Prompt: "Path on the remote",
+			def:    func(Answers) string { return config.DefaultConfig().CloudSync.Path },
+			skip:   func(a Answers) bool { return a.Remote == "" },
+			set: func(a *Answers, value string) error {
+				a.RemotePath = value
+				return nil
+			},
+		},
+		{
+			Prompt:  "Enable backup of all discovered stacks",
+			Help:    "Otherwise new stacks stay disabled until enabled in the dirlist",
+			Choices: yes,
+			def:     func(Answers) string { return "no" },
+			skip: func(a Answers) bool {
+				stacks, err := dirlist.DiscoverDirectories(a.StacksDir)
+				return err != nil || len(stacks) == 0
+			},
+			set: func(a *Answers, value string) error {
+				a.EnableStacks = value == "yes"
+				return nil
+			},
+		},
+	}
+}
+
+// RepositoryExists reports whether repo is a local path holding a restic
+// repository; repositories on other backends are not checked
+func RepositoryExists(repo string) bool {
+	path := backup.LocalRepository(repo)
+	if path == "" {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(path, "config"))
+	return err == nil
+}
+
+// Config returns the configuration the answers describe
+func (a Answers) Config() *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.Docker.StacksDir = a.StacksDir
+	cfg.LocalBackup.Repository = a.Repository
+	switch a.PasswordMethod {
+	case PasswordInline:
+		cfg.LocalBackup.Password = a.Password
+	case PasswordFile:
+		cfg.LocalBackup.PasswordFile = a.Password
+	case PasswordCommand:
+		cfg.LocalBackup.PasswordCommand = a.Password
+	}
+	cfg.CloudSync.Remote = a.Remote
+	if a.RemotePath != "" {
+		cfg.CloudSync.Path = a.RemotePath
+	}
+	return cfg
+}
+
+// writtenKeys are the settings the wizard fills in on the template
+var writtenKeys = map[string]bool{
+	"DOCKER_STACKS_DIR": true, "RESTIC_REPOSITORY": true, "RESTIC_PASSWORD": true, "PASSWORD_FILE": true,
+	"PASSWORD_COMMAND": true, "RCLONE_REMOTE": true, "RCLONE_PATH": true,
+}
+
+// Run creates the configuration at configPath: it initializes the
+// repository if asked to, writes config.ini from the template and writes
+// the dirlist with the stacks found. Progress is written to out.
+func Run(ctx context.Context, configPath string, a Answers, out io.Writer) error {
+	if _, err := os.Stat(configPath); err == nil {
+		return fmt.Errorf("%s already exists", configPath)
+	}
+	cfg := a.Config()
+
+	// The repository comes first, a failed init leaves nothing behind to clean up
+	if a.InitRepository {
+		fmt.Fprintf(out, "Initializing repository %s\n", cfg.LocalBackup.Repository)
+		restic := backup.NewResticManager(&cfg.LocalBackup, false, out)
+		err := restic.Init(ctx)
+		restic.Cleanup()
+		if err != nil {
+			return err
+		}
+	}
+
+	var fields []config.Field
+	for _, f := range config.Fields() {
+		if writtenKeys[f.Key] {
+			fields = append(fields, f)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
+		return fmt.Errorf("cannot create config directory: %w", err)
+	}
+	if err := config.Create(configPath, cfg, fields); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Wrote %s\n", configPath)
+
+	// Load it back for the dirlist and lock paths, which follow from its location
+	loaded, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("cannot load the new config: %w", err)
+	}
+	list := dirlist.NewManager(loaded.DirlistFile, loaded.LockDir, loaded.Docker.StacksDir)
+	if err := list.Load(); err != nil {
+		return err
+	}
+	added, _, err := list.Sync()
+	if err != nil {
+		return err
+	}
+	if a.EnableStacks {
+		for _, name := range added {
+			list.Set(name, true)
+		}
+	}
+	if err := list.Save(); err != nil {
+		return err
+	}
+	total, enabled, _ := list.Count()
+	fmt.Fprintf(out, "Wrote %s with %d stack(s), %d enabled\n", list.FilePath(), total, enabled)
+
+	return nil
+}
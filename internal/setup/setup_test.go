@@ -0,0 +1,104 @@
+package setup
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"backup-tui/internal/config"
+)
+
+func TestPromptAndRun(t *testing.T) {
+	dir := t.TempDir()
+	stacks := filepath.Join(dir, "stacks")
+	for _, name := range []string{"nginx", "db"} {
+		if err := os.MkdirAll(filepath.Join(stacks, name), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(stacks, name, "compose.yml"), []byte("services: {}\n"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	secret := filepath.Join(dir, "password")
+	if err := os.WriteFile(secret, []byte("secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// A missing stacks directory and an unknown choice are asked again
+	input := strings.Join([]string{
+		filepath.Join(dir, "missing"),
+		stacks,
+		filepath.Join(dir, "repo"),
+		"maybe",
+		"n",
+		"",
+		secret,
+		"",
+		"y",
+	}, "\n") + "\n"
+	var out strings.Builder
+	answers, err := Prompt(Questions(context.Background()), strings.NewReader(input), &out)
+	if err != nil {
+		t.Fatalf("Prompt: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "is not a directory") || !strings.Contains(out.String(), "answer one of: yes, no") {
+		t.Errorf("Expected the rejected answers to be reported:\n%s", out.String())
+	}
+	want := Answers{StacksDir: stacks, Repository: filepath.Join(dir, "repo"), PasswordMethod: PasswordFile, Password: secret, EnableStacks: true}
+	if answers != want {
+		t.Fatalf("Prompt() = %+v, want %+v", answers, want)
+	}
+
+	path := filepath.Join(dir, "config", "config.ini")
+	if err := Run(context.Background(), path, answers, io.Discard); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Docker.StacksDir != stacks || cfg.LocalBackup.Repository != want.Repository || cfg.GetPasswordMethod() != "file" ||
+		cfg.LocalBackup.PasswordFile != secret || cfg.CloudSync.Remote != "" {
+		t.Errorf("Unexpected config: %+v %+v %+v", cfg.Docker, cfg.LocalBackup, cfg.CloudSync)
+	}
+	if issues := cfg.Lint(); len(issues) > 0 {
+		t.Errorf("Lint() = %v", issues)
+	}
+
+	data, err := os.ReadFile(cfg.DirlistFile)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if !strings.Contains(string(data), "db=true\nnginx=true\n") {
+		t.Errorf("Unexpected dirlist:\n%s", data)
+	}
+
+	if err := Run(context.Background(), path, answers, io.Discard); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("Expected Run to refuse an existing config, got %v", err)
+	}
+}
+
+func TestRepositoryExists(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		repo string
+		want bool
+	}{
+		{dir, true},
+		{"local:" + dir, true},
+		{filepath.Join(dir, "new"), false},
+		{"sftp:backup@nas:/srv/restic", false},
+	}
+	for _, tt := range tests {
+		if got := RepositoryExists(tt.repo); got != tt.want {
+			t.Errorf("RepositoryExists(%q) = %v, want %v", tt.repo, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Package stats keeps a time series of repository usage to show its growth
+package stats
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"backup-tui/internal/util"
+)
+
+const (
+	// fileName holds one JSON sample per line in the log directory, oldest first
+	fileName = "stats.jsonl"
+	// MaxSamples is the number of samples kept, older ones are dropped when a new one is recorded
+	MaxSamples = 1000
+)
+
+// Usage is what restic stats reports for a set of snapshots
+type Usage struct {
+	RestoreSize      int64   `json:"restore_size"` // Size of the files when restored
+	FileCount        int64   `json:"file_count"`
+	RawData          int64   `json:"raw_data"`     // Stored after deduplication and compression
+	Uncompressed     int64   `json:"uncompressed"` // Stored after deduplication, before compression
+	CompressionRatio float64 `json:"compression_ratio"`
+	Snapshots        int     `json:"snapshots"`
+}
+
+// Sample is the usage of the repository and of each stack tag at one point in time.
+// The restore size of a stack is that of its latest snapshot; its raw data is
+// what all of its snapshots reference, so data shared between stacks counts for each.
+type Sample struct {
+	Time       time.Time        `json:"time"`
+	Repository Usage            `json:"repository"`
+	Stacks     map[string]Usage `json:"stacks"`
+}
+
+// Load returns the recorded samples, oldest first. A missing file yields no samples.
+func Load(logDir string) ([]Sample, error) {
+	f, err := os.Open(filepath.Join(logDir, fileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read statistics: %w", err)
+	}
+	defer f.Close()
+
+	var samples []Sample
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var s Sample
+		if err := json.Unmarshal([]byte(line), &s); err != nil {
+			continue // A line cut short by a crash should not hide the others
+		}
+		samples = append(samples, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read statistics: %w", err)
+	}
+	sort.SliceStable(samples, func(i, j int) bool {
+		return samples[i].Time.Before(samples[j].Time)
+	})
+	return samples, nil
+}
+
+// Record appends s to the series and drops the oldest samples beyond MaxSamples
+func Record(logDir string, s Sample) error {
+	lock, err := util.NewFileLock(logDir, "stats")
+	if err != nil {
+		return fmt.Errorf("cannot create lock: %w", err)
+	}
+	if err := lock.Acquire(10 * time.Second); err != nil {
+		return fmt.Errorf("cannot acquire lock: %w", err)
+	}
+	defer lock.Release()
+
+	samples, err := Load(logDir)
+	if err != nil {
+		// An unreadable series should not block recording new samples
+		samples = nil
+	}
+	samples = append(samples, s)
+	if len(samples) > MaxSamples {
+		samples = samples[len(samples)-MaxSamples:]
+	}
+
+	var b strings.Builder
+	for _, sample := range samples {
+		data, err := json.Marshal(sample)
+		if err != nil {
+			return fmt.Errorf("cannot encode statistics: %w", err)
+		}
+		b.Write(data)
+		b.WriteByte('\n')
+	}
+
+	tmpFile, err := os.CreateTemp(logDir, "stats-*.tmp")
+	if err != nil {
+		return fmt.Errorf("cannot create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	if _, err := tmpFile.WriteString(b.String()); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("cannot write statistics: %w", err)
+	}
+	tmpFile.Close()
+
+	if err := os.Rename(tmpPath, filepath.Join(logDir, fileName)); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("cannot save statistics: %w", err)
+	}
+	return nil
+}
+
+// GrowthPerDay returns how much value grew per day from the oldest sample
+// within window of the newest one to the newest one. Samples for which value
+// reports false are ignored; ok is false unless two such samples at least an
+// hour apart exist.
+func GrowthPerDay(samples []Sample, window time.Duration, value func(Sample) (int64, bool)) (perDay float64, ok bool) {
+	if len(samples) < 2 {
+		return 0, false
+	}
+	last := samples[len(samples)-1]
+	to, ok := value(last)
+	if !ok {
+		return 0, false
+	}
+	for _, s := range samples[:len(samples)-1] {
+		if last.Time.Sub(s.Time) > window {
+			continue
+		}
+		from, ok := value(s)
+		if !ok {
+			continue
+		}
+		span := last.Time.Sub(s.Time)
+		if span < time.Hour {
+			return 0, false
+		}
+		return float64(to-from) / span.Hours() * 24, true
+	}
+	return 0, false
+}
+
+// RepositoryRawData selects the data stored in the whole repository, for GrowthPerDay
+func RepositoryRawData(s Sample) (int64, bool) {
+	return s.Repository.RawData, true
+}
+
+// StackRawData returns a selector of the data stored for one stack, for GrowthPerDay
+func StackRawData(tag string) func(Sample) (int64, bool) {
+	return func(s Sample) (int64, bool) {
+		u, ok := s.Stacks[tag]
+		return u.RawData, ok
+	}
+}
+
+// Largest returns the stack tags of s by restore size, largest first
+func Largest(s Sample) []string {
+	tags := make([]string, 0, len(s.Stacks))
+	for tag := range s.Stacks {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		a, b := s.Stacks[tags[i]], s.Stacks[tags[j]]
+		if a.RestoreSize != b.RestoreSize {
+			return a.RestoreSize > b.RestoreSize
+		}
+		return tags[i] < tags[j]
+	})
+	return tags
+}
+
+// FormatGrowth formats a result of GrowthPerDay such as "+1.20 GB/day", or "-" without one
+func FormatGrowth(perDay float64, ok bool) string {
+	if !ok {
+		return "-"
+	}
+	switch delta := int64(perDay); {
+	case delta > 0:
+		return "+" + util.FormatSize(delta) + "/day"
+	case delta < 0:
+		return "-" + util.FormatSize(-delta) + "/day"
+	default:
+		return "0/day"
+	}
+}
@@ -0,0 +1,78 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	samples, err := Load(dir)
+	if err != nil || len(samples) != 0 {
+		t.Fatalf("Load on empty dir: %v, %d samples", err, len(samples))
+	}
+
+	now := time.Now()
+	for i := 2; i >= 0; i-- {
+		s := Sample{
+			Time:       now.Add(-time.Duration(i) * 24 * time.Hour),
+			Repository: Usage{RawData: int64(100 - i*10)},
+			Stacks:     map[string]Usage{"web": {RawData: int64(50 - i*5)}},
+		}
+		if err := Record(dir, s); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	samples, err = Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(samples) != 3 || samples[0].Repository.RawData != 80 || samples[2].Stacks["web"].RawData != 50 {
+		t.Fatalf("Unexpected samples: %+v", samples)
+	}
+}
+
+func TestGrowthPerDay(t *testing.T) {
+	day := 24 * time.Hour
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	samples := []Sample{
+		{Time: now.Add(-40 * day), Repository: Usage{RawData: 0}},
+		{Time: now.Add(-10 * day), Repository: Usage{RawData: 1000}, Stacks: map[string]Usage{}},
+		{Time: now.Add(-4 * day), Repository: Usage{RawData: 1600}, Stacks: map[string]Usage{"db": {RawData: 200}}},
+		{Time: now, Repository: Usage{RawData: 2000}, Stacks: map[string]Usage{"db": {RawData: 600}}},
+	}
+
+	if got, ok := GrowthPerDay(samples, 30*day, RepositoryRawData); !ok || got != 100 {
+		t.Errorf("30 day growth = %v, %v; want 100", got, ok)
+	}
+	if got, ok := GrowthPerDay(samples, 7*day, RepositoryRawData); !ok || got != 100 {
+		t.Errorf("7 day growth = %v, %v; want 100", got, ok)
+	}
+	// The stack only appears in the last two samples
+	if got, ok := GrowthPerDay(samples, 30*day, StackRawData("db")); !ok || got != 100 {
+		t.Errorf("Stack growth = %v, %v; want 100", got, ok)
+	}
+	if _, ok := GrowthPerDay(samples, day, RepositoryRawData); ok {
+		t.Error("Expected no growth without a second sample in the window")
+	}
+	if _, ok := GrowthPerDay(samples[:1], 30*day, RepositoryRawData); ok {
+		t.Error("Expected no growth from a single sample")
+	}
+}
+
+func TestLargest(t *testing.T) {
+	s := Sample{Stacks: map[string]Usage{
+		"small": {RestoreSize: 10},
+		"big":   {RestoreSize: 300},
+		"b":     {RestoreSize: 50},
+		"a":     {RestoreSize: 50},
+	}}
+	got := Largest(s)
+	want := []string{"big", "a", "b", "small"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Largest = %v, want %v", got, want)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+// Package status records the outcome of the most recent backup, sync, check and drill runs
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"backup-tui/internal/util"
+)
+
+// fileName is the status file kept in the log directory
+const fileName = "last-run.json"
+
+// Result is the outcome of a single backup or sync run
+type Result struct {
+	Time     time.Time     `json:"time"`
+	Success  bool          `json:"success"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// NewResult builds a result for a run that started at start and ended with err
+func NewResult(start time.Time, err error) Result {
+	r := Result{
+		Time:     time.Now(),
+		Success:  err == nil,
+		Duration: time.Since(start),
+	}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	return r
+}
+
+// CheckResult is the outcome of a repository check that read slice Subset of
+// Total of the pack data. A check reading all data is slice 1 of 1.
+type CheckResult struct {
+	Result
+	Subset int  `json:"subset"`
+	Total  int  `json:"total"`
+	Manual bool `json:"manual,omitempty"` // The slice was chosen by hand, outside the rotation
+}
+
+// CheckRotation is the progress of scheduled checks through Total slices
+type CheckRotation struct {
+	Total  int   `json:"total"`
+	Next   int   `json:"next"`             // Slice the next scheduled check reads
+	Passed []int `json:"passed,omitempty"` // Slices read without errors since the rotation started
+
+	// When every slice of a rotation had passed, or all data was read at once
+	PassCompleted *time.Time `json:"pass_completed,omitempty"`
+}
+
+// DrillResult is the outcome of restoring a stack into a sandbox
+type DrillResult struct {
+	Result
+	Snapshot string `json:"snapshot"`
+	Started  bool   `json:"started"` // The restored stack was brought up, not only validated
+}
+
+// Status holds the last result per stack, of the last cloud sync and check,
+// and of the last restore drill per stack
+type Status struct {
+	Stacks   map[string]Result      `json:"stacks"`
+	Sync     *Result                `json:"sync,omitempty"`
+	Check    *CheckResult           `json:"check,omitempty"`
+	Rotation *CheckRotation         `json:"rotation,omitempty"`
+	Drills   map[string]DrillResult `json:"drills,omitempty"`
+}
+
+// NextSubset returns the slice the next scheduled check of a rotation over
+// total slices reads: the one after the last passed slice, the failed slice
+// again, or the first when the rotation is new or the number of slices changed
+func (s *Status) NextSubset(total int) int {
+	r := s.Rotation
+	if r == nil || r.Total != total || r.Next < 1 || r.Next > total {
+		return 1
+	}
+	return r.Next
+}
+
+// Load reads the status file from logDir. A missing file yields an empty status.
+func Load(logDir string) (*Status, error) {
+	s := &Status{Stacks: make(map[string]Result)}
+
+	data, err := os.ReadFile(filepath.Join(logDir, fileName))
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read status file: %w", err)
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("cannot parse status file: %w", err)
+	}
+	if s.Stacks == nil {
+		s.Stacks = make(map[string]Result)
+	}
+	return s, nil
+}
+
+// Update loads the status file, applies fn and writes it back atomically
+func Update(logDir string, fn func(s *Status)) error {
+	lock, err := util.NewFileLock(logDir, "last-run")
+	if err != nil {
+		return fmt.Errorf("cannot create lock: %w", err)
+	}
+	if err := lock.Acquire(10 * time.Second); err != nil {
+		return fmt.Errorf("cannot acquire lock: %w", err)
+	}
+	defer lock.Release()
+
+	s, err := Load(logDir)
+	if err != nil {
+		// A corrupt file should not block recording new results
+		s = &Status{Stacks: make(map[string]Result)}
+	}
+	fn(s)
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot encode status: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp(logDir, "last-run-*.tmp")
+	if err != nil {
+		return fmt.Errorf("cannot create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("cannot write status file: %w", err)
+	}
+	tmpFile.Close()
+
+	if err := os.Rename(tmpPath, filepath.Join(logDir, fileName)); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("cannot save status file: %w", err)
+	}
+	return nil
+}
+
+// RecordStack stores the result of backing up one stack
+func RecordStack(logDir, name string, r Result) error {
+	return Update(logDir, func(s *Status) {
+		s.Stacks[name] = r
+	})
+}
+
+// RecordSync stores the result of a cloud sync
+func RecordSync(logDir string, r Result) error {
+	return Update(logDir, func(s *Status) {
+		s.Sync = &r
+	})
+}
+
+// RecordDrill stores the result of a restore drill of one stack
+func RecordDrill(logDir, name string, r DrillResult) error {
+	return Update(logDir, func(s *Status) {
+		if s.Drills == nil {
+			s.Drills = make(map[string]DrillResult)
+		}
+		s.Drills[name] = r
+	})
+}
+
+// RecordCheck stores the result of a check. A scheduled check advances the
+// rotation; a full pass is only recorded once each of its slices has passed, or
+// when all data was read at once. Slices chosen by hand leave the rotation alone.
+func RecordCheck(logDir string, c CheckResult) error {
+	return Update(logDir, func(s *Status) {
+		s.Check = &c
+
+		r := s.Rotation
+		if r == nil {
+			r = &CheckRotation{}
+		}
+		switch {
+		case c.Success && c.Total == 1:
+			r.PassCompleted = &c.Time
+		case c.Manual:
+		case r.Total != c.Total:
+			// The number of slices changed, slices read so far do not line up with the new ones
+			r = &CheckRotation{Total: c.Total, PassCompleted: r.PassCompleted}
+			fallthrough
+		default:
+			r.Next = c.Subset
+			if !c.Success {
+				break
+			}
+			if !slices.Contains(r.Passed, c.Subset) {
+				r.Passed = append(r.Passed, c.Subset)
+			}
+			r.Next = c.Subset%c.Total + 1
+			if len(r.Passed) == c.Total {
+				r.PassCompleted, r.Passed = &c.Time, nil
+			}
+		}
+		s.Rotation = r
+	})
+}
@@ -0,0 +1,135 @@
+package status
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRecordAndLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load on empty dir: %v", err)
+	}
+	if len(s.Stacks) != 0 || s.Sync != nil {
+		t.Fatalf("Expected empty status, got %+v", s)
+	}
+
+	start := time.Now().Add(-time.Minute)
+	if err := RecordStack(dir, "webapp", NewResult(start, nil)); err != nil {
+		t.Fatalf("RecordStack: %v", err)
+	}
+	if err := RecordStack(dir, "db", NewResult(start, errors.New("backup failed"))); err != nil {
+		t.Fatalf("RecordStack: %v", err)
+	}
+	if err := RecordSync(dir, NewResult(start, nil)); err != nil {
+		t.Fatalf("RecordSync: %v", err)
+	}
+
+	s, err = Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if r := s.Stacks["webapp"]; !r.Success || r.Duration < time.Minute {
+		t.Fatalf("Unexpected webapp result: %+v", r)
+	}
+	if r := s.Stacks["db"]; r.Success || r.Error != "backup failed" {
+		t.Fatalf("Unexpected db result: %+v", r)
+	}
+	if s.Sync == nil || !s.Sync.Success {
+		t.Fatalf("Unexpected sync result: %+v", s.Sync)
+	}
+}
+
+func TestCheckRotation(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().Add(-time.Minute)
+	next := func(total int) int {
+		t.Helper()
+		s, err := Load(dir)
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		return s.NextSubset(total)
+	}
+	record := func(subset, total int, err error) {
+		t.Helper()
+		c := CheckResult{Result: NewResult(start, err), Subset: subset, Total: total}
+		if err := RecordCheck(dir, c); err != nil {
+			t.Fatalf("RecordCheck: %v", err)
+		}
+	}
+
+	if n := next(3); n != 1 {
+		t.Fatalf("First check reads slice %d, want 1", n)
+	}
+	record(1, 3, nil)
+	if n := next(3); n != 2 {
+		t.Fatalf("After slice 1 passed got %d, want 2", n)
+	}
+	record(2, 3, errors.New("pack damaged"))
+	if n := next(3); n != 2 {
+		t.Fatalf("A failed slice is read again, got %d", n)
+	}
+	record(2, 3, nil)
+	record(3, 3, nil)
+	if n := next(3); n != 1 {
+		t.Fatalf("After the last slice got %d, want 1", n)
+	}
+	if n := next(5); n != 1 {
+		t.Fatalf("A new number of slices starts over, got %d", n)
+	}
+
+	record(1, 3, nil)
+	s, _ := Load(dir)
+	if s.Rotation.PassCompleted == nil || s.Check.Subset != 1 {
+		t.Fatalf("The completed pass is kept while the next one runs: %+v %+v", s.Check, s.Rotation)
+	}
+}
+
+func TestCheckPassNeedsEverySlice(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now().Add(-time.Minute)
+	record := func(subset, total int, manual bool) *Status {
+		t.Helper()
+		c := CheckResult{Result: NewResult(start, nil), Subset: subset, Total: total, Manual: manual}
+		if err := RecordCheck(dir, c); err != nil {
+			t.Fatalf("RecordCheck: %v", err)
+		}
+		s, err := Load(dir)
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		return s
+	}
+
+	record(1, 3, false)
+	s := record(3, 3, true)
+	if s.Rotation.PassCompleted != nil || s.NextSubset(3) != 2 {
+		t.Fatalf("A slice read by hand changed the rotation: %+v", s.Rotation)
+	}
+	if s.Check.Subset != 3 || !s.Check.Manual {
+		t.Fatalf("The manual check is not the last one: %+v", s.Check)
+	}
+
+	// Slices passed before the count changed do not count towards a pass of the new one
+	record(2, 4, false)
+	record(3, 4, false)
+	s = record(4, 4, false)
+	if s.Rotation.PassCompleted != nil {
+		t.Fatalf("Pass completed with slices %v of 4", s.Rotation.Passed)
+	}
+	s = record(1, 4, false)
+	if s.Rotation.PassCompleted == nil || len(s.Rotation.Passed) != 0 || s.NextSubset(4) != 2 {
+		t.Fatalf("Every slice passed, got %+v", s.Rotation)
+	}
+
+	// Reading all data at once is a full pass, whoever started it
+	dir = t.TempDir()
+	s = record(1, 1, true)
+	if s.Rotation.PassCompleted == nil || s.NextSubset(12) != 1 {
+		t.Fatalf("A full read did not complete a pass: %+v", s.Rotation)
+	}
+}
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
@@ -65,6 +66,9 @@ type Model struct {
 	dirlistDirs       []string
 	dirlistSelections map[string]bool
 	dirlistModified   bool
+	dirlistMarked     map[string]bool  // Entries marked for an ad hoc backup
+	dirlistQuery      listQuery        // Search, filter and sort of the visible entries
+	detail            stackDetailState // Detail pane of the highlighted entry
 
 	// File picker state
 	filepicker       filepicker.Model
@@ -72,7 +76,10 @@ type Model struct {
 	filePickerErr    string
 
 	// Snapshot management state
-	snapshotList     []backup.Snapshot
+	snapshotAll      []backup.Snapshot // Everything in the repository
+	snapshotList     []backup.Snapshot // Snapshots shown after search, filter, sort and grouping
+	snapshotQuery    listQuery
+	snapshotHeaders  map[int]string // Group header shown in front of a snapshotList index
 	snapshotCursor   int
 	snapshotSelected map[string]bool
 	snapshotLoading  bool
@@ -81,12 +88,45 @@ type Model struct {
 	snapshotVpReady  bool
 	snapshotYOffset  int // Desired scroll offset, persists across renders
 
+	// Snapshot browser state
+	browser snapshotBrowser
+
+	// Snapshot mounted via FUSE
+	mount snapshotMount
+
+	// Main screen dashboard
+	dashboard dashboardState
+
+	// Configuration editor
+	settings settingsEditor
+
+	// Past runs
+	history historyState
+
+	// Repository keys
+	repoKeys keysState
+
+	// Repository statistics
+	repoStats statsState
+
+	// Modal confirmation of a destructive action
+	confirm confirmDialog
+
 	// Output view state
 	outputTitle    string
 	outputContent  *strings.Builder
 	outputViewport viewport.Model
 	outputReady    bool
 
+	// Background operation state
+	ctx          context.Context    // Parent of all operation contexts, cancelled on shutdown
+	events       chan tea.Msg       // Messages streamed from the running operation
+	cancel       context.CancelFunc // Cancels the running operation
+	cancelling   bool               // Cancellation requested, waiting for the operation to return
+	quitWhenDone bool               // Quit once the running operation has returned
+	transfer     transferState      // rclone progress panel for sync and restore
+	backupRun    backupRunState     // Per-stack progress panel for backups
+
 	// Application state
 	err      error
 	quitting bool
@@ -98,9 +138,12 @@ func NewModel(cfg *config.Config) Model {
 	m := Model{
 		screen:        ScreenMain,
 		config:        cfg,
+		ctx:           context.Background(),
 		dirlist:       dirlist.NewManager(cfg.DirlistFile, cfg.LockDir, cfg.Docker.StacksDir),
 		keys:          DefaultKeyMap,
 		outputContent: &strings.Builder{},
+		dirlistQuery:  newListQuery("name", sortName),
+		snapshotQuery: newListQuery("id, host, tag or path", sortAge),
 	}
 
 	// Load dirlist (ignore errors during startup)
@@ -110,6 +153,9 @@ func NewModel(cfg *config.Config) Model {
 	// Initialize menus
 	m.initMenus()
 
+	// Init starts the first dashboard load
+	m.dashboard.loading = true
+
 	return m
 }
 
@@ -123,6 +169,8 @@ func (m *Model) initMenus() {
 		MenuItem{title: "4. Directory Management", description: "Select directories to backup", shortcut: '4'},
 		MenuItem{title: "5. Status & Logs", description: "View system status", shortcut: '5'},
 		MenuItem{title: "6. Restic Repository", description: "Manage snapshots and repository", shortcut: '6'},
+		MenuItem{title: "7. Settings", description: "Edit config.ini", shortcut: '7'},
+		MenuItem{title: "8. History", description: "Browse past runs and their output", shortcut: '8'},
 		MenuItem{title: "─────────────────────", description: "", shortcut: '-'},
 		MenuItem{title: "R. Run Backup Now", description: "Run backup now", shortcut: 'r'},
 		MenuItem{title: "P. Preview (Dry Run)", description: "Preview backup without changes", shortcut: 'p'},
@@ -142,6 +190,9 @@ func (m *Model) initMenus() {
 		MenuItem{title: "L. List Snapshots", description: "Show recent backup snapshots", shortcut: 'l'},
 		MenuItem{title: "M. Manage Snapshots", description: "Delete snapshots and prune repository", shortcut: 'm'},
 		MenuItem{title: "V. Verify Repository", description: "Verify the restic repository", shortcut: 'v'},
+		MenuItem{title: "K. Repository Keys", description: "List, add and remove keys, change the password", shortcut: 'k'},
+		MenuItem{title: "I. Initialize Repository", description: "Create the configured repository with restic init", shortcut: 'i'},
+		MenuItem{title: "S. Statistics", description: "Repository size, growth and the largest stacks", shortcut: 's'},
 	}
 	m.resticMenu = createMenu("Restic Repository", resticItems)
 
@@ -189,7 +240,11 @@ func createMenu(title string, items []list.Item) list.Model {
 
 // Init implements tea.Model
 func (m Model) Init() tea.Cmd {
-	return nil
+	ctx, cfg := m.ctx, m.config
+	return tea.Batch(
+		func() tea.Msg { return loadDashboard(ctx, cfg, true) },
+		dashboardTick(),
+	)
 }
 
 // Update implements tea.Model
@@ -242,18 +297,98 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case CommandDoneMsg:
+		if msg.Operation == "backup" {
+			m.backupRun.running = false
+		}
+		switch msg.Operation {
+		case "backup", "prune", "init":
+			m.dashboard.sizeKnown = false // Measure the repository again on the next refresh
+		}
 		if msg.Err != nil {
 			fmt.Fprintf(m.outputContent, "\n%s\n", ErrorStyle.Render(fmt.Sprintf("Error: %v", msg.Err)))
 		} else {
 			fmt.Fprintf(m.outputContent, "\n%s\n", SuccessStyle.Render("Completed successfully!"))
 		}
+		record := m.recordHistory(msg)
 		m.outputContent.WriteString("\nPress ESC to go back")
 		if m.outputReady {
 			m.outputViewport.SetContent(m.outputContent.String())
 			m.outputViewport.GotoBottom()
 		}
+		return m, record
+
+	case historyRecordedMsg:
+		if msg.Err != nil {
+			fmt.Fprintf(m.outputContent, "\n%s", WarningStyle.Render(fmt.Sprintf("Run not saved to history: %v", msg.Err)))
+			if m.outputReady && m.screen == ScreenOutput {
+				m.outputViewport.SetContent(m.outputContent.String())
+			}
+		}
+		return m, nil
+
+	case streamEventMsg:
+		updated, cmd := m.Update(msg.msg)
+		m = updated.(Model)
+		return m, tea.Batch(cmd, waitForEvent(msg.ch))
+
+	case streamClosedMsg:
+		if m.events == msg.ch {
+			m.events = nil
+			m.cancel = nil
+			m.cancelling = false
+			if m.quitWhenDone {
+				m.quitting = true
+				return m, tea.Quit
+			}
+		}
+		return m, nil
+
+	case shutdownMsg:
+		return m.quit()
+
+	case BackupEventMsg:
+		m.backupRun.apply(msg.Event)
 		return m, nil
 
+	case TransferStatsMsg:
+		m.transfer.stats = msg.Stats
+		m.transfer.hasStats = true
+		return m, nil
+
+	case TransferErrorMsg:
+		m.transfer.addError(msg.Error)
+		return m, nil
+
+	case SnapshotFilesMsg:
+		return m.handleSnapshotFiles(msg)
+
+	case dashboardMsg:
+		return m.handleDashboard(msg)
+
+	case stackDetailRequestMsg:
+		return m.handleStackDetailRequest(msg)
+
+	case stackDetailMsg:
+		return m.handleStackDetail(msg)
+
+	case stackActionMsg:
+		return m.handleStackAction(msg)
+
+	case dashboardTickMsg:
+		var cmd tea.Cmd
+		// A sync, restore or diff left running in the background may hold the
+		// repository lock; the refresh waits until it has finished
+		if m.screen == ScreenMain && m.events == nil {
+			cmd = m.refreshDashboard()
+		}
+		return m, tea.Batch(cmd, dashboardTick())
+
+	case SnapshotMountedMsg:
+		return m.handleSnapshotMounted(msg)
+
+	case SnapshotUnmountedMsg:
+		return m.handleSnapshotUnmounted(msg)
+
 	case DirlistSavedMsg:
 		if msg.Err != nil {
 			m.err = msg.Err
@@ -265,6 +400,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case ErrorMsg:
 		m.err = msg.Err
 		return m, nil
+
+	case ConfirmMsg:
+		return m.handleConfirm(msg)
+
+	case keysLoadedMsg:
+		return m.handleKeysLoaded(msg)
+
+	case keyActionMsg:
+		return m.handleKeyAction(msg)
+
+	case statsCollectedMsg:
+		return m.handleStatsCollected(msg)
 	}
 
 	// Delegate to active screen
@@ -273,10 +420,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // handleKey processes key events
 func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	// Global quit
+	// Global quit; a running operation is cancelled first, a second Ctrl+C quits
+	// as soon as it has returned
 	if msg.String() == "ctrl+c" {
-		m.quitting = true
-		return m, tea.Quit
+		if m.events != nil && !m.cancelling {
+			return m.cancelOperation()
+		}
+		return m.quit()
+	}
+
+	// An open confirmation dialog takes all keys
+	if m.confirm.active {
+		return m.handleConfirmKey(msg)
 	}
 
 	// Screen-specific key handling
@@ -301,6 +456,16 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleFilePickerKey(msg)
 	case ScreenSnapshots:
 		return m.handleSnapshotsKey(msg)
+	case ScreenSnapshotBrowser:
+		return m.handleBrowserKey(msg)
+	case ScreenSettings:
+		return m.handleSettingsKey(msg)
+	case ScreenHistory:
+		return m.handleHistoryKey(msg)
+	case ScreenKeys:
+		return m.handleKeysKey(msg)
+	case ScreenStats:
+		return m.handleStatsKey(msg)
 	}
 
 	return m, nil
@@ -310,8 +475,7 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m Model) handleMainMenuKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "q":
-		m.quitting = true
-		return m, tea.Quit
+		return m.quit()
 	case keyEnter:
 		idx := m.mainMenu.Index()
 		switch idx {
@@ -327,11 +491,15 @@ func (m Model) handleMainMenuKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m.changeScreen(ScreenStatus)
 		case 5:
 			return m.changeScreen(ScreenRestic)
-		case 7: // After separator
+		case 6:
+			return m.openSettings()
+		case 7:
+			return m.openHistory()
+		case 9: // After separator
 			return m.runQuickBackup()
-		case 8:
+		case 10:
 			return m.runDryRunBackup()
-		case 9:
+		case 11:
 			return m.showQuickStatus()
 		}
 	case "1":
@@ -346,6 +514,10 @@ func (m Model) handleMainMenuKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.changeScreen(ScreenStatus)
 	case "6":
 		return m.changeScreen(ScreenRestic)
+	case "7":
+		return m.openSettings()
+	case "8":
+		return m.openHistory()
 	case "r":
 		return m.runQuickBackup()
 	case "p":
@@ -364,8 +536,7 @@ func (m Model) handleMainMenuKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m Model) handleBackupMenuKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "q":
-		m.quitting = true
-		return m, tea.Quit
+		return m.quit()
 	case keyEsc:
 		return m.changeScreen(ScreenMain)
 	case keyEnter:
@@ -391,8 +562,7 @@ func (m Model) handleBackupMenuKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m Model) handleResticMenuKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "q":
-		m.quitting = true
-		return m, tea.Quit
+		return m.quit()
 	case keyEsc:
 		return m.changeScreen(ScreenMain)
 	case keyEnter:
@@ -404,6 +574,12 @@ func (m Model) handleResticMenuKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m.changeScreen(ScreenSnapshots)
 		case 2:
 			return m.verifyRepository()
+		case 3:
+			return m.openKeys()
+		case 4:
+			return m.initRepository()
+		case 5:
+			return m.openStats()
 		}
 	case "l":
 		return m.showSnapshots()
@@ -411,6 +587,12 @@ func (m Model) handleResticMenuKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.changeScreen(ScreenSnapshots)
 	case "v":
 		return m.verifyRepository()
+	case "k":
+		return m.openKeys()
+	case "i":
+		return m.initRepository()
+	case "s":
+		return m.openStats()
 	}
 
 	var cmd tea.Cmd
@@ -422,8 +604,7 @@ func (m Model) handleResticMenuKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m Model) handleSyncMenuKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "q":
-		m.quitting = true
-		return m, tea.Quit
+		return m.quit()
 	case keyEsc:
 		return m.changeScreen(ScreenMain)
 	case keyEnter:
@@ -455,8 +636,7 @@ func (m Model) handleSyncMenuKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m Model) handleRestoreMenuKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "q":
-		m.quitting = true
-		return m, tea.Quit
+		return m.quit()
 	case keyEsc:
 		return m.changeScreen(ScreenMain)
 	case keyEnter:
@@ -486,8 +666,7 @@ func (m Model) handleRestoreMenuKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m Model) handleStatusMenuKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "q":
-		m.quitting = true
-		return m, tea.Quit
+		return m.quit()
 	case keyEsc:
 		return m.changeScreen(ScreenMain)
 	case keyEnter:
@@ -515,20 +694,50 @@ func (m Model) handleStatusMenuKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 // handleDirlistKey handles keys on the dirlist screen
 func (m Model) handleDirlistKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// A stack action waits for confirmation, any other key cancels it
+	if m.detail.pending != "" {
+		if msg.String() == "y" || msg.String() == "Y" {
+			return m, m.confirmStackAction()
+		}
+		m.detail.pending = ""
+		return m, nil
+	}
+	if m.dirlistQuery.searching {
+		cmd := m.dirlistQuery.handleSearchKey(msg)
+		m.applyDirlistQuery()
+		return m, tea.Batch(cmd, m.scheduleStackDetail())
+	}
+
 	switch msg.String() {
 	case "q":
-		m.quitting = true
-		return m, tea.Quit
+		return m.quit()
 	case keyEsc:
+		if m.dirlistQuery.text() != "" {
+			m.dirlistQuery.clearSearch()
+			m.applyDirlistQuery()
+			return m, m.scheduleStackDetail()
+		}
 		return m.changeScreen(ScreenMain)
+	case "/":
+		return m, m.dirlistQuery.startSearch()
+	case "f", "F":
+		m.dirlistQuery.filter = (m.dirlistQuery.filter + 1) % filterCount
+		m.applyDirlistQuery()
+		return m, m.scheduleStackDetail()
+	case "o", "O":
+		m.dirlistQuery.sort = (m.dirlistQuery.sort + 1) % sortCount
+		m.applyDirlistQuery()
+		return m, m.scheduleStackDetail()
 	case "up", "k":
 		if m.dirlistCursor > 0 {
 			m.dirlistCursor--
 		}
+		return m, m.scheduleStackDetail()
 	case "down", "j":
 		if m.dirlistCursor < len(m.dirlistDirs)-1 {
 			m.dirlistCursor++
 		}
+		return m, m.scheduleStackDetail()
 	case "enter", " ":
 		m.toggleCurrentDirSelection()
 	case "s":
@@ -540,12 +749,48 @@ func (m Model) handleDirlistKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "x", "X":
 		return m.openFilePicker()
 	case "d", "D":
-		m.removeCurrentExternalEntry()
+		return m, m.confirmRemoveExternalEntry()
+	case "u", "U":
+		m.requestStackAction(stackActionStart)
+	case "t", "T":
+		m.requestStackAction(stackActionStop)
+	case "r", "R":
+		m.requestStackAction(stackActionRestart)
+	case "i", "I":
+		return m, m.refreshStackDetail()
+	case "m":
+		m.toggleCurrentDirMark()
+	case "b":
+		return m.runSelectedBackup()
 	}
 
 	return m, nil
 }
 
+// toggleCurrentDirMark marks or unmarks the current directory for an ad hoc backup
+func (m *Model) toggleCurrentDirMark() {
+	if len(m.dirlistDirs) == 0 {
+		return
+	}
+	dir := m.dirlistDirs[m.dirlistCursor]
+	m.dirlistMarked[dir] = !m.dirlistMarked[dir]
+}
+
+// markedDirs returns the marked directories, including those hidden by a filter,
+// or the directory under the cursor if nothing is marked
+func (m Model) markedDirs() []string {
+	var dirs []string
+	for _, dir := range m.dirlist.SortedDirs() {
+		if m.dirlistMarked[dir] {
+			dirs = append(dirs, dir)
+		}
+	}
+	if len(dirs) == 0 && len(m.dirlistDirs) > 0 {
+		dirs = append(dirs, m.dirlistDirs[m.dirlistCursor])
+	}
+	return dirs
+}
+
 // toggleCurrentDirSelection toggles the selection of the current directory
 func (m *Model) toggleCurrentDirSelection() {
 	if len(m.dirlistDirs) == 0 {
@@ -556,14 +801,32 @@ func (m *Model) toggleCurrentDirSelection() {
 	m.dirlistModified = true
 }
 
-// setAllDirlistSelections sets all directory selections to the given value
+// setAllDirlistSelections sets the selection of every visible directory to the given value
 func (m *Model) setAllDirlistSelections(enabled bool) {
-	for dir := range m.dirlistSelections {
+	for _, dir := range m.dirlistDirs {
 		m.dirlistSelections[dir] = enabled
 	}
 	m.dirlistModified = true
 }
 
+// confirmRemoveExternalEntry asks before removing the current entry if it's an external entry
+func (m *Model) confirmRemoveExternalEntry() tea.Cmd {
+	if len(m.dirlistDirs) == 0 {
+		return nil
+	}
+	dir := m.dirlistDirs[m.dirlistCursor]
+	entry := m.dirlist.GetEntry(dir)
+	if entry == nil || !entry.IsExternal {
+		return nil
+	}
+	items := []string{
+		fmt.Sprintf("%s  %s", dir, MutedStyle.Render(m.dirlist.GetFullPath(dir))),
+		fmt.Sprintf("Snapshot tag: %s", m.dirlist.SnapshotTag(dir)),
+	}
+	return m.askConfirm(confirmRemoveExternal, "Remove external entry",
+		items, "The path is no longer backed up once the dirlist is saved. Existing snapshots are kept.", dir)
+}
+
 // removeCurrentExternalEntry removes the current entry if it's an external entry
 func (m *Model) removeCurrentExternalEntry() {
 	if len(m.dirlistDirs) == 0 {
@@ -582,10 +845,20 @@ func (m *Model) removeCurrentExternalEntry() {
 
 // handleOutputKey handles keys on the output screen
 func (m Model) handleOutputKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.events != nil && msg.String() == "x" {
+		return m.cancelOperation()
+	}
+	// Stay on the output while a backup runs so it can be cancelled
+	if m.backupRun.running {
+		switch msg.String() {
+		case "esc", "enter":
+			return m, nil
+		}
+	}
+
 	switch msg.String() {
 	case "q":
-		m.quitting = true
-		return m, tea.Quit
+		return m.quit()
 	case "esc", "enter":
 		return m.changeScreen(m.prevScreen)
 	case "home", "g":
@@ -635,8 +908,7 @@ func (m Model) openFilePicker() (tea.Model, tea.Cmd) {
 func (m Model) handleFilePickerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "q":
-		m.quitting = true
-		return m, tea.Quit
+		return m.quit()
 	case keyEsc:
 		// Cancel and go back to dirlist
 		m.filePickerActive = false
@@ -673,6 +945,11 @@ func (m Model) handleFilePickerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m Model) updateActiveScreen(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
+	if m.confirm.active {
+		m.confirm.input, cmd = m.confirm.input.Update(msg)
+		return m, cmd
+	}
+
 	switch m.screen {
 	case ScreenMain:
 		m.mainMenu, cmd = m.mainMenu.Update(msg)
@@ -688,6 +965,29 @@ func (m Model) updateActiveScreen(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.resticMenu, cmd = m.resticMenu.Update(msg)
 	case ScreenFilePicker:
 		m.filepicker, cmd = m.filepicker.Update(msg)
+	case ScreenSnapshotBrowser:
+		// Keep text input cursors blinking
+		if m.browser.prompting {
+			m.browser.target, cmd = m.browser.target.Update(msg)
+		} else if m.browser.searching {
+			m.browser.search, cmd = m.browser.search.Update(msg)
+		}
+	case ScreenDirlist:
+		if m.dirlistQuery.searching {
+			m.dirlistQuery.search, cmd = m.dirlistQuery.search.Update(msg)
+		}
+	case ScreenSnapshots:
+		if m.snapshotQuery.searching {
+			m.snapshotQuery.search, cmd = m.snapshotQuery.search.Update(msg)
+		}
+	case ScreenSettings:
+		if m.settings.editing {
+			m.settings.input, cmd = m.settings.input.Update(msg)
+		}
+	case ScreenKeys:
+		if m.repoKeys.entering != "" {
+			m.repoKeys.input, cmd = m.repoKeys.input.Update(msg)
+		}
 	}
 
 	return m, cmd
@@ -698,14 +998,24 @@ func (m Model) changeScreen(screen Screen) (tea.Model, tea.Cmd) {
 	m.prevScreen = m.screen
 	m.screen = screen
 
-	// Initialize dirlist if switching to it
-	if screen == ScreenDirlist {
+	// Initialize dirlist if switching to it; coming back from the file
+	// picker or an ad hoc backup keeps unsaved changes
+	if screen == ScreenDirlist && m.prevScreen != ScreenFilePicker && m.prevScreen != ScreenOutput {
 		m.initDirlist()
 	}
+	if screen == ScreenDirlist {
+		return m, tea.Batch(m.scheduleStackDetail(), m.refreshDashboard())
+	}
 
 	// Initialize snapshots if switching to it
 	if screen == ScreenSnapshots {
 		m.initSnapshots()
+		return m, m.refreshDashboard()
+	}
+
+	// Refresh dashboard when returning home
+	if screen == ScreenMain {
+		return m, m.refreshDashboard()
 	}
 
 	return m, nil
@@ -718,23 +1028,18 @@ func (m *Model) initDirlist() {
 
 	m.refreshDirlistView()
 	m.dirlistModified = false
+	m.dirlistMarked = make(map[string]bool)
+	m.resetStackDetails()
 }
 
 // refreshDirlistView updates the TUI state from in-memory dirlist (without reloading from file)
 func (m *Model) refreshDirlistView() {
 	allDirs := m.dirlist.GetAll()
-	m.dirlistDirs = m.dirlist.SortedDirs()
 	m.dirlistSelections = make(map[string]bool)
 	for dir, enabled := range allDirs {
 		m.dirlistSelections[dir] = enabled
 	}
-	// Keep cursor in bounds
-	if m.dirlistCursor >= len(m.dirlistDirs) {
-		m.dirlistCursor = len(m.dirlistDirs) - 1
-	}
-	if m.dirlistCursor < 0 {
-		m.dirlistCursor = 0
-	}
+	m.applyDirlistQuery()
 }
 
 // updateMenuSizes updates menu dimensions based on window size
@@ -762,6 +1067,9 @@ func (m Model) View() string {
 	if m.quitting {
 		return ""
 	}
+	if m.confirm.active {
+		return m.viewConfirm()
+	}
 
 	switch m.screen {
 	case ScreenMain:
@@ -784,6 +1092,16 @@ func (m Model) View() string {
 		return m.viewFilePicker()
 	case ScreenSnapshots:
 		return m.viewSnapshots()
+	case ScreenSnapshotBrowser:
+		return m.viewSnapshotBrowser()
+	case ScreenSettings:
+		return m.viewSettings()
+	case ScreenHistory:
+		return m.viewHistory()
+	case ScreenKeys:
+		return m.viewKeys()
+	case ScreenStats:
+		return m.viewStats()
 	}
 
 	return ""
@@ -798,11 +1116,38 @@ func (m Model) viewMainMenu() string {
 		SuccessStyle.Render(fmt.Sprintf("%d enabled", enabled)),
 		total)
 
+	// Menu and dashboard side by side on wide terminals, stacked otherwise
+	bodyHeight := m.height - 8
+	if bodyHeight < 5 {
+		bodyHeight = 5
+	}
+	menu := m.mainMenu
+	var body string
+	if m.width >= 110 {
+		menuWidth := 44
+		menu.SetSize(menuWidth, bodyHeight)
+		dashboard := m.viewDashboard(m.width-menuWidth-8, bodyHeight-2)
+		body = lipgloss.JoinHorizontal(lipgloss.Top,
+			lipgloss.NewStyle().Width(menuWidth).Render(menu.View()),
+			MenuBoxStyle.Render(dashboard))
+	} else {
+		// The main menu needs two lines per item plus title and pagination
+		menuHeight := len(menu.Items())*2 + 5
+		if menuHeight > bodyHeight {
+			menuHeight = bodyHeight
+		}
+		menu.SetSize(m.width-4, menuHeight)
+		body = menu.View()
+		if rest := bodyHeight - menuHeight - 1; rest >= 6 {
+			body = lipgloss.JoinVertical(lipgloss.Left, body, "", m.viewDashboard(m.width-4, rest))
+		}
+	}
+
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
 		title,
 		"",
-		m.mainMenu.View(),
+		body,
 		"",
 		MutedStyle.Render(status),
 	)
@@ -886,11 +1231,12 @@ func (m Model) viewStatusMenu() string {
 // viewDirlist renders the directory list screen
 func (m Model) viewDirlist() string {
 	title := TitleStyle.Render("Directory Selection")
-	instructions := MutedStyle.Render("↑/↓: Navigate  ENTER/SPACE: Toggle  S: Save  A: All On  N: All Off  X: Add External  D: Remove External  ESC: Back  Q: Quit")
-	legend := fmt.Sprintf("%s = Will be backed up    %s = Will be skipped    %s = External path",
+	instructions := MutedStyle.Render("↑/↓: Navigate  ENTER/SPACE: Toggle  S: Save  A: All On  N: All Off  X: Add External  D: Remove External  M: Mark  B: Back Up Marked Now  /: Search  F: Filter  O: Sort  ESC: Back  Q: Quit")
+	legend := fmt.Sprintf("%s = Will be backed up    %s = Will be skipped    %s = External path    %s = Marked for backup now",
 		EnabledStyle.Render("✓ BACKUP"),
 		DisabledStyle.Render("✗ SKIP"),
-		CyanStyle.Render("[EXT]"))
+		CyanStyle.Render("[EXT]"),
+		WarningStyle.Render("●"))
 
 	var rows strings.Builder
 	for i, dir := range m.dirlistDirs {
@@ -900,6 +1246,10 @@ func (m Model) viewDirlist() string {
 		}
 
 		status := StatusIcon(m.dirlistSelections[dir])
+		mark := " "
+		if m.dirlistMarked[dir] {
+			mark = WarningStyle.Render("●")
+		}
 
 		// Check if this is an external entry
 		entry := m.dirlist.GetEntry(dir)
@@ -913,12 +1263,15 @@ func (m Model) viewDirlist() string {
 			}
 		}
 
-		line := fmt.Sprintf("%s%s  %s%s", cursor, status, extMarker, displayName)
+		line := fmt.Sprintf("%s%s %s %s%s", cursor, mark, status, extMarker, displayName)
 		if i == m.dirlistCursor {
 			line = lipgloss.NewStyle().Bold(true).Render(line)
 		}
 		rows.WriteString(line + "\n")
 	}
+	if len(m.dirlistDirs) == 0 {
+		rows.WriteString(MutedStyle.Render("  No entries match the search and filter") + "\n")
+	}
 
 	enabledCount := 0
 	for _, enabled := range m.dirlistSelections {
@@ -930,21 +1283,42 @@ func (m Model) viewDirlist() string {
 		len(m.dirlistSelections),
 		SuccessStyle.Render(fmt.Sprintf("Enabled: %d", enabledCount)),
 		DisabledStyle.Render(fmt.Sprintf("Disabled: %d", len(m.dirlistSelections)-enabledCount)))
+	if len(m.dirlistDirs) != len(m.dirlistSelections) {
+		summary += fmt.Sprintf(" | Shown: %d", len(m.dirlistDirs))
+	}
 
 	modified := ""
 	if m.dirlistModified {
 		modified = WarningStyle.Render(" (unsaved changes)")
 	}
 
+	entries := lipgloss.JoinVertical(lipgloss.Left, rows.String(), summary+modified)
+
+	// Detail pane next to the list on wide terminals, below it otherwise
+	var body string
+	if m.currentDirlistEntry() == "" {
+		body = entries
+	} else if m.width >= 110 {
+		listWidth := m.width / 2
+		body = lipgloss.JoinHorizontal(lipgloss.Top,
+			lipgloss.NewStyle().Width(listWidth).Render(entries),
+			MenuBoxStyle.Render(m.viewStackDetail(m.width-listWidth-6)))
+	} else {
+		detailWidth := m.width - 4
+		if detailWidth < 40 {
+			detailWidth = 40
+		}
+		body = lipgloss.JoinVertical(lipgloss.Left, entries, "", m.viewStackDetail(detailWidth))
+	}
+
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
 		title,
 		instructions,
 		legend,
+		m.dirlistQuery.view(false),
 		"",
-		rows.String(),
-		"",
-		summary+modified,
+		body,
 	)
 }
 
@@ -960,6 +1334,14 @@ func (m Model) viewOutput() string {
 	}
 
 	footer := Footer("ESC: Back │ ↑/↓/PgUp/PgDn: Scroll │ Home/End: Top/Bottom" + scrollInfo)
+	switch {
+	case m.backupRun.running && m.cancelling:
+		footer = Footer("Cancelling, waiting for the stack to restart │ ↑/↓/PgUp/PgDn: Scroll" + scrollInfo)
+	case m.backupRun.running:
+		footer = Footer("X: Cancel │ ↑/↓/PgUp/PgDn: Scroll │ Home/End: Top/Bottom" + scrollInfo)
+	case m.events != nil && !m.cancelling:
+		footer = Footer("X: Cancel │ ESC: Back │ ↑/↓/PgUp/PgDn: Scroll │ Home/End: Top/Bottom" + scrollInfo)
+	}
 
 	if !m.outputReady {
 		return lipgloss.JoinVertical(
@@ -972,6 +1354,31 @@ func (m Model) viewOutput() string {
 		)
 	}
 
+	panel := ""
+	switch {
+	case m.transfer.active:
+		panel = m.viewTransferPanel()
+	case m.backupRun.active:
+		panel = m.viewBackupPanel()
+	}
+
+	if panel != "" {
+		// Shrink the viewport to make room for the progress panel
+		vp := m.outputViewport
+		vp.Height -= lipgloss.Height(panel)
+		if vp.Height < 3 {
+			vp.Height = 3
+		}
+		return lipgloss.JoinVertical(
+			lipgloss.Left,
+			title,
+			panel,
+			vp.View(),
+			"",
+			footer,
+		)
+	}
+
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
 		title,
@@ -1023,7 +1430,7 @@ func (m *Model) initSnapshots() {
 		return
 	}
 
-	snapshots, err := restic.ListSnapshots("", 0) // All snapshots
+	snapshots, err := restic.ListSnapshots(m.ctx, "", 0) // All snapshots
 	restic.Cleanup()
 
 	if err != nil {
@@ -1032,18 +1439,40 @@ func (m *Model) initSnapshots() {
 		return
 	}
 
-	m.snapshotList = snapshots
+	m.snapshotAll = snapshots
+	m.applySnapshotQuery()
 	m.snapshotLoading = false
 }
 
 // handleSnapshotsKey handles keys on the snapshots screen
 func (m Model) handleSnapshotsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.snapshotQuery.searching {
+		cmd := m.snapshotQuery.handleSearchKey(msg)
+		m.applySnapshotQuery()
+		return m, cmd
+	}
+
 	switch msg.String() {
 	case "q":
-		m.quitting = true
-		return m, tea.Quit
+		return m.quit()
 	case keyEsc:
+		if m.snapshotQuery.text() != "" {
+			m.snapshotQuery.clearSearch()
+			m.applySnapshotQuery()
+			return m, nil
+		}
 		return m.changeScreen(ScreenRestic)
+	case "/":
+		return m, m.snapshotQuery.startSearch()
+	case "f", "F":
+		m.snapshotQuery.filter = (m.snapshotQuery.filter + 1) % filterCount
+		m.applySnapshotQuery()
+	case "o", "O":
+		m.snapshotQuery.sort = (m.snapshotQuery.sort + 1) % sortCount
+		m.applySnapshotQuery()
+	case "v", "V":
+		m.snapshotQuery.group = (m.snapshotQuery.group + 1) % groupCount
+		m.applySnapshotQuery()
 	case "up", "k":
 		if m.snapshotCursor > 0 {
 			m.snapshotCursor--
@@ -1062,7 +1491,7 @@ func (m Model) handleSnapshotsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.snapshotCursor = len(m.snapshotList) - 1
 			// Calculate YOffset to show cursor at bottom of viewport
 			// (viewport content may not be set yet, so calculate manually)
-			m.snapshotYOffset = m.snapshotCursor - m.snapshotViewport.Height + 1
+			m.snapshotYOffset = m.snapshotLine(m.snapshotCursor) - m.snapshotViewport.Height + 1
 			if m.snapshotYOffset < 0 {
 				m.snapshotYOffset = 0
 			}
@@ -1101,20 +1530,34 @@ func (m Model) handleSnapshotsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Deselect all
 		m.snapshotSelected = make(map[string]bool)
 	case "d":
-		// Delete selected snapshots
-		return m.forgetSelectedSnapshots(false)
+		// Delete selected snapshots after confirmation
+		return m, m.confirmForgetSnapshots()
 	case "D":
 		// Delete selected (dry run)
 		return m.forgetSelectedSnapshots(true)
 	case "p":
-		// Prune repository
-		return m.pruneRepository(false)
+		// Prune repository after confirmation
+		return m, m.confirmPruneRepository()
 	case "P":
 		// Prune repository (dry run)
 		return m.pruneRepository(true)
 	case "r":
 		// Refresh snapshot list
 		m.initSnapshots()
+	case keyEnter, "b":
+		// Browse snapshot contents
+		if len(m.snapshotList) > 0 {
+			return m.openSnapshotBrowser(m.snapshotList[m.snapshotCursor])
+		}
+	case "c":
+		// Compare the two selected snapshots
+		return m.compareSelectedSnapshots()
+	case "m":
+		// Mount highlighted snapshot via FUSE
+		return m.mountSnapshot()
+	case "u":
+		// Unmount the mounted snapshot
+		return m.unmountSnapshot()
 	}
 
 	return m, nil
@@ -1125,20 +1568,57 @@ func (m *Model) ensureSnapshotCursorVisible() {
 	if !m.snapshotVpReady {
 		return
 	}
-	// Each snapshot is one line
-	cursorLine := m.snapshotCursor
+	// Each snapshot is one line, plus one for each group header above it
+	cursorLine := m.snapshotLine(m.snapshotCursor)
+	topLine := cursorLine
+	if _, ok := m.snapshotHeaders[m.snapshotCursor]; ok {
+		topLine-- // Keep the header of the cursor's group in view
+	}
 	viewTop := m.snapshotYOffset
 	viewBottom := viewTop + m.snapshotViewport.Height - 1
 
-	if cursorLine < viewTop {
+	if topLine < viewTop {
 		// Cursor is above viewport - scroll so cursor is at top
-		m.snapshotYOffset = cursorLine
+		m.snapshotYOffset = topLine
 	} else if cursorLine > viewBottom {
 		// Cursor is below viewport - scroll so cursor is at bottom
 		m.snapshotYOffset = cursorLine - m.snapshotViewport.Height + 1
 	}
 }
 
+// confirmForgetSnapshots asks before deleting the selected snapshots
+func (m *Model) confirmForgetSnapshots() tea.Cmd {
+	byID := make(map[string]backup.Snapshot, len(m.snapshotAll))
+	for _, snap := range m.snapshotAll {
+		byID[snap.ShortID] = snap
+	}
+
+	var items []string
+	for _, snap := range m.snapshotAll {
+		if !m.snapshotSelected[snap.ShortID] {
+			continue
+		}
+		when := snap.Time
+		if t, err := time.Parse(time.RFC3339Nano, snap.Time); err == nil {
+			when = t.Local().Format("2006-01-02 15:04")
+		}
+		items = append(items, fmt.Sprintf("%s  %s  %s  %s", snap.ShortID, when, snap.Hostname, snap.StackTag()))
+	}
+	// Selections no longer in the list are still forgotten
+	for id, selected := range m.snapshotSelected {
+		if _, ok := byID[id]; selected && !ok {
+			items = append(items, id)
+		}
+	}
+
+	if len(items) == 0 {
+		m.snapshotErr = "No snapshots selected"
+		return nil
+	}
+	return m.askConfirm(confirmForget, fmt.Sprintf("Forget %d snapshot(s)", len(items)),
+		items, "The snapshots are removed from the repository. Run prune afterwards to free the space.", "forget")
+}
+
 // forgetSelectedSnapshots deletes selected snapshots
 func (m Model) forgetSelectedSnapshots(dryRun bool) (tea.Model, tea.Cmd) {
 	// Get selected snapshot IDs
@@ -1181,7 +1661,7 @@ func (m Model) forgetSelectedSnapshots(dryRun bool) (tea.Model, tea.Cmd) {
 		}
 		defer restic.Cleanup()
 
-		err := restic.ForgetSnapshots(selectedIDs, dryRun)
+		err := restic.ForgetSnapshots(m.ctx, selectedIDs, dryRun)
 		return CommandDoneMsg{
 			Operation: "forget",
 			Err:       err,
@@ -1190,6 +1670,16 @@ func (m Model) forgetSelectedSnapshots(dryRun bool) (tea.Model, tea.Cmd) {
 	}
 }
 
+// confirmPruneRepository asks before pruning the restic repository
+func (m *Model) confirmPruneRepository() tea.Cmd {
+	items := []string{
+		"Repository: " + m.config.LocalBackup.Repository,
+		fmt.Sprintf("Snapshots: %d", len(m.snapshotAll)),
+	}
+	return m.askConfirm(confirmPrune, "Prune repository", items,
+		"Data no longer referenced by any snapshot is deleted for good.", "prune")
+}
+
 // pruneRepository prunes the restic repository
 func (m Model) pruneRepository(dryRun bool) (tea.Model, tea.Cmd) {
 	// Clear output and switch to output screen
@@ -1219,7 +1709,7 @@ func (m Model) pruneRepository(dryRun bool) (tea.Model, tea.Cmd) {
 		}
 		defer restic.Cleanup()
 
-		err := restic.Prune(dryRun)
+		err := restic.Prune(m.ctx, dryRun)
 		return CommandDoneMsg{
 			Operation: "prune",
 			Err:       err,
@@ -1231,7 +1721,7 @@ func (m Model) pruneRepository(dryRun bool) (tea.Model, tea.Cmd) {
 // viewSnapshots renders the snapshot management screen
 func (m Model) viewSnapshots() string {
 	title := TitleStyle.Render("Snapshot Management")
-	instructions := MutedStyle.Render("↑/↓/PgUp/PgDn: Navigate  SPACE: Toggle  A: All  N: None  D: Delete  P: Prune  R: Refresh  ESC: Back")
+	instructions := MutedStyle.Render("↑/↓/PgUp/PgDn: Navigate  ENTER/B: Browse  SPACE: Toggle  C: Compare  M: Mount  A: All  N: None  D: Delete  P: Prune  R: Refresh  /: Search  F: Filter  O: Sort  V: Group  ESC: Back")
 
 	if m.snapshotLoading {
 		return lipgloss.JoinVertical(
@@ -1255,7 +1745,7 @@ func (m Model) viewSnapshots() string {
 		)
 	}
 
-	if len(m.snapshotList) == 0 {
+	if len(m.snapshotAll) == 0 {
 		return lipgloss.JoinVertical(
 			lipgloss.Left,
 			title,
@@ -1270,9 +1760,15 @@ func (m Model) viewSnapshots() string {
 	// Build all snapshot rows
 	var rows strings.Builder
 	for i, snap := range m.snapshotList {
+		if header, ok := m.snapshotHeaders[i]; ok {
+			rows.WriteString(CyanStyle.Render("── "+header+" ──") + "\n")
+		}
 		line := m.formatSnapshotLine(i, snap)
 		rows.WriteString(line + "\n")
 	}
+	if len(m.snapshotList) == 0 {
+		rows.WriteString(MutedStyle.Render("  No snapshots match the search and filter") + "\n")
+	}
 
 	// Set viewport content
 	content := strings.TrimSuffix(rows.String(), "\n")
@@ -1297,7 +1793,13 @@ func (m Model) viewSnapshots() string {
 		scrollInfo = fmt.Sprintf(" │ %.0f%%", scrollPercent*100)
 	}
 
-	summary := fmt.Sprintf("Selected: %d | Total: %d%s", selectedCount, len(m.snapshotList), scrollInfo)
+	summary := fmt.Sprintf("Selected: %d | Total: %d%s", selectedCount, len(m.snapshotAll), scrollInfo)
+	if len(m.snapshotList) != len(m.snapshotAll) {
+		summary = fmt.Sprintf("Selected: %d | Shown: %d of %d%s", selectedCount, len(m.snapshotList), len(m.snapshotAll), scrollInfo)
+	}
+	if status := m.viewMountStatus(); status != "" {
+		summary += "\n" + status
+	}
 
 	footer := Footer("D: Delete (Shift+D: Dry Run) | P: Prune (Shift+P: Dry Run) | ESC: Back | Q: Quit")
 
@@ -1305,7 +1807,7 @@ func (m Model) viewSnapshots() string {
 		lipgloss.Left,
 		title,
 		instructions,
-		"",
+		m.snapshotQuery.view(true),
 		m.snapshotViewport.View(),
 		"",
 		summary,
@@ -1367,10 +1869,20 @@ func (m Model) formatSnapshotLine(idx int, snap backup.Snapshot) string {
 	return line
 }
 
-// Run starts the TUI application
-func Run(cfg *config.Config) error {
+// Run starts the TUI application.
+// Cancelling ctx cancels the running operation and quits once it has returned.
+func Run(ctx context.Context, cfg *config.Config) error {
 	model := NewModel(cfg)
+	model.ctx = ctx
 	p := tea.NewProgram(model, tea.WithAltScreen())
-	_, err := p.Run()
+
+	// Shut down gracefully when ctx is cancelled (e.g. by a signal)
+	stop := context.AfterFunc(ctx, func() { p.Send(shutdownMsg{}) })
+	defer stop()
+
+	final, err := p.Run()
+	if fm, ok := final.(Model); ok {
+		fm.releaseMount()
+	}
 	return err
 }
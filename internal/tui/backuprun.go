@@ -0,0 +1,208 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"backup-tui/internal/backup"
+	"backup-tui/internal/util"
+)
+
+const (
+	// backupProgressInterval throttles restic status updates sent to the update loop
+	backupProgressInterval = 250 * time.Millisecond
+	// maxBackupPanelStacks limits how many stacks the progress panel lists at once
+	maxBackupPanelStacks = 8
+)
+
+// stackProgress is the progress panel state of one stack
+type stackProgress struct {
+	name     string
+	phase    backup.EventKind
+	progress *backup.BackupProgress
+	err      error
+	started  time.Time
+	finished time.Time
+}
+
+// backupRunState holds the progress panel state of an in-process backup
+type backupRunState struct {
+	active  bool // Panel is shown
+	running bool // Backup goroutine has not finished yet
+	stacks  []stackProgress
+	current int // 1-based index of the stack being processed
+}
+
+// stack returns the panel entry for name, or nil if it is unknown
+func (b *backupRunState) stack(name string) *stackProgress {
+	for i := range b.stacks {
+		if b.stacks[i].name == name {
+			return &b.stacks[i]
+		}
+	}
+	return nil
+}
+
+// apply updates the panel state from a backup event
+func (b *backupRunState) apply(ev backup.Event) {
+	if ev.Kind == backup.EventRunStarted {
+		b.stacks = make([]stackProgress, len(ev.Stacks))
+		for i, name := range ev.Stacks {
+			b.stacks[i] = stackProgress{name: name, phase: backup.EventRunStarted}
+		}
+		return
+	}
+
+	s := b.stack(ev.Stack)
+	if s == nil {
+		return
+	}
+	if ev.Index > 0 && ev.Kind != backup.EventStackSkipped {
+		b.current = ev.Index
+	}
+
+	switch ev.Kind {
+	case backup.EventStackStarted:
+		s.started = time.Now()
+	case backup.EventBackupProgress:
+		s.progress = ev.Progress
+	case backup.EventStackDone, backup.EventStackFailed, backup.EventStackSkipped:
+		s.finished = time.Now()
+		s.err = ev.Err
+	}
+	s.phase = ev.Kind
+}
+
+// executeBackup runs the backup in-process and streams typed events into the progress panel.
+// A non-empty only limits the run to those dirlist entries, see backup.Service.SetSelection.
+func (m *Model) executeBackup(only []string) tea.Cmd {
+	m.backupRun = backupRunState{active: true, running: true}
+	cfg := m.config
+
+	return m.startStream(func(ctx context.Context, emit emitFunc) tea.Msg {
+		startTime := time.Now()
+		done := func(err error) tea.Msg {
+			return CommandDoneMsg{Operation: "backup", Err: err, Duration: time.Since(startTime)}
+		}
+
+		if err := cfg.Validate(); err != nil {
+			return done(fmt.Errorf("configuration error: %w", err))
+		}
+
+		svc := backup.NewServiceWithOutput(cfg, false, true, &streamWriter{emit})
+		svc.SetSelection(only, nil)
+		var lastProgress time.Time
+		svc.SetEventFunc(func(ev backup.Event) {
+			if ev.Kind == backup.EventBackupProgress {
+				if time.Since(lastProgress) < backupProgressInterval && ev.Progress.PercentDone < 1 {
+					return
+				}
+				lastProgress = time.Now()
+			}
+			emit(BackupEventMsg{Event: ev})
+		})
+
+		return done(svc.Run(ctx))
+	})
+}
+
+// viewBackupPanel renders the multi-stack progress panel shown above the output viewport
+func (m Model) viewBackupPanel() string {
+	b := m.backupRun
+	width := m.width - 4
+	if width < 30 {
+		width = 30
+	}
+
+	if len(b.stacks) == 0 {
+		return MenuBoxStyle.Width(width).Render(CyanStyle.Render("Backup") + "  " + MutedStyle.Render("running pre-flight checks..."))
+	}
+
+	finished := 0
+	for _, s := range b.stacks {
+		if !s.finished.IsZero() {
+			finished++
+		}
+	}
+	total := len(b.stacks)
+	barWidth := width - 30
+	if barWidth > 50 {
+		barWidth = 50
+	}
+	header := fmt.Sprintf("%s  Stack %d/%d  %s %d%%", CyanStyle.Render("Backup"),
+		b.current, total, progressBar(float64(finished)/float64(total), barWidth), finished*100/total)
+	if m.cancelling && b.running {
+		header += "  " + WarningStyle.Render("cancelling…")
+	}
+	lines := []string{header}
+
+	// Show a window of stacks around the current one
+	start := b.current - maxBackupPanelStacks/2
+	if start > total-maxBackupPanelStacks {
+		start = total - maxBackupPanelStacks
+	}
+	if start < 0 {
+		start = 0
+	}
+	end := start + maxBackupPanelStacks
+	if end > total {
+		end = total
+	}
+	if start > 0 {
+		lines = append(lines, MutedStyle.Render(fmt.Sprintf("  … %d earlier", start)))
+	}
+	for _, s := range b.stacks[start:end] {
+		lines = append(lines, formatStackProgress(s, width))
+	}
+	if end < total {
+		lines = append(lines, MutedStyle.Render(fmt.Sprintf("  … %d more", total-end)))
+	}
+
+	return MenuBoxStyle.Width(width).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// formatStackProgress renders one stack line of the progress panel
+func formatStackProgress(s stackProgress, width int) string {
+	nameWidth := 24
+	name := fmt.Sprintf("%-*s", nameWidth, truncateLeft(s.name, nameWidth))
+
+	switch s.phase {
+	case backup.EventRunStarted:
+		return MutedStyle.Render("  · " + name + "  pending")
+	case backup.EventStackDone:
+		return SuccessStyle.Render("  ✓ "+name) + MutedStyle.Render("  done in "+s.finished.Sub(s.started).Round(time.Second).String())
+	case backup.EventStackFailed:
+		msg := "failed"
+		if s.err != nil {
+			msg = "failed: " + s.err.Error()
+		}
+		return ErrorStyle.Render("  ✗ "+name) + "  " + ErrorStyle.Render(truncateRight(msg, width-nameWidth-8))
+	case backup.EventStackSkipped:
+		return MutedStyle.Render("  - " + name + "  skipped")
+	case backup.EventBackupProgress:
+		if p := s.progress; p != nil {
+			detail := fmt.Sprintf("%s / %s  •  %d/%d files",
+				util.FormatSize(p.BytesDone), util.FormatSize(p.TotalBytes), p.FilesDone, p.TotalFiles)
+			if p.Remaining > 0 {
+				detail += "  •  ETA " + p.Remaining.Round(time.Second).String()
+			}
+			return CyanStyle.Render("  ▶ "+name) + "  " + progressBar(p.PercentDone, 20) +
+				fmt.Sprintf(" %3.0f%%  ", p.PercentDone*100) + MutedStyle.Render(detail)
+		}
+	}
+
+	phase := s.phase.String()
+	switch s.phase {
+	case backup.EventStackStarted:
+		phase = "stopping"
+	case backup.EventStackStopped:
+		phase = "backing up"
+	case backup.EventStackRestarted:
+		phase = "restarted"
+	}
+	return CyanStyle.Render("  ▶ "+name) + "  " + phase + "…"
+}
@@ -0,0 +1,558 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"backup-tui/internal/backup"
+	"backup-tui/internal/util"
+)
+
+// maxSearchResults limits how many matches the snapshot browser search shows
+const maxSearchResults = 1000
+
+// browserNode is a file or directory in the snapshot browser tree
+type browserNode struct {
+	name     string
+	path     string
+	isDir    bool
+	size     int64 // For directories, the total size of all contained files
+	parent   *browserNode
+	children []*browserNode
+}
+
+// snapshotBrowser holds the state of the snapshot file browser screen
+type snapshotBrowser struct {
+	snapshot backup.Snapshot
+	loading  bool
+	err      string
+
+	root     *browserNode
+	dir      *browserNode // Directory currently shown
+	cursor   int
+	offset   int             // First visible row
+	selected map[string]bool // Selected paths for restore
+
+	// Search state: results is non-nil while a search filter is active
+	search    textinput.Model
+	searching bool
+	results   []*browserNode
+
+	// Restore target prompt
+	target    textinput.Model
+	prompting bool
+}
+
+// buildBrowserTree builds a directory tree from a flat restic ls listing
+func buildBrowserTree(nodes []backup.SnapshotNode) *browserNode {
+	root := &browserNode{name: "/", path: "/", isDir: true}
+	index := map[string]*browserNode{"/": root}
+
+	var ensureDir func(p string) *browserNode
+	ensureDir = func(p string) *browserNode {
+		if n, ok := index[p]; ok {
+			return n
+		}
+		parent := ensureDir(path.Dir(p))
+		n := &browserNode{name: path.Base(p), path: p, isDir: true, parent: parent}
+		parent.children = append(parent.children, n)
+		index[p] = n
+		return n
+	}
+
+	for _, sn := range nodes {
+		if sn.IsDir() {
+			ensureDir(sn.Path)
+			continue
+		}
+		if _, exists := index[sn.Path]; exists {
+			continue
+		}
+		parent := ensureDir(path.Dir(sn.Path))
+		n := &browserNode{name: sn.Name, path: sn.Path, size: sn.Size, parent: parent}
+		parent.children = append(parent.children, n)
+		index[sn.Path] = n
+	}
+
+	finalizeBrowserNode(root)
+	return root
+}
+
+// finalizeBrowserNode sorts children (directories first) and sums directory sizes
+func finalizeBrowserNode(n *browserNode) int64 {
+	if !n.isDir {
+		return n.size
+	}
+	var total int64
+	for _, c := range n.children {
+		total += finalizeBrowserNode(c)
+	}
+	n.size = total
+	sort.Slice(n.children, func(i, j int) bool {
+		a, b := n.children[i], n.children[j]
+		if a.isDir != b.isDir {
+			return a.isDir
+		}
+		return a.name < b.name
+	})
+	return total
+}
+
+// startDir skips single-directory chains so the browser opens at the backed-up directory
+func (n *browserNode) startDir() *browserNode {
+	cur := n
+	for len(cur.children) == 1 && cur.children[0].isDir {
+		cur = cur.children[0]
+	}
+	return cur
+}
+
+// entries returns the rows currently shown: search results or the current directory
+func (b *snapshotBrowser) entries() []*browserNode {
+	if b.results != nil {
+		return b.results
+	}
+	if b.dir == nil {
+		return nil
+	}
+	return b.dir.children
+}
+
+// current returns the node under the cursor
+func (b *snapshotBrowser) current() *browserNode {
+	entries := b.entries()
+	if b.cursor < 0 || b.cursor >= len(entries) {
+		return nil
+	}
+	return entries[b.cursor]
+}
+
+// openDir changes into a directory, optionally placing the cursor on a child
+func (b *snapshotBrowser) openDir(dir, focus *browserNode) {
+	b.dir = dir
+	b.results = nil
+	b.cursor = 0
+	b.offset = 0
+	if focus != nil {
+		for i, c := range dir.children {
+			if c == focus {
+				b.cursor = i
+				break
+			}
+		}
+	}
+}
+
+// runSearch collects all nodes whose name contains the query
+func (b *snapshotBrowser) runSearch(query string) {
+	query = strings.ToLower(strings.TrimSpace(query))
+	b.cursor = 0
+	b.offset = 0
+	if query == "" {
+		b.results = nil
+		return
+	}
+
+	results := []*browserNode{}
+	var walk func(n *browserNode)
+	walk = func(n *browserNode) {
+		for _, c := range n.children {
+			if len(results) >= maxSearchResults {
+				return
+			}
+			if strings.Contains(strings.ToLower(c.name), query) {
+				results = append(results, c)
+			}
+			if c.isDir {
+				walk(c)
+			}
+		}
+	}
+	walk(b.root)
+	b.results = results
+}
+
+// selectedPaths returns the selected paths in sorted order
+func (b *snapshotBrowser) selectedPaths() []string {
+	var paths []string
+	for p, ok := range b.selected {
+		if ok {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// browserVisibleRows returns how many entry rows fit on screen
+func (m Model) browserVisibleRows() int {
+	rows := m.height - 10
+	if rows < 5 {
+		rows = 5
+	}
+	return rows
+}
+
+// ensureBrowserCursorVisible adjusts the browser offset to keep the cursor on screen
+func (m *Model) ensureBrowserCursorVisible() {
+	rows := m.browserVisibleRows()
+	b := &m.browser
+	if b.cursor < b.offset {
+		b.offset = b.cursor
+	} else if b.cursor >= b.offset+rows {
+		b.offset = b.cursor - rows + 1
+	}
+}
+
+// openSnapshotBrowser switches to the browser for a snapshot and loads its contents
+func (m Model) openSnapshotBrowser(snap backup.Snapshot) (tea.Model, tea.Cmd) {
+	search := textinput.New()
+	search.Placeholder = "file or directory name"
+	search.Prompt = "/"
+
+	target := textinput.New()
+	target.Prompt = "Restore to: "
+	target.SetValue(fmt.Sprintf("/tmp/restore_%s_%s", snap.ShortID, time.Now().Format("20060102_150405")))
+
+	m.browser = snapshotBrowser{
+		snapshot: snap,
+		loading:  true,
+		selected: make(map[string]bool),
+		search:   search,
+		target:   target,
+	}
+	m.prevScreen = m.screen
+	m.screen = ScreenSnapshotBrowser
+
+	return m, m.loadSnapshotFiles(snap)
+}
+
+// loadSnapshotFiles lists the contents of a snapshot in the background
+func (m Model) loadSnapshotFiles(snap backup.Snapshot) tea.Cmd {
+	return func() tea.Msg {
+		restic := backup.NewResticManager(&m.config.LocalBackup, false, nil)
+		if err := restic.SetupEnv(); err != nil {
+			return SnapshotFilesMsg{SnapshotID: snap.ID, Err: err}
+		}
+		defer restic.Cleanup()
+
+		nodes, err := restic.ListFiles(m.ctx, snap.ID)
+		return SnapshotFilesMsg{SnapshotID: snap.ID, Nodes: nodes, Err: err}
+	}
+}
+
+// handleSnapshotFiles builds the browser tree once the snapshot listing arrives
+func (m Model) handleSnapshotFiles(msg SnapshotFilesMsg) (tea.Model, tea.Cmd) {
+	if msg.SnapshotID != m.browser.snapshot.ID {
+		return m, nil
+	}
+	m.browser.loading = false
+	if msg.Err != nil {
+		m.browser.err = msg.Err.Error()
+		return m, nil
+	}
+	m.browser.root = buildBrowserTree(msg.Nodes)
+	m.browser.openDir(m.browser.root.startDir(), nil)
+	return m, nil
+}
+
+// handleBrowserKey handles keys on the snapshot browser screen
+func (m Model) handleBrowserKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	b := &m.browser
+
+	if b.prompting {
+		switch msg.String() {
+		case keyEsc:
+			b.prompting = false
+			b.target.Blur()
+			return m, nil
+		case keyEnter:
+			b.prompting = false
+			b.target.Blur()
+			target := strings.TrimSpace(b.target.Value())
+			// Restoring to / overwrites the live files
+			if filepath.Clean(target) == "/" && len(b.selectedPaths()) > 0 {
+				return m, m.askConfirm(confirmRestoreInPlace,
+					fmt.Sprintf("Restore %d path(s) in place from snapshot %s", len(b.selectedPaths()), b.snapshot.ShortID),
+					b.selectedPaths(), "Current files at these paths are overwritten with the snapshot contents.", "restore")
+			}
+			return m.restoreSelectedPaths(target)
+		}
+		var cmd tea.Cmd
+		b.target, cmd = b.target.Update(msg)
+		return m, cmd
+	}
+
+	if b.searching {
+		switch msg.String() {
+		case keyEsc:
+			b.searching = false
+			b.search.Blur()
+			b.search.SetValue("")
+			b.runSearch("")
+			return m, nil
+		case keyEnter:
+			b.searching = false
+			b.search.Blur()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		b.search, cmd = b.search.Update(msg)
+		b.runSearch(b.search.Value())
+		return m, cmd
+	}
+
+	entries := b.entries()
+	switch msg.String() {
+	case "q":
+		return m.quit()
+	case keyEsc:
+		if b.results != nil {
+			b.search.SetValue("")
+			b.runSearch("")
+			return m, nil
+		}
+		m.screen = ScreenSnapshots
+		return m, nil
+	case "up", "k":
+		if b.cursor > 0 {
+			b.cursor--
+		}
+	case "down", "j":
+		if b.cursor < len(entries)-1 {
+			b.cursor++
+		}
+	case "pgup", "ctrl+u":
+		b.cursor -= m.browserVisibleRows()
+		if b.cursor < 0 {
+			b.cursor = 0
+		}
+	case "pgdown", "ctrl+d":
+		b.cursor += m.browserVisibleRows()
+		if b.cursor >= len(entries) {
+			b.cursor = len(entries) - 1
+		}
+		if b.cursor < 0 {
+			b.cursor = 0
+		}
+	case "home", "g":
+		b.cursor = 0
+	case "end", "G":
+		if len(entries) > 0 {
+			b.cursor = len(entries) - 1
+		}
+	case keyEnter, "right", "l":
+		if n := b.current(); n != nil {
+			if n.isDir {
+				b.openDir(n, nil)
+			} else if b.results != nil {
+				// Jump to the file's directory
+				b.search.SetValue("")
+				b.openDir(n.parent, n)
+			}
+		}
+	case "backspace", "left", "h":
+		if b.results == nil && b.dir != nil && b.dir.parent != nil {
+			b.openDir(b.dir.parent, b.dir)
+		}
+	case " ":
+		if n := b.current(); n != nil {
+			b.selected[n.path] = !b.selected[n.path]
+		}
+	case "c":
+		b.selected = make(map[string]bool)
+	case "/":
+		if b.root != nil {
+			b.searching = true
+			b.search.Focus()
+			return m, textinput.Blink
+		}
+	case "r":
+		if len(b.selectedPaths()) == 0 {
+			if n := b.current(); n != nil {
+				b.selected[n.path] = true
+			}
+		}
+		if len(b.selectedPaths()) > 0 {
+			b.prompting = true
+			b.target.Focus()
+			b.target.CursorEnd()
+			return m, textinput.Blink
+		}
+	}
+
+	m.ensureBrowserCursorVisible()
+	return m, nil
+}
+
+// restoreSelectedPaths restores the selected snapshot paths into target
+func (m Model) restoreSelectedPaths(target string) (tea.Model, tea.Cmd) {
+	if m.busy() {
+		return m, nil
+	}
+	paths := m.browser.selectedPaths()
+	if len(paths) == 0 {
+		return m, nil
+	}
+	if !filepath.IsAbs(target) {
+		m.browser.err = "Restore target must be an absolute path"
+		return m, nil
+	}
+
+	snap := m.browser.snapshot
+	var intro strings.Builder
+	fmt.Fprintf(&intro, "Restoring %d path(s) from snapshot %s\nTarget: %s\n\n", len(paths), snap.ShortID, target)
+	for _, p := range paths {
+		fmt.Fprintf(&intro, "  %s\n", p)
+	}
+	intro.WriteString("\n")
+
+	m.resetOutput("Restore Files", intro.String())
+	return m, m.startStream(func(ctx context.Context, emit emitFunc) tea.Msg {
+		startTime := time.Now()
+		done := func(err error) tea.Msg {
+			return CommandDoneMsg{Operation: "restore-files", Err: err, Duration: time.Since(startTime)}
+		}
+
+		if err := os.MkdirAll(target, 0o755); err != nil {
+			return done(fmt.Errorf("cannot create restore target: %w", err))
+		}
+
+		restic := backup.NewResticManager(&m.config.LocalBackup, false, &streamWriter{emit})
+		if err := restic.SetupEnv(); err != nil {
+			return done(err)
+		}
+		defer restic.Cleanup()
+
+		return done(restic.RestorePaths(ctx, snap.ID, target, paths))
+	})
+}
+
+// viewSnapshotBrowser renders the snapshot browser screen
+func (m Model) viewSnapshotBrowser() string {
+	b := m.browser
+	snap := b.snapshot
+
+	snapTime := snap.Time
+	if t, err := time.Parse(time.RFC3339Nano, snap.Time); err == nil {
+		snapTime = t.Format("2006-01-02 15:04")
+	}
+	title := TitleStyle.Render("Snapshot Browser")
+	header := CyanStyle.Render(fmt.Sprintf("Snapshot %s  %s  %s", snap.ShortID, snapTime, snap.Hostname))
+	if len(snap.Tags) > 0 {
+		header += MutedStyle.Render("  [" + strings.Join(snap.Tags, ", ") + "]")
+	}
+	instructions := MutedStyle.Render("↑/↓: Navigate  ENTER/→: Open  ←/BKSP: Up  SPACE: Select  /: Search  R: Restore  C: Clear  ESC: Back")
+
+	if b.loading {
+		return lipgloss.JoinVertical(lipgloss.Left, title, header, "", "Loading snapshot contents...")
+	}
+	if b.root == nil {
+		return lipgloss.JoinVertical(lipgloss.Left, title, header, "",
+			ErrorStyle.Render("Error: "+b.err), "", Footer("ESC: Back"))
+	}
+
+	location := "Path: " + b.dir.path
+	if b.results != nil {
+		location = fmt.Sprintf("Search results: %d", len(b.results))
+		if len(b.results) >= maxSearchResults {
+			location += " (limited)"
+		}
+	}
+
+	var rows strings.Builder
+	entries := b.entries()
+	visible := m.browserVisibleRows()
+	end := b.offset + visible
+	if end > len(entries) {
+		end = len(entries)
+	}
+	for i := b.offset; i < end; i++ {
+		rows.WriteString(m.formatBrowserLine(i, entries[i]) + "\n")
+	}
+	if len(entries) == 0 {
+		rows.WriteString(MutedStyle.Render("  (empty)") + "\n")
+	}
+
+	// Selection summary
+	selected := b.selectedPaths()
+	summary := fmt.Sprintf("Selected: %d", len(selected))
+	if len(entries) > visible {
+		summary += fmt.Sprintf(" │ %d-%d of %d", b.offset+1, end, len(entries))
+	}
+
+	var prompt string
+	switch {
+	case b.prompting:
+		prompt = b.target.View() + MutedStyle.Render("  (ENTER: Restore  ESC: Cancel)")
+	case b.searching || b.results != nil:
+		prompt = b.search.View()
+	}
+
+	var errMsg string
+	if b.err != "" {
+		errMsg = ErrorStyle.Render("Error: " + b.err)
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		header,
+		instructions,
+		CyanStyle.Render(location),
+		"",
+		strings.TrimSuffix(rows.String(), "\n"),
+		"",
+		summary,
+		prompt,
+		errMsg,
+	)
+}
+
+// formatBrowserLine formats a single entry in the snapshot browser
+func (m Model) formatBrowserLine(idx int, n *browserNode) string {
+	b := m.browser
+
+	cursor := "  "
+	if idx == b.cursor {
+		cursor = "> "
+	}
+	checkbox := "[ ]"
+	if b.selected[n.path] {
+		checkbox = "[x]"
+	}
+
+	name := n.name
+	if b.results != nil {
+		name = n.path
+	}
+	if n.isDir {
+		name += "/"
+	}
+
+	nameWidth := m.width - 20
+	if nameWidth < 20 {
+		nameWidth = 20
+	}
+	name = truncateLeft(name, nameWidth)
+	padded := fmt.Sprintf("%-*s", nameWidth, name)
+	if n.isDir {
+		padded = CyanStyle.Render(padded)
+	}
+
+	line := fmt.Sprintf("%s%s %s %10s", cursor, checkbox, padded, util.FormatSize(n.size))
+	if idx == b.cursor {
+		line = lipgloss.NewStyle().Bold(true).Render(line)
+	}
+	return line
+}
@@ -1,6 +1,7 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -26,6 +27,8 @@ func (m *Model) resetOutput(title, initialContent string) {
 	m.outputContent.WriteString(initialContent)
 	m.prevScreen = m.screen
 	m.screen = ScreenOutput
+	m.transfer = transferState{}
+	m.backupRun = backupRunState{}
 
 	// Update viewport content if ready
 	if m.outputReady {
@@ -39,25 +42,36 @@ func (m *Model) resetOutput(title, initialContent string) {
 // ============================================================================
 
 func (m Model) runQuickBackup() (tea.Model, tea.Cmd) {
+	if m.busy() {
+		return m, nil
+	}
 	m.resetOutput("Quick Backup", "Starting backup...\n\nThis will stop Docker containers, backup data, and restart them.\n\n")
-	return m, m.executeBackup(false)
+	return m, m.executeBackup(nil)
+}
+
+// runSelectedBackup backs up the marked dirlist entries right away, whether
+// or not they are enabled and without saving the dirlist
+func (m Model) runSelectedBackup() (tea.Model, tea.Cmd) {
+	if m.busy() {
+		return m, nil
+	}
+	only := m.markedDirs()
+	if len(only) == 0 {
+		return m, nil
+	}
+	m.resetOutput("Backup Selected", "Backing up now: "+strings.Join(only, ", ")+
+		"\n\nThis will stop Docker containers, backup data, and restart them.\n\n")
+	return m, m.executeBackup(only)
 }
 
 func (m Model) runDryRunBackup() (tea.Model, tea.Cmd) {
+	if m.busy() {
+		return m, nil
+	}
 	m.resetOutput("Dry Run Backup", "Running backup dry run...\n\nThis shows what would be backed up without making changes.\n\n")
 	return m, m.executeDryRunBackup()
 }
 
-// executeBackup runs the backup operation using tea.ExecProcess for real-time output
-func (m Model) executeBackup(dryRun bool) tea.Cmd {
-	return tea.ExecProcess(
-		m.buildBackupCommand(dryRun),
-		func(err error) tea.Msg {
-			return CommandDoneMsg{Operation: "backup", Err: err}
-		},
-	)
-}
-
 // executeDryRunBackup runs backup dry run and captures output for the viewport
 func (m Model) executeDryRunBackup() tea.Cmd {
 	return func() tea.Msg {
@@ -85,6 +99,9 @@ func (m Model) buildBackupCommand(dryRun bool) *exec.Cmd {
 }
 
 func (m Model) showSnapshots() (tea.Model, tea.Cmd) {
+	if m.busy() {
+		return m, nil
+	}
 	m.resetOutput("Backup Snapshots", "Loading snapshots from repository...\n\n")
 
 	return m, func() tea.Msg {
@@ -101,7 +118,7 @@ func (m Model) showSnapshots() (tea.Model, tea.Cmd) {
 		}
 		defer restic.Cleanup()
 
-		snapshots, err := restic.ListSnapshots("", 20) // No tag filter, limit to 20
+		snapshots, err := restic.ListSnapshots(m.ctx, "", 20) // No tag filter, limit to 20
 		if err != nil {
 			return CommandDoneMsg{Operation: "snapshots", Err: err}
 		}
@@ -126,6 +143,9 @@ func (m Model) showSnapshots() (tea.Model, tea.Cmd) {
 }
 
 func (m Model) verifyRepository() (tea.Model, tea.Cmd) {
+	if m.busy() {
+		return m, nil
+	}
 	m.resetOutput("Verify Repository", "Verifying restic repository integrity...\n\n")
 
 	return m, func() tea.Msg {
@@ -134,7 +154,7 @@ func (m Model) verifyRepository() (tea.Model, tea.Cmd) {
 		}
 
 		restic := backup.NewResticManager(&m.config.LocalBackup, false, nil)
-		if err := restic.CheckRepository(); err != nil {
+		if err := restic.CheckRepository(m.ctx); err != nil {
 			return CommandDoneMsg{Operation: "verify", Err: err}
 		}
 
@@ -142,28 +162,70 @@ func (m Model) verifyRepository() (tea.Model, tea.Cmd) {
 	}
 }
 
+func (m Model) initRepository() (tea.Model, tea.Cmd) {
+	if m.busy() {
+		return m, nil
+	}
+	m.resetOutput("Initialize Repository", "Creating restic repository "+m.config.LocalBackup.Repository+"...\n\n")
+
+	return m, func() tea.Msg {
+		var output strings.Builder
+		restic := backup.NewResticManager(&m.config.LocalBackup, false, &output)
+		defer restic.Cleanup()
+		if err := restic.Init(m.ctx); err != nil {
+			return CommandDoneMsg{Operation: "init", Err: err}
+		}
+		return CommandOutputMsg{Output: output.String() + SuccessStyle.Render("Repository initialized!") + "\n"}
+	}
+}
+
 // ============================================================================
 // Cloud Sync Operations
 // ============================================================================
 
 func (m Model) runQuickSync() (tea.Model, tea.Cmd) {
+	if m.busy() {
+		return m, nil
+	}
 	m.resetOutput("Cloud Sync", "Starting cloud sync...\n\nThis will upload the restic repository to cloud storage.\n\n")
-	return m, m.executeSync(false)
+	m.transfer = newTransferState("Upload")
+	return m, m.startStream(m.executeSync)
 }
 
 func (m Model) runDryRunSync() (tea.Model, tea.Cmd) {
+	if m.busy() {
+		return m, nil
+	}
 	m.resetOutput("Cloud Sync Dry Run", "Running sync dry run...\n\nThis shows what would be synced without uploading.\n\n")
 	return m, m.executeDryRunSync()
 }
 
-// executeSync runs the sync operation using tea.ExecProcess for real-time output
-func (m Model) executeSync(dryRun bool) tea.Cmd {
-	return tea.ExecProcess(
-		m.buildSyncCommand(dryRun),
-		func(err error) tea.Msg {
-			return CommandDoneMsg{Operation: "sync", Err: err}
-		},
-	)
+// executeSync runs the sync in-process, streaming output and rclone progress
+func (m Model) executeSync(ctx context.Context, emit emitFunc) tea.Msg {
+	startTime := time.Now()
+	done := func(err error) tea.Msg {
+		return CommandDoneMsg{Operation: "sync", Err: err, Duration: time.Since(startTime)}
+	}
+
+	if err := m.config.ValidateForCloudSync(); err != nil {
+		return done(err)
+	}
+	if !cloud.RcloneAvailable() {
+		return done(fmt.Errorf("rclone is not installed"))
+	}
+	if err := cloud.ValidateRemote(ctx, m.config.CloudSync.Remote); err != nil {
+		return done(fmt.Errorf("remote validation failed: %w", err))
+	}
+
+	svc := cloud.NewSyncServiceWithOutput(&m.config.CloudSync, m.config.LocalBackup.Repository, false, &streamWriter{emit})
+	svc.SetProgressFunc(transferProgressFunc(emit))
+	svc.SetStatusDir(m.config.LogDir)
+
+	if err := svc.TestConnectivity(ctx); err != nil {
+		return done(fmt.Errorf("connectivity test failed: %w", err))
+	}
+
+	return done(svc.Sync(ctx))
 }
 
 // executeDryRunSync runs sync dry run and captures output for the viewport
@@ -192,6 +254,9 @@ func (m Model) buildSyncCommand(dryRun bool) *exec.Cmd {
 }
 
 func (m Model) testSyncConnectivity() (tea.Model, tea.Cmd) {
+	if m.busy() {
+		return m, nil
+	}
 	m.resetOutput("Test Connectivity", "Testing cloud storage connectivity...\n\n")
 
 	return m, func() tea.Msg {
@@ -209,7 +274,7 @@ func (m Model) testSyncConnectivity() (tea.Model, tea.Cmd) {
 		output.WriteString(CyanStyle.Render("Path: ") + m.config.CloudSync.Path + "\n\n")
 
 		svc := cloud.NewSyncService(&m.config.CloudSync, m.config.LocalBackup.Repository, true)
-		if err := svc.TestConnectivity(); err != nil {
+		if err := svc.TestConnectivity(m.ctx); err != nil {
 			return CommandDoneMsg{Operation: "connectivity", Err: err}
 		}
 
@@ -219,6 +284,9 @@ func (m Model) testSyncConnectivity() (tea.Model, tea.Cmd) {
 }
 
 func (m Model) showRemoteSize() (tea.Model, tea.Cmd) {
+	if m.busy() {
+		return m, nil
+	}
 	m.resetOutput("Remote Size", "Calculating remote backup size...\n\n")
 
 	return m, func() tea.Msg {
@@ -231,7 +299,7 @@ func (m Model) showRemoteSize() (tea.Model, tea.Cmd) {
 		}
 
 		svc := cloud.NewSyncService(&m.config.CloudSync, m.config.LocalBackup.Repository, true)
-		size, err := svc.GetRemoteSize()
+		size, err := svc.GetRemoteSize(m.ctx)
 		if err != nil {
 			return CommandDoneMsg{Operation: "size", Err: err}
 		}
@@ -246,24 +314,56 @@ func (m Model) showRemoteSize() (tea.Model, tea.Cmd) {
 // ============================================================================
 
 func (m Model) runRestore() (tea.Model, tea.Cmd) {
+	if m.busy() {
+		return m, nil
+	}
 	restorePath := fmt.Sprintf("/tmp/restored_backup_%s", time.Now().Format("20060102_150405"))
 	m.resetOutput("Cloud Restore", fmt.Sprintf("Restoring from cloud...\n\nDestination: %s\n\n", restorePath))
-	return m, m.executeRestore(restorePath, false)
+	m.transfer = newTransferState("Download")
+	return m, m.startStream(func(ctx context.Context, emit emitFunc) tea.Msg {
+		return m.executeRestore(ctx, emit, restorePath)
+	})
 }
 
 func (m Model) runRestorePreview() (tea.Model, tea.Cmd) {
+	if m.busy() {
+		return m, nil
+	}
 	m.resetOutput("Restore Preview", "Running restore dry run...\n\nThis shows what would be downloaded without making changes.\n\n")
 	return m, m.executeDryRunRestore()
 }
 
-// executeRestore runs the restore operation using tea.ExecProcess
-func (m Model) executeRestore(path string, dryRun bool) tea.Cmd {
-	return tea.ExecProcess(
-		m.buildRestoreCommand(path, dryRun),
-		func(err error) tea.Msg {
-			return CommandDoneMsg{Operation: "restore", Err: err}
-		},
-	)
+// executeRestore runs the restore in-process, streaming output and rclone progress
+func (m Model) executeRestore(ctx context.Context, emit emitFunc, path string) tea.Msg {
+	startTime := time.Now()
+	done := func(err error) tea.Msg {
+		return CommandDoneMsg{Operation: "restore", Err: err, Duration: time.Since(startTime)}
+	}
+
+	if err := m.config.ValidateForCloudSync(); err != nil {
+		return done(err)
+	}
+	if !cloud.RcloneAvailable() {
+		return done(fmt.Errorf("rclone is not installed"))
+	}
+
+	out := &streamWriter{emit}
+	svc := cloud.NewRestoreServiceWithOutput(&m.config.CloudSync, false, false, out)
+	svc.SetProgressFunc(transferProgressFunc(emit))
+	svc.SetRepositoryCheck(&m.config.LocalBackup, false)
+
+	if err := svc.TestConnectivity(ctx); err != nil {
+		return done(fmt.Errorf("connectivity test failed: %w", err))
+	}
+	if err := svc.Restore(ctx, path); err != nil {
+		return done(err)
+	}
+	if err := svc.Verify(ctx, path); err != nil {
+		return done(fmt.Errorf("restore verification failed: %w", err))
+	}
+
+	svc.PrintNextSteps(path)
+	return done(nil)
 }
 
 // executeDryRunRestore runs restore dry run and captures output for the viewport
@@ -292,6 +392,9 @@ func (m Model) buildRestoreCommand(path string, dryRun bool) *exec.Cmd {
 }
 
 func (m Model) testRestoreConnectivity() (tea.Model, tea.Cmd) {
+	if m.busy() {
+		return m, nil
+	}
 	m.resetOutput("Test Connectivity", "Testing cloud storage connectivity...\n\n")
 
 	return m, func() tea.Msg {
@@ -310,7 +413,7 @@ func (m Model) testRestoreConnectivity() (tea.Model, tea.Cmd) {
 		output.WriteString(CyanStyle.Render("Remote: ") + m.config.CloudSync.Remote + "\n")
 		output.WriteString(CyanStyle.Render("Path: ") + m.config.CloudSync.Path + "\n\n")
 
-		if err := svc.TestConnectivity(); err != nil {
+		if err := svc.TestConnectivity(m.ctx); err != nil {
 			return CommandDoneMsg{Operation: "connectivity", Err: err}
 		}
 
@@ -324,6 +427,9 @@ func (m Model) testRestoreConnectivity() (tea.Model, tea.Cmd) {
 // ============================================================================
 
 func (m Model) showQuickStatus() (tea.Model, tea.Cmd) {
+	if m.busy() {
+		return m, nil
+	}
 	m.resetOutput("Quick Status", "")
 
 	var output strings.Builder
@@ -361,6 +467,9 @@ func (m Model) showQuickStatus() (tea.Model, tea.Cmd) {
 }
 
 func (m Model) showSystemStatus() (tea.Model, tea.Cmd) {
+	if m.busy() {
+		return m, nil
+	}
 	m.resetOutput("System Status", "Loading system status...\n\n")
 
 	return m, func() tea.Msg {
@@ -405,6 +514,9 @@ func (m Model) showSystemStatus() (tea.Model, tea.Cmd) {
 }
 
 func (m Model) viewLogs() (tea.Model, tea.Cmd) {
+	if m.busy() {
+		return m, nil
+	}
 	m.resetOutput("View Logs", "Loading recent log entries...\n\n")
 
 	return m, func() tea.Msg {
@@ -432,16 +544,7 @@ func (m Model) viewLogs() (tea.Model, tea.Cmd) {
 		output.WriteString(CyanStyle.Render(fmt.Sprintf("Showing last %d lines:", len(lines)-start)) + "\n\n")
 
 		for _, line := range lines[start:] {
-			switch {
-			case strings.Contains(line, "[ERROR]"):
-				output.WriteString(ErrorStyle.Render(line) + "\n")
-			case strings.Contains(line, "[WARN]"):
-				output.WriteString(WarningStyle.Render(line) + "\n")
-			case strings.Contains(line, "[SUCCESS]"):
-				output.WriteString(SuccessStyle.Render(line) + "\n")
-			default:
-				output.WriteString(line + "\n")
-			}
+			output.WriteString(styleLogLine(line) + "\n")
 		}
 
 		return CommandOutputMsg{Output: output.String()}
@@ -459,6 +562,9 @@ func writeHealthCheckResult(output *strings.Builder, label string, ok bool, succ
 }
 
 func (m Model) runHealthCheck() (tea.Model, tea.Cmd) {
+	if m.busy() {
+		return m, nil
+	}
 	m.resetOutput("Health Check", "Running health diagnostics...\n\n")
 
 	return m, func() tea.Msg {
@@ -473,7 +579,7 @@ func (m Model) runHealthCheck() (tea.Model, tea.Cmd) {
 
 		// Check repository
 		restic := backup.NewResticManager(&m.config.LocalBackup, false, nil)
-		repoErr := restic.CheckRepository()
+		repoErr := restic.CheckRepository(m.ctx)
 		writeHealthCheckResult(&output, "Restic Repository", repoErr == nil, "OK", fmt.Sprintf("ERROR: %v", repoErr))
 
 		// Check stacks directory
@@ -484,7 +590,7 @@ func (m Model) runHealthCheck() (tea.Model, tea.Cmd) {
 
 		// Check cloud remote (if configured)
 		if m.config.CloudSync.Remote != "" {
-			remoteErr := cloud.ValidateRemote(m.config.CloudSync.Remote)
+			remoteErr := cloud.ValidateRemote(m.ctx, m.config.CloudSync.Remote)
 			writeHealthCheckResult(&output, "Cloud Remote", remoteErr == nil,
 				fmt.Sprintf("OK (%s)", m.config.CloudSync.Remote), fmt.Sprintf("ERROR: %v", remoteErr))
 		}
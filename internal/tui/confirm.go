@@ -0,0 +1,129 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Actions a confirmation dialog can be opened for
+const (
+	confirmForget         = "forget"
+	confirmPrune          = "prune"
+	confirmRestoreInPlace = "restore-in-place"
+	confirmRemoveExternal = "remove-external"
+	confirmRemoveKey      = "remove-key"
+)
+
+// maxConfirmItems is the number of affected items listed before the rest are summarized
+const maxConfirmItems = 12
+
+// confirmDialog is a modal that asks before a destructive action runs. The
+// action only runs once the confirmation word has been typed; ESC cancels.
+// The result is delivered as a ConfirmMsg.
+type confirmDialog struct {
+	active   bool
+	action   string
+	title    string
+	items    []string // Snapshots, paths or entries affected by the action
+	warning  string
+	word     string // Text that has to be typed to confirm
+	input    textinput.Model
+	mismatch bool // ENTER was pressed with the wrong text
+}
+
+// askConfirm opens a confirmation dialog for action
+func (m *Model) askConfirm(action, title string, items []string, warning, word string) tea.Cmd {
+	input := textinput.New()
+	input.Prompt = "> "
+	input.Placeholder = word
+	input.CharLimit = len(word) + 16
+	m.confirm = confirmDialog{
+		active:  true,
+		action:  action,
+		title:   title,
+		items:   items,
+		warning: warning,
+		word:    word,
+		input:   input,
+	}
+	return m.confirm.input.Focus()
+}
+
+// handleConfirmKey handles keys while the confirmation dialog is open
+func (m Model) handleConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	c := &m.confirm
+	switch msg.String() {
+	case keyEsc:
+		c.active = false
+		action := c.action
+		return m, func() tea.Msg { return ConfirmMsg{Confirmed: false, Action: action} }
+	case keyEnter:
+		if strings.TrimSpace(c.input.Value()) != c.word {
+			c.mismatch = true
+			return m, nil
+		}
+		c.active = false
+		action := c.action
+		return m, func() tea.Msg { return ConfirmMsg{Confirmed: true, Action: action} }
+	}
+	c.mismatch = false
+	var cmd tea.Cmd
+	c.input, cmd = c.input.Update(msg)
+	return m, cmd
+}
+
+// handleConfirm runs the action of a confirmed dialog
+func (m Model) handleConfirm(msg ConfirmMsg) (tea.Model, tea.Cmd) {
+	if !msg.Confirmed {
+		return m, nil
+	}
+	switch msg.Action {
+	case confirmForget:
+		return m.forgetSelectedSnapshots(false)
+	case confirmPrune:
+		return m.pruneRepository(false)
+	case confirmRestoreInPlace:
+		return m.restoreSelectedPaths(strings.TrimSpace(m.browser.target.Value()))
+	case confirmRemoveExternal:
+		m.removeCurrentExternalEntry()
+		return m, m.scheduleStackDetail()
+	case confirmRemoveKey:
+		return m.removeSelectedKey()
+	}
+	return m, nil
+}
+
+// viewConfirm renders the confirmation dialog in the middle of the screen
+func (m Model) viewConfirm() string {
+	c := m.confirm
+
+	var lines []string
+	lines = append(lines, ErrorStyle.Render(c.title), "")
+	shown := c.items
+	if len(shown) > maxConfirmItems {
+		shown = shown[:maxConfirmItems]
+	}
+	for _, item := range shown {
+		lines = append(lines, "  "+item)
+	}
+	if more := len(c.items) - len(shown); more > 0 {
+		lines = append(lines, MutedStyle.Render(fmt.Sprintf("  ... and %d more", more)))
+	}
+	if c.warning != "" {
+		lines = append(lines, "", WarningStyle.Render(c.warning))
+	}
+	lines = append(lines, "",
+		fmt.Sprintf("Type %s to confirm:", lipgloss.NewStyle().Bold(true).Render(c.word)),
+		c.input.View())
+	if c.mismatch {
+		lines = append(lines, ErrorStyle.Render("The text does not match"))
+	}
+	lines = append(lines, "", MutedStyle.Render("ENTER: Confirm  ESC: Cancel"))
+
+	box := BoxStyle.BorderForeground(ColorError).Render(strings.Join(lines, "\n"))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
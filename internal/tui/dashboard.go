@@ -0,0 +1,349 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"backup-tui/internal/backup"
+	"backup-tui/internal/config"
+	"backup-tui/internal/dirlist"
+	"backup-tui/internal/status"
+	"backup-tui/internal/util"
+)
+
+const (
+	// dashboardRefreshInterval is how often the dashboard reloads while the main screen is shown
+	dashboardRefreshInterval = time.Minute
+	// dashboardWorkers bounds concurrent docker compose status checks
+	dashboardWorkers = 8
+)
+
+// freshness classifies how recent a stack's last snapshot is
+type freshness int
+
+const (
+	freshnessOK freshness = iota
+	freshnessWarning
+	freshnessCritical
+	freshnessNever
+)
+
+// stackFreshness classifies the age of a last snapshot against the configured thresholds
+func stackFreshness(cfg *config.LocalBackupConfig, last time.Time, now time.Time) freshness {
+	if last.IsZero() {
+		return freshnessNever
+	}
+	age := now.Sub(last)
+	switch {
+	case age >= time.Duration(cfg.StaleCriticalHours)*time.Hour:
+		return freshnessCritical
+	case age >= time.Duration(cfg.StaleWarningHours)*time.Hour:
+		return freshnessWarning
+	default:
+		return freshnessOK
+	}
+}
+
+// dashboardRow is the freshness summary of one dirlist entry
+type dashboardRow struct {
+	ID           string
+	Enabled      bool
+	External     bool
+	Docker       backup.StackState
+	LastSnapshot time.Time
+	Size         int64 // Size of the last snapshot, 0 if unknown
+	LastRun      *status.Result
+}
+
+// dashboardState holds the data shown on the main screen
+type dashboardState struct {
+	loading   bool
+	loaded    bool
+	rows      []dashboardRow
+	repoSize  int64 // Size of a local repository, measured once and after backups and prunes
+	sizeKnown bool
+	lastSync  *status.Result
+	err       string
+	updatedAt time.Time
+}
+
+// dashboardMsg carries freshly gathered dashboard data
+type dashboardMsg struct {
+	Rows     []dashboardRow
+	RepoSize int64
+	Measured bool // RepoSize was measured on this refresh
+	LastSync *status.Result
+	Err      error
+}
+
+// dashboardTickMsg triggers a periodic dashboard refresh
+type dashboardTickMsg struct{}
+
+// refreshDashboard gathers dashboard data in the background
+func (m *Model) refreshDashboard() tea.Cmd {
+	if m.dashboard.loading {
+		return nil
+	}
+	m.dashboard.loading = true
+	ctx, cfg, measure := m.ctx, m.config, !m.dashboard.sizeKnown
+
+	return func() tea.Msg {
+		return loadDashboard(ctx, cfg, measure)
+	}
+}
+
+// dashboardTick schedules the next periodic refresh
+func dashboardTick() tea.Cmd {
+	return tea.Tick(dashboardRefreshInterval, func(time.Time) tea.Msg {
+		return dashboardTickMsg{}
+	})
+}
+
+// loadDashboard collects Docker state, snapshot times, run results and, if measure is
+// set, the size of a local repository; walking it is too slow for every refresh.
+// It uses its own dirlist manager so it never touches the model's state.
+func loadDashboard(ctx context.Context, cfg *config.Config, measure bool) dashboardMsg {
+	var msg dashboardMsg
+
+	dl := dirlist.NewManager(cfg.DirlistFile, cfg.LockDir, cfg.Docker.StacksDir)
+	if err := dl.Load(); err != nil {
+		msg.Err = fmt.Errorf("cannot load dirlist: %w", err)
+		return msg
+	}
+	_, _, _ = dl.Sync()
+
+	ids := dl.SortedDirs()
+	msg.Rows = make([]dashboardRow, len(ids))
+	for i, id := range ids {
+		entry := dl.GetEntry(id)
+		msg.Rows[i] = dashboardRow{
+			ID:       id,
+			Enabled:  entry.Enabled,
+			External: entry.IsExternal,
+			Docker:   backup.StateUnknown,
+		}
+	}
+
+	// Docker state, checked concurrently since each check shells out
+	docker := backup.NewDockerManager(cfg.Docker.Timeout, false, nil)
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, dashboardWorkers)
+	for i := range msg.Rows {
+		wg.Add(1)
+		go func(row *dashboardRow) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if state, err := docker.CheckStackStatus(ctx, dl.GetFullPath(row.ID)); err == nil {
+				row.Docker = state
+			}
+		}(&msg.Rows[i])
+	}
+
+	// Last snapshot per tag, through a manager of its own so its password file
+	// is not shared with restic commands the TUI may still be running
+	restic := backup.NewResticManager(&cfg.LocalBackup, false, nil)
+	snapshots, err := restic.ListSnapshots(ctx, "", 0)
+	restic.Cleanup()
+	if err != nil {
+		msg.Err = err
+	} else {
+		latest := make(map[string]time.Time)
+		sizes := make(map[string]int64)
+		for _, snap := range snapshots {
+			t, err := time.Parse(time.RFC3339Nano, snap.Time)
+			if err != nil {
+				continue
+			}
+			for _, tag := range snap.Tags {
+				if t.After(latest[tag]) {
+					latest[tag] = t
+					sizes[tag] = snap.Size()
+				}
+			}
+		}
+		for i := range msg.Rows {
+			tag := dl.SnapshotTag(msg.Rows[i].ID)
+			msg.Rows[i].LastSnapshot = latest[tag]
+			msg.Rows[i].Size = sizes[tag]
+		}
+	}
+
+	// Last run results
+	if st, err := status.Load(cfg.LogDir); err == nil {
+		for i := range msg.Rows {
+			if r, ok := st.Stacks[msg.Rows[i].ID]; ok {
+				msg.Rows[i].LastRun = &r
+			}
+		}
+		msg.LastSync = st.Sync
+	}
+
+	if path := backup.LocalRepository(cfg.LocalBackup.Repository); measure && path != "" {
+		if size, err := util.DirSize(path); err == nil {
+			msg.RepoSize, msg.Measured = size, true
+		}
+	}
+
+	wg.Wait()
+	return msg
+}
+
+// handleDashboard stores gathered dashboard data
+func (m Model) handleDashboard(msg dashboardMsg) (tea.Model, tea.Cmd) {
+	m.dashboard.loading = false
+	m.dashboard.loaded = true
+	m.dashboard.rows = msg.Rows
+	if msg.Measured {
+		m.dashboard.repoSize, m.dashboard.sizeKnown = msg.RepoSize, true
+	}
+	m.dashboard.lastSync = msg.LastSync
+	m.dashboard.updatedAt = time.Now()
+	m.dashboard.err = ""
+	if msg.Err != nil {
+		m.dashboard.err = msg.Err.Error()
+	}
+
+	// Running, stale, size and age depend on the dashboard data
+	switch m.screen {
+	case ScreenDirlist:
+		m.applyDirlistQuery()
+	case ScreenSnapshots:
+		m.applySnapshotQuery()
+	}
+	return m, nil
+}
+
+// viewDashboard renders the per-stack freshness panel within width and height
+func (m Model) viewDashboard(width, height int) string {
+	d := m.dashboard
+	if !d.loaded {
+		return MutedStyle.Render("Loading stack status...")
+	}
+
+	now := time.Now()
+	nameWidth := 12
+	for _, row := range d.rows {
+		if n := len(m.dashboardName(row)); n > nameWidth {
+			nameWidth = n
+		}
+	}
+	if maxName := width - 50; nameWidth > maxName && maxName >= 12 {
+		nameWidth = maxName
+	}
+
+	lines := []string{
+		CyanStyle.Render(fmt.Sprintf("%-*s  %-9s  %-16s  %-8s  %s", nameWidth, "Stack", "Docker", "Last Snapshot", "Age", "Last Run")),
+	}
+
+	// Header, blank line, summary, footer and error take up to five lines
+	maxRows := height - 5
+	if maxRows < 1 {
+		maxRows = 1
+	}
+
+	for i, row := range d.rows {
+		if i == maxRows && len(d.rows) > maxRows {
+			lines = append(lines, MutedStyle.Render(fmt.Sprintf("… and %d more", len(d.rows)-maxRows)))
+			break
+		}
+		name := fmt.Sprintf("%-*s", nameWidth, truncateLeft(m.dashboardName(row), nameWidth))
+		if !row.Enabled {
+			name = MutedStyle.Render(name)
+		}
+
+		docker := fmt.Sprintf("%-9s", row.Docker)
+		switch row.Docker {
+		case backup.StateRunning:
+			docker = SuccessStyle.Render(docker)
+		case backup.StateStopped:
+			docker = WarningStyle.Render(docker)
+		default:
+			docker = MutedStyle.Render(docker)
+		}
+
+		snapTime, age := "never", "-"
+		if !row.LastSnapshot.IsZero() {
+			snapTime = row.LastSnapshot.Local().Format("2006-01-02 15:04")
+			age = formatAge(now.Sub(row.LastSnapshot))
+		}
+		snapCol := fmt.Sprintf("%-16s  %-8s", snapTime, age)
+		switch f := stackFreshness(&m.config.LocalBackup, row.LastSnapshot, now); {
+		case !row.Enabled:
+			snapCol = MutedStyle.Render(snapCol)
+		case f == freshnessOK:
+			snapCol = SuccessStyle.Render(snapCol)
+		case f == freshnessWarning:
+			snapCol = WarningStyle.Render(snapCol)
+		default:
+			snapCol = ErrorStyle.Render(snapCol)
+		}
+
+		lines = append(lines, fmt.Sprintf("%s  %s  %s  %s", name, docker, snapCol, formatRunResult(row.LastRun)))
+	}
+
+	if len(d.rows) == 0 {
+		lines = append(lines, MutedStyle.Render("No directories in dirlist"))
+	}
+
+	lastSync := MutedStyle.Render("never")
+	if d.lastSync != nil {
+		lastSync = formatRunResult(d.lastSync) + MutedStyle.Render(" "+formatAge(now.Sub(d.lastSync.Time))+" ago")
+	}
+	repoSize := MutedStyle.Render("n/a")
+	if d.sizeKnown {
+		repoSize = util.FormatSize(d.repoSize)
+	}
+	lines = append(lines, "",
+		fmt.Sprintf("Repository: %s   Last cloud sync: %s", repoSize, lastSync))
+
+	footer := "Updated " + d.updatedAt.Format("15:04:05")
+	if d.loading {
+		footer += " • refreshing..."
+	}
+	lines = append(lines, MutedStyle.Render(footer))
+	if d.err != "" {
+		lines = append(lines, ErrorStyle.Render(truncateRight(d.err, width)))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// dashboardName returns the display name of a row
+func (m Model) dashboardName(row dashboardRow) string {
+	if row.External {
+		return "↗ " + row.ID
+	}
+	return row.ID
+}
+
+// formatRunResult renders the outcome of a run
+func formatRunResult(r *status.Result) string {
+	switch {
+	case r == nil:
+		return MutedStyle.Render("-")
+	case r.Success:
+		return SuccessStyle.Render("OK")
+	default:
+		return ErrorStyle.Render("FAILED")
+	}
+}
+
+// formatAge renders a duration as a short age such as 5m, 3h or 2d
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"backup-tui/internal/backup"
+	"backup-tui/internal/util"
+)
+
+// compareSelectedSnapshots diffs the two selected snapshots, older against newer
+func (m Model) compareSelectedSnapshots() (tea.Model, tea.Cmd) {
+	if m.busy() {
+		return m, nil
+	}
+	var selected []backup.Snapshot
+	for _, snap := range m.snapshotAll {
+		if m.snapshotSelected[snap.ShortID] {
+			selected = append(selected, snap)
+		}
+	}
+
+	if len(selected) != 2 {
+		m.snapshotErr = fmt.Sprintf("Select exactly two snapshots to compare (%d selected)", len(selected))
+		return m, nil
+	}
+
+	sort.Slice(selected, func(i, j int) bool {
+		ti, _ := time.Parse(time.RFC3339Nano, selected[i].Time)
+		tj, _ := time.Parse(time.RFC3339Nano, selected[j].Time)
+		return ti.Before(tj)
+	})
+	from, to := selected[0].ShortID, selected[1].ShortID
+
+	m.resetOutput("Snapshot Diff", fmt.Sprintf("Comparing %s → %s...\n\n", from, to))
+
+	return m, m.startStream(func(ctx context.Context, emit emitFunc) tea.Msg {
+		startTime := time.Now()
+		restic := backup.NewResticManager(&m.config.LocalBackup, false, nil)
+		if err := restic.SetupEnv(); err != nil {
+			return CommandDoneMsg{Operation: "diff", Err: err, Duration: time.Since(startTime)}
+		}
+		defer restic.Cleanup()
+
+		diff, err := restic.DiffSnapshots(ctx, from, to)
+		if err != nil {
+			return CommandDoneMsg{Operation: "diff", Err: err, Duration: time.Since(startTime)}
+		}
+
+		emit(CommandOutputMsg{Output: renderSnapshotDiff(diff)})
+		return CommandDoneMsg{Operation: "diff", Duration: time.Since(startTime)}
+	})
+}
+
+// renderSnapshotDiff formats a snapshot diff for the output viewport
+func renderSnapshotDiff(diff *backup.SnapshotDiff) string {
+	var b strings.Builder
+
+	if len(diff.Changes) == 0 {
+		b.WriteString(MutedStyle.Render("No differences between snapshots") + "\n")
+		return b.String()
+	}
+
+	for _, c := range diff.Changes {
+		marker := WarningStyle.Render(fmt.Sprintf("%-2s", c.Modifier))
+		switch c.Modifier {
+		case backup.DiffAdded:
+			marker = SuccessStyle.Render(fmt.Sprintf("%-2s", c.Modifier))
+		case backup.DiffRemoved:
+			marker = ErrorStyle.Render(fmt.Sprintf("%-2s", c.Modifier))
+		}
+		line := marker + " " + c.Path
+		if d := c.SizeDelta(); d != 0 {
+			line += "  " + MutedStyle.Render(backup.FormatSizeDelta(d))
+		}
+		b.WriteString(line + "\n")
+	}
+
+	if largest := diff.LargestChanges(10); len(largest) > 0 {
+		b.WriteString("\n" + CyanStyle.Render("Largest size changes:") + "\n")
+		for _, c := range largest {
+			fmt.Fprintf(&b, "  %10s  %s\n", backup.FormatSizeDelta(c.SizeDelta()), c.Path)
+		}
+	}
+
+	added, removed, modified := diff.Counts()
+	b.WriteString("\n")
+	b.WriteString(SuccessStyle.Render(fmt.Sprintf("Added:    %d (%s)", added, util.FormatSize(diff.Added.Bytes))) + "\n")
+	b.WriteString(ErrorStyle.Render(fmt.Sprintf("Removed:  %d (%s)", removed, util.FormatSize(diff.Removed.Bytes))) + "\n")
+	b.WriteString(WarningStyle.Render(fmt.Sprintf("Modified: %d", modified)) + "\n")
+	fmt.Fprintf(&b, "Net size: %s\n", backup.FormatSizeDelta(diff.SizeDelta()))
+
+	return b.String()
+}
@@ -0,0 +1,336 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"backup-tui/internal/backup"
+)
+
+// listFilter restricts a list screen to stacks with a given property
+type listFilter int
+
+const (
+	filterAll listFilter = iota
+	filterEnabled
+	filterDisabled
+	filterExternal
+	filterRunning
+	filterStale
+	filterCount
+)
+
+var listFilterNames = [...]string{"all", "enabled", "disabled", "external", "running", "stale"}
+
+func (f listFilter) String() string {
+	return listFilterNames[f]
+}
+
+// listSort orders a list screen
+type listSort int
+
+const (
+	sortName listSort = iota
+	sortSize          // Largest first
+	sortAge           // Oldest backup first
+	sortCount
+)
+
+var listSortNames = [...]string{"name", "size", "age"}
+
+func (s listSort) String() string {
+	return listSortNames[s]
+}
+
+// snapshotGrouping puts the snapshot list under one header per stack or day
+type snapshotGrouping int
+
+const (
+	groupNone snapshotGrouping = iota
+	groupStack
+	groupDate
+	groupCount
+)
+
+var snapshotGroupingNames = [...]string{"none", "stack", "date"}
+
+func (g snapshotGrouping) String() string {
+	return snapshotGroupingNames[g]
+}
+
+// listQuery holds the search, filter and sort state of a list screen
+type listQuery struct {
+	search    textinput.Model
+	searching bool
+	filter    listFilter
+	sort      listSort
+	group     snapshotGrouping // Snapshot screen only
+}
+
+// newListQuery creates an empty query with the given search placeholder and initial sort
+func newListQuery(placeholder string, order listSort) listQuery {
+	search := textinput.New()
+	search.Placeholder = placeholder
+	search.Prompt = "/"
+	return listQuery{search: search, sort: order}
+}
+
+// text returns the lower-cased search text
+func (q listQuery) text() string {
+	return strings.ToLower(strings.TrimSpace(q.search.Value()))
+}
+
+// matches reports whether one of fields contains the search text
+func (q listQuery) matches(fields ...string) bool {
+	text := q.text()
+	if text == "" {
+		return true
+	}
+	for _, f := range fields {
+		if strings.Contains(strings.ToLower(f), text) {
+			return true
+		}
+	}
+	return false
+}
+
+// startSearch focuses the search input
+func (q *listQuery) startSearch() tea.Cmd {
+	q.searching = true
+	return q.search.Focus()
+}
+
+// clearSearch drops the search text
+func (q *listQuery) clearSearch() {
+	q.searching = false
+	q.search.Blur()
+	q.search.SetValue("")
+}
+
+// handleSearchKey feeds a key to the search input: ESC clears the search, ENTER keeps it
+func (q *listQuery) handleSearchKey(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case keyEsc:
+		q.clearSearch()
+		return nil
+	case keyEnter:
+		q.searching = false
+		q.search.Blur()
+		return nil
+	}
+	var cmd tea.Cmd
+	q.search, cmd = q.search.Update(msg)
+	return cmd
+}
+
+// view renders the search input and the active filter, sort and grouping
+func (q listQuery) view(grouping bool) string {
+	state := fmt.Sprintf("Filter: %s  Sort: %s", q.filter, q.sort)
+	if grouping {
+		state += fmt.Sprintf("  Group: %s", q.group)
+	}
+	line := MutedStyle.Render(state)
+	if q.searching || q.text() != "" {
+		line = q.search.View() + "   " + line
+	}
+	return line
+}
+
+// stackFacts are the properties of a stack the list filters look at
+type stackFacts struct {
+	known    bool // In the dirlist
+	enabled  bool
+	external bool
+	state    backup.StackState
+	last     time.Time // Newest snapshot
+}
+
+// accepts reports whether a stack passes the filter
+func (m Model) accepts(f listFilter, s stackFacts) bool {
+	switch f {
+	case filterEnabled:
+		return s.known && s.enabled
+	case filterDisabled:
+		return s.known && !s.enabled
+	case filterExternal:
+		return s.external
+	case filterRunning:
+		return s.state == backup.StateRunning
+	case filterStale:
+		return stackFreshness(&m.config.LocalBackup, s.last, time.Now()) != freshnessOK
+	}
+	return true
+}
+
+// dashboardRows indexes the last dashboard data by dirlist identifier
+func (m Model) dashboardRows() map[string]dashboardRow {
+	rows := make(map[string]dashboardRow, len(m.dashboard.rows))
+	for _, row := range m.dashboard.rows {
+		rows[row.ID] = row
+	}
+	return rows
+}
+
+// dirlistFacts gathers the filter properties of a dirlist entry
+func (m Model) dirlistFacts(id string, row dashboardRow) stackFacts {
+	facts := stackFacts{known: true, enabled: m.dirlistSelections[id], state: row.Docker, last: row.LastSnapshot}
+	if entry := m.dirlist.GetEntry(id); entry != nil {
+		facts.external = entry.IsExternal
+	}
+	// The detail pane has the more recent Docker state
+	if d := m.detail.details[id]; d != nil {
+		facts.state = d.State
+	}
+	return facts
+}
+
+// applyDirlistQuery rebuilds the visible dirlist entries, keeping the cursor on the same entry
+func (m *Model) applyDirlistQuery() {
+	current := m.currentDirlistEntry()
+	q := m.dirlistQuery
+	rows := m.dashboardRows()
+
+	var dirs []string
+	for _, id := range m.dirlist.SortedDirs() {
+		if q.matches(id, m.dirlist.SnapshotTag(id)) && m.accepts(q.filter, m.dirlistFacts(id, rows[id])) {
+			dirs = append(dirs, id)
+		}
+	}
+
+	switch q.sort {
+	case sortSize:
+		sort.SliceStable(dirs, func(i, j int) bool {
+			return rows[dirs[i]].Size > rows[dirs[j]].Size
+		})
+	case sortAge:
+		sort.SliceStable(dirs, func(i, j int) bool {
+			return rows[dirs[i]].LastSnapshot.Before(rows[dirs[j]].LastSnapshot)
+		})
+	}
+
+	m.dirlistDirs = dirs
+	m.dirlistCursor = cursorOn(dirs, current, m.dirlistCursor)
+}
+
+// applySnapshotQuery rebuilds the visible snapshots and group headers, keeping the cursor on the same snapshot
+func (m *Model) applySnapshotQuery() {
+	var current string
+	if m.snapshotCursor < len(m.snapshotList) {
+		current = m.snapshotList[m.snapshotCursor].ID
+	}
+	q := m.snapshotQuery
+	rows := m.dashboardRows()
+
+	tagIDs := make(map[string]string)
+	for _, id := range m.dirlist.SortedDirs() {
+		tagIDs[m.dirlist.SnapshotTag(id)] = id
+	}
+	times := make(map[string]time.Time, len(m.snapshotAll))
+	latest := make(map[string]time.Time)
+	for _, snap := range m.snapshotAll {
+		t, _ := time.Parse(time.RFC3339Nano, snap.Time)
+		times[snap.ID] = t
+		if tag := snap.StackTag(); t.After(latest[tag]) {
+			latest[tag] = t
+		}
+	}
+
+	var shown []backup.Snapshot
+	for _, snap := range m.snapshotAll {
+		tag := snap.StackTag()
+		facts := stackFacts{external: strings.HasSuffix(tag, "-external"), last: latest[tag]}
+		if id, ok := tagIDs[tag]; ok {
+			facts.known = true
+			facts.enabled, _ = m.dirlist.Get(id)
+			facts.external = m.dirlist.GetEntry(id).IsExternal
+			facts.state = rows[id].Docker
+		}
+		if q.matches(snap.ShortID, snap.Hostname, strings.Join(snap.Tags, " "), strings.Join(snap.Paths, " ")) && m.accepts(q.filter, facts) {
+			shown = append(shown, snap)
+		}
+	}
+
+	groupKey := func(snap backup.Snapshot) string {
+		switch q.group {
+		case groupStack:
+			if tag := snap.StackTag(); tag != "" {
+				return tag
+			}
+			return "(untagged)"
+		case groupDate:
+			return times[snap.ID].Local().Format("2006-01-02")
+		}
+		return ""
+	}
+	less := func(a, b backup.Snapshot) bool {
+		switch q.sort {
+		case sortName:
+			if a.StackTag() != b.StackTag() {
+				return a.StackTag() < b.StackTag()
+			}
+		case sortSize:
+			return a.Size() > b.Size()
+		}
+		return times[a.ID].Before(times[b.ID])
+	}
+	sort.SliceStable(shown, func(i, j int) bool {
+		if gi, gj := groupKey(shown[i]), groupKey(shown[j]); gi != gj {
+			return gi < gj
+		}
+		return less(shown[i], shown[j])
+	})
+
+	// One header in front of the first snapshot of each group
+	m.snapshotHeaders = nil
+	if q.group != groupNone {
+		m.snapshotHeaders = make(map[int]string)
+		start := 0
+		for i := 1; i <= len(shown); i++ {
+			if i == len(shown) || groupKey(shown[i]) != groupKey(shown[start]) {
+				m.snapshotHeaders[start] = fmt.Sprintf("%s (%d)", groupKey(shown[start]), i-start)
+				start = i
+			}
+		}
+	}
+
+	m.snapshotList = shown
+	ids := make([]string, len(shown))
+	for i, snap := range shown {
+		ids[i] = snap.ID
+	}
+	m.snapshotCursor = cursorOn(ids, current, m.snapshotCursor)
+	m.snapshotYOffset = 0
+	m.ensureSnapshotCursorVisible()
+}
+
+// snapshotLine returns the viewport line of the snapshot at idx, counting group headers
+func (m Model) snapshotLine(idx int) int {
+	line := idx
+	for start := range m.snapshotHeaders {
+		if start <= idx {
+			line++
+		}
+	}
+	return line
+}
+
+// cursorOn returns the index of current in items, or fallback clamped to items
+func cursorOn(items []string, current string, fallback int) int {
+	for i, item := range items {
+		if item == current {
+			return i
+		}
+	}
+	if fallback >= len(items) {
+		fallback = len(items) - 1
+	}
+	if fallback < 0 {
+		fallback = 0
+	}
+	return fallback
+}
@@ -0,0 +1,213 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"backup-tui/internal/history"
+)
+
+// recordedOperations are the operations whose runs are kept in the history
+var recordedOperations = map[string]bool{
+	"backup":        true,
+	"sync":          true,
+	"restore":       true,
+	"restore-files": true,
+	"prune":         true,
+	"forget":        true,
+}
+
+// historyRecordedMsg reports whether a finished run could be added to the history
+type historyRecordedMsg struct {
+	Err error
+}
+
+// historyState is the state of the run history screen
+type historyState struct {
+	entries      []history.Entry // Newest first
+	cursor       int             // Index into visible()
+	failuresOnly bool
+	err          string
+}
+
+// visible returns the entries shown with the current filter
+func (h historyState) visible() []history.Entry {
+	if !h.failuresOnly {
+		return h.entries
+	}
+	var failed []history.Entry
+	for _, e := range h.entries {
+		if !e.Success {
+			failed = append(failed, e)
+		}
+	}
+	return failed
+}
+
+// recordHistory stores a finished run with the output collected so far
+func (m Model) recordHistory(msg CommandDoneMsg) tea.Cmd {
+	if !recordedOperations[msg.Operation] {
+		return nil
+	}
+	logDir := m.config.LogDir
+	entry := history.NewEntry(msg.Operation, m.outputTitle, msg.Duration, msg.Err)
+	output := m.outputContent.String()
+	return func() tea.Msg {
+		return historyRecordedMsg{Err: history.Record(logDir, entry, output)}
+	}
+}
+
+// openHistory loads the recorded runs and shows the history screen
+func (m Model) openHistory() (tea.Model, tea.Cmd) {
+	m.loadHistory()
+	return m.changeScreen(ScreenHistory)
+}
+
+// loadHistory reads the recorded runs, keeping the cursor in range
+func (m *Model) loadHistory() {
+	m.history.err = ""
+	entries, err := history.List(m.config.LogDir)
+	if err != nil {
+		m.history.err = err.Error()
+	}
+	m.history.entries = entries
+	if n := len(m.history.visible()); m.history.cursor >= n {
+		m.history.cursor = max(n-1, 0)
+	}
+}
+
+// handleHistoryKey handles keys on the history screen
+func (m Model) handleHistoryKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	h := &m.history
+	visible := h.visible()
+
+	switch msg.String() {
+	case "q":
+		return m.quit()
+	case keyEsc:
+		return m.changeScreen(ScreenMain)
+	case "up", "k":
+		if h.cursor > 0 {
+			h.cursor--
+		}
+	case "down", "j":
+		if h.cursor < len(visible)-1 {
+			h.cursor++
+		}
+	case "home", "g":
+		h.cursor = 0
+	case "end", "G":
+		h.cursor = max(len(visible)-1, 0)
+	case "f":
+		h.failuresOnly = !h.failuresOnly
+		h.cursor = 0
+	case "r":
+		m.loadHistory()
+	case keyEnter:
+		if h.cursor < len(visible) {
+			return m.showHistoryOutput(visible[h.cursor])
+		}
+	}
+	return m, nil
+}
+
+// showHistoryOutput replays the captured output of a run in the output view
+func (m Model) showHistoryOutput(e history.Entry) (tea.Model, tea.Cmd) {
+	output, err := history.Output(m.config.LogDir, e.ID)
+	if err != nil {
+		m.history.err = err.Error()
+		return m, nil
+	}
+
+	m.outputContent.Reset()
+	m.outputContent.WriteString(output)
+	m.outputContent.WriteString("\nPress ESC to go back")
+	m.outputTitle = fmt.Sprintf("%s (%s)", e.Title, e.Time.Local().Format("2006-01-02 15:04:05"))
+	m.prevScreen = ScreenHistory
+	m.screen = ScreenOutput
+	if m.outputReady {
+		m.outputViewport.SetContent(m.outputContent.String())
+		m.outputViewport.GotoTop()
+	}
+	return m, nil
+}
+
+// viewHistory renders the list of recorded runs
+func (m Model) viewHistory() string {
+	h := m.history
+	title := TitleStyle.Render("Run History")
+	instructions := MutedStyle.Render("↑/↓: Navigate  ENTER: View Output  F: Failures Only  R: Refresh  ESC: Back  Q: Quit")
+
+	filter := "all runs"
+	if h.failuresOnly {
+		filter = "failures only"
+	}
+	visible := h.visible()
+	summary := MutedStyle.Render(fmt.Sprintf("Showing %s: %d of %d", filter, len(visible), len(h.entries)))
+
+	var lines []string
+	if len(visible) == 0 {
+		lines = append(lines, MutedStyle.Render("  No runs recorded yet"))
+		if h.failuresOnly && len(h.entries) > 0 {
+			lines[0] = SuccessStyle.Render("  No failed runs")
+		}
+	}
+	for i, e := range visible {
+		cursor := "  "
+		if i == h.cursor {
+			cursor = "> "
+		}
+		mark := SuccessStyle.Render("✓")
+		if !e.Success {
+			mark = ErrorStyle.Render("✗")
+		}
+		line := fmt.Sprintf("%s%s %s  %-13s %8s  %s", cursor, mark,
+			e.Time.Local().Format("2006-01-02 15:04"), e.Operation, e.Duration.Round(time.Second), e.Title)
+		if i == h.cursor {
+			line = lipgloss.NewStyle().Bold(true).Render(line)
+		}
+		if e.Error != "" {
+			line += "  " + ErrorStyle.Render(firstLine(e.Error))
+		}
+		lines = append(lines, line)
+	}
+
+	// Keep the cursor in view when the terminal is short
+	visibleLines := m.height - 10
+	if visibleLines < 5 {
+		visibleLines = 5
+	}
+	start := 0
+	if h.cursor >= visibleLines {
+		start = h.cursor - visibleLines + 1
+	}
+	end := min(start+visibleLines, len(lines))
+
+	var status string
+	if h.err != "" {
+		status = ErrorStyle.Render(h.err)
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		instructions,
+		summary,
+		"",
+		strings.Join(lines[start:end], "\n"),
+		"",
+		status,
+	)
+}
+
+// firstLine returns s up to the first line break
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -1,7 +1,12 @@
 // Package tui2 provides the Bubbletea-based terminal user interface
 package tui
 
-import "time"
+import (
+	"time"
+
+	"backup-tui/internal/backup"
+	"backup-tui/internal/cloud"
+)
 
 // Screen represents the current screen/page in the TUI
 type Screen int
@@ -17,6 +22,11 @@ const (
 	ScreenFilePicker
 	ScreenSnapshots
 	ScreenRestic
+	ScreenSnapshotBrowser
+	ScreenSettings
+	ScreenHistory
+	ScreenKeys
+	ScreenStats
 )
 
 // ScreenChangeMsg is sent when navigating between screens
@@ -60,3 +70,38 @@ type DirlistRefreshMsg struct{}
 type DirlistSavedMsg struct {
 	Err error
 }
+
+// TransferStatsMsg carries an rclone stats update for the progress panel
+type TransferStatsMsg struct {
+	Stats cloud.TransferStats
+}
+
+// TransferErrorMsg carries a per-file rclone error
+type TransferErrorMsg struct {
+	Error cloud.TransferError
+}
+
+// BackupEventMsg carries an event from the in-process backup run
+type BackupEventMsg struct {
+	Event backup.Event
+}
+
+// SnapshotFilesMsg carries the contents of a snapshot for the browser
+type SnapshotFilesMsg struct {
+	SnapshotID string
+	Nodes      []backup.SnapshotNode
+	Err        error
+}
+
+// SnapshotMountedMsg reports the result of mounting a snapshot
+type SnapshotMountedMsg struct {
+	SnapshotID string
+	Session    *backup.MountSession
+	Err        error
+}
+
+// SnapshotUnmountedMsg is sent when a mount has been released or restic mount exited
+type SnapshotUnmountedMsg struct {
+	Session *backup.MountSession
+	Err     error
+}
@@ -0,0 +1,127 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"backup-tui/internal/backup"
+)
+
+// snapshotMount tracks a snapshot mounted with restic mount in the background
+type snapshotMount struct {
+	snapshotID string
+	pending    bool
+	restic     *backup.ResticManager // Owns the mount; its Cleanup unmounts
+	session    *backup.MountSession
+	cancel     context.CancelFunc // Aborts a pending mount
+	done       chan struct{}      // Closed once the pending mount has returned
+}
+
+// mounted reports whether a mount is active
+func (s snapshotMount) mounted() bool {
+	return s.session != nil
+}
+
+// mountSnapshot mounts the highlighted snapshot in the background
+func (m Model) mountSnapshot() (tea.Model, tea.Cmd) {
+	if len(m.snapshotList) == 0 || m.mount.pending {
+		return m, nil
+	}
+	if m.mount.mounted() {
+		m.snapshotErr = fmt.Sprintf("Snapshot %s is already mounted at %s, unmount it first", m.mount.snapshotID, m.mount.session.Path)
+		return m, nil
+	}
+
+	// The manager is kept from the start so quitting during the wait can release it
+	snap := m.snapshotList[m.snapshotCursor]
+	restic := backup.NewResticManager(&m.config.LocalBackup, false, nil)
+	ctx, cancel := context.WithCancel(m.ctx)
+	done := make(chan struct{})
+	m.mount = snapshotMount{snapshotID: snap.ShortID, pending: true, restic: restic, cancel: cancel, done: done}
+
+	return m, func() tea.Msg {
+		defer close(done)
+		defer cancel()
+		if err := restic.SetupEnv(); err != nil {
+			return SnapshotMountedMsg{SnapshotID: snap.ShortID, Err: err}
+		}
+
+		session, err := restic.Mount(ctx, snap.ShortID)
+		if err != nil {
+			restic.Cleanup()
+			return SnapshotMountedMsg{SnapshotID: snap.ShortID, Err: err}
+		}
+		return SnapshotMountedMsg{SnapshotID: snap.ShortID, Session: session}
+	}
+}
+
+// handleSnapshotMounted records a finished mount and starts watching the restic process
+func (m Model) handleSnapshotMounted(msg SnapshotMountedMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.mount = snapshotMount{}
+		m.snapshotErr = fmt.Sprintf("Failed to mount snapshot %s: %v", msg.SnapshotID, msg.Err)
+		return m, nil
+	}
+
+	m.mount = snapshotMount{snapshotID: msg.SnapshotID, restic: m.mount.restic, session: msg.Session}
+	session := msg.Session
+	return m, func() tea.Msg {
+		<-session.Done()
+		return SnapshotUnmountedMsg{Session: session}
+	}
+}
+
+// handleSnapshotUnmounted clears the mount once it is gone
+func (m Model) handleSnapshotUnmounted(msg SnapshotUnmountedMsg) (tea.Model, tea.Cmd) {
+	if msg.Err != nil {
+		m.snapshotErr = fmt.Sprintf("Unmount failed: %v", msg.Err)
+	}
+	if msg.Session != m.mount.session {
+		return m, nil
+	}
+
+	// Cleanup also releases the mountpoint when restic exited on its own
+	restic := m.mount.restic
+	m.mount = snapshotMount{}
+	return m, func() tea.Msg {
+		restic.Cleanup()
+		return nil
+	}
+}
+
+// unmountSnapshot releases the active mount in the background
+func (m Model) unmountSnapshot() (tea.Model, tea.Cmd) {
+	if !m.mount.mounted() {
+		return m, nil
+	}
+	session := m.mount.session
+	return m, func() tea.Msg {
+		return SnapshotUnmountedMsg{Session: session, Err: session.Unmount()}
+	}
+}
+
+// releaseMount unmounts synchronously, used when the TUI exits.
+// A mount still waiting for restic is aborted and awaited first.
+func (m Model) releaseMount() {
+	if m.mount.pending {
+		m.mount.cancel()
+		<-m.mount.done
+	}
+	if m.mount.restic != nil {
+		m.mount.restic.Cleanup()
+	}
+}
+
+// viewMountStatus renders the mount status line for the snapshots screen
+func (m Model) viewMountStatus() string {
+	switch {
+	case m.mount.pending:
+		return MutedStyle.Render(fmt.Sprintf("Mounting snapshot %s...", m.mount.snapshotID))
+	case m.mount.mounted():
+		return CyanStyle.Render("Mounted: ") + m.mount.session.Path + MutedStyle.Render("  (U: Unmount)")
+	default:
+		return ""
+	}
+}
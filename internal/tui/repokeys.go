@@ -0,0 +1,274 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"backup-tui/internal/backup"
+)
+
+// What a new password is typed for on the keys screen
+const (
+	keyEntryAdd    = "add"
+	keyEntryPasswd = "passwd"
+)
+
+// keysLoadedMsg carries the keys of the repository
+type keysLoadedMsg struct {
+	keys []backup.Key
+	err  error
+}
+
+// keyActionMsg reports the result of adding or removing a key or changing the password
+type keyActionMsg struct {
+	message string
+	err     error
+}
+
+// keysState is the state of the repository keys screen
+type keysState struct {
+	keys    []backup.Key
+	cursor  int
+	loading bool // restic is running
+	message string
+	failed  bool
+
+	entering string          // keyEntryAdd or keyEntryPasswd while a new password is typed
+	input    textinput.Model // Password entry, typed twice
+	first    string          // The first entry, waiting to be repeated
+}
+
+// openKeys shows the keys screen and starts loading the keys
+func (m Model) openKeys() (tea.Model, tea.Cmd) {
+	m.repoKeys = keysState{loading: true, input: textinput.New()}
+	updated, cmd := m.changeScreen(ScreenKeys)
+	return updated, tea.Batch(cmd, m.loadKeys())
+}
+
+// loadKeys lists the repository keys in the background
+func (m Model) loadKeys() tea.Cmd {
+	ctx, cfg := m.ctx, m.config
+	return func() tea.Msg {
+		restic := backup.NewResticManager(&cfg.LocalBackup, false, nil)
+		defer restic.Cleanup()
+		keys, err := restic.ListKeys(ctx)
+		return keysLoadedMsg{keys: keys, err: err}
+	}
+}
+
+// handleKeysLoaded shows the loaded keys, keeping the cursor in range
+func (m Model) handleKeysLoaded(msg keysLoadedMsg) (tea.Model, tea.Cmd) {
+	k := &m.repoKeys
+	k.loading = false
+	if msg.err != nil {
+		k.message, k.failed = msg.err.Error(), true
+		return m, nil
+	}
+	k.keys = msg.keys
+	if k.cursor >= len(k.keys) {
+		k.cursor = max(len(k.keys)-1, 0)
+	}
+	return m, nil
+}
+
+// handleKeyAction shows the result of a key change and reloads the keys
+func (m Model) handleKeyAction(msg keyActionMsg) (tea.Model, tea.Cmd) {
+	k := &m.repoKeys
+	if msg.err != nil {
+		k.loading = false
+		k.message, k.failed = msg.err.Error(), true
+		return m, nil
+	}
+	k.message, k.failed = msg.message, false
+	return m, m.loadKeys()
+}
+
+// handleKeysKey handles keys on the repository keys screen
+func (m Model) handleKeysKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	k := &m.repoKeys
+	if k.entering != "" {
+		return m.handleKeyEntry(msg)
+	}
+	if k.loading {
+		if msg.String() == keyEsc {
+			return m.changeScreen(ScreenRestic)
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "q":
+		return m.quit()
+	case keyEsc:
+		return m.changeScreen(ScreenRestic)
+	case "up", "k":
+		if k.cursor > 0 {
+			k.cursor--
+		}
+	case "down", "j":
+		if k.cursor < len(k.keys)-1 {
+			k.cursor++
+		}
+	case "r":
+		k.loading, k.message = true, ""
+		return m, m.loadKeys()
+	case "a":
+		return m.startKeyEntry(keyEntryAdd)
+	case "p":
+		return m.startKeyEntry(keyEntryPasswd)
+	case "d":
+		if k.cursor >= len(k.keys) {
+			return m, nil
+		}
+		key := k.keys[k.cursor]
+		if key.Current {
+			k.message, k.failed = "This key opens the repository with the configured password and cannot be removed", true
+			return m, nil
+		}
+		items := []string{fmt.Sprintf("%s  %s@%s  %s", key.ShortID(), key.UserName, key.HostName, key.Created)}
+		return m, m.askConfirm(confirmRemoveKey, "Remove repository key?", items,
+			"Whoever uses this key's password loses access to the repository.", key.ShortID())
+	}
+	return m, nil
+}
+
+// startKeyEntry asks for a new password for action
+func (m Model) startKeyEntry(action string) (tea.Model, tea.Cmd) {
+	k := &m.repoKeys
+	k.entering, k.first, k.message = action, "", ""
+	k.input.Reset()
+	k.input.Prompt = "New password: "
+	k.input.EchoMode = textinput.EchoPassword
+	return m, k.input.Focus()
+}
+
+// handleKeyEntry handles keys while a new password is typed
+func (m Model) handleKeyEntry(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	k := &m.repoKeys
+	switch msg.String() {
+	case keyEsc:
+		k.entering = ""
+		k.input.Blur()
+		return m, nil
+	case keyEnter:
+		value := k.input.Value()
+		if value == "" {
+			return m, nil
+		}
+		if k.first == "" {
+			k.first = value
+			k.input.Reset()
+			k.input.Prompt = "Repeat password: "
+			return m, nil
+		}
+		action := k.entering
+		k.entering = ""
+		k.input.Blur()
+		if value != k.first {
+			k.message, k.failed = "The passwords do not match", true
+			return m, nil
+		}
+		k.loading, k.message = true, ""
+		return m, m.runKeyEntry(action, value)
+	}
+	var cmd tea.Cmd
+	k.input, cmd = k.input.Update(msg)
+	return m, cmd
+}
+
+// runKeyEntry adds a key or changes the password in the background
+func (m Model) runKeyEntry(action, password string) tea.Cmd {
+	ctx, cfg := m.ctx, m.config
+	return func() tea.Msg {
+		svc := backup.NewService(cfg, false, false)
+		if action == keyEntryAdd {
+			if err := svc.AddKey(ctx, password, "", ""); err != nil {
+				return keyActionMsg{err: err}
+			}
+			return keyActionMsg{message: "Key added"}
+		}
+		message, err := svc.ChangePassword(ctx, password)
+		return keyActionMsg{message: message, err: err}
+	}
+}
+
+// removeSelectedKey removes the highlighted key after it was confirmed
+func (m Model) removeSelectedKey() (tea.Model, tea.Cmd) {
+	k := &m.repoKeys
+	if k.cursor >= len(k.keys) {
+		return m, nil
+	}
+	key := k.keys[k.cursor]
+	k.loading, k.message = true, ""
+	ctx, cfg := m.ctx, m.config
+	return m, func() tea.Msg {
+		restic := backup.NewResticManager(&cfg.LocalBackup, false, nil)
+		defer restic.Cleanup()
+		if err := restic.RemoveKey(ctx, key.ID); err != nil {
+			return keyActionMsg{err: err}
+		}
+		return keyActionMsg{message: "Key " + key.ShortID() + " removed"}
+	}
+}
+
+// viewKeys renders the repository keys screen
+func (m Model) viewKeys() string {
+	k := m.repoKeys
+	title := TitleStyle.Render("Repository Keys")
+	instructions := MutedStyle.Render("↑/↓: Navigate  A: Add Key  P: Change Password  D: Remove Key  R: Refresh  ESC: Back  Q: Quit")
+
+	var lines []string
+	switch {
+	case k.loading && len(k.keys) == 0:
+		lines = append(lines, MutedStyle.Render("  Loading keys..."))
+	case len(k.keys) == 0:
+		lines = append(lines, MutedStyle.Render("  No keys loaded"))
+	default:
+		lines = append(lines, MutedStyle.Render(fmt.Sprintf("    %-10s %-30s %s", "ID", "USER@HOST", "CREATED")))
+	}
+	for i, key := range k.keys {
+		cursor := "  "
+		if i == k.cursor {
+			cursor = "> "
+		}
+		current := " "
+		if key.Current {
+			current = SuccessStyle.Render("*")
+		}
+		line := fmt.Sprintf("%s%s %-10s %-30s %s", cursor, current, key.ShortID(), key.UserName+"@"+key.HostName, key.Created)
+		if i == k.cursor {
+			line = lipgloss.NewStyle().Bold(true).Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	var bottom []string
+	switch {
+	case k.entering == keyEntryAdd:
+		bottom = append(bottom, "Password for the new key (ESC: Cancel)", k.input.View())
+	case k.entering == keyEntryPasswd:
+		bottom = append(bottom, "New password for the key in use (ESC: Cancel)", k.input.View())
+	case k.loading && len(k.keys) > 0:
+		bottom = append(bottom, WarningStyle.Render("Working..."))
+	case k.message != "" && k.failed:
+		bottom = append(bottom, ErrorStyle.Render(k.message))
+	case k.message != "":
+		bottom = append(bottom, SuccessStyle.Render(k.message))
+	}
+
+	method := m.config.GetPasswordMethod()
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		instructions,
+		MutedStyle.Render(fmt.Sprintf("%s  (password method: %s, * marks its key)", m.config.LocalBackup.Repository, method)),
+		"",
+		strings.Join(lines, "\n"),
+		"",
+		strings.Join(bottom, "\n"),
+	)
+}
@@ -0,0 +1,306 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"backup-tui/internal/config"
+	"backup-tui/internal/dirlist"
+)
+
+// settingsEditor is the state of the configuration editor screen
+type settingsEditor struct {
+	fields  []config.Field
+	draft   config.Config // Configuration with the edits applied
+	errs    []string      // Input error per field, the field keeps its previous value
+	cursor  int
+	editing bool
+	input   textinput.Model
+	message string // Result of the last save
+	failed  bool
+	discard bool // ESC was pressed once with unsaved changes
+}
+
+// openSettings starts editing a copy of the current configuration
+func (m Model) openSettings() (tea.Model, tea.Cmd) {
+	fields := config.Fields()
+	m.settings = settingsEditor{
+		fields: fields,
+		draft:  *m.config,
+		errs:   make([]string, len(fields)),
+		input:  textinput.New(),
+	}
+	return m.changeScreen(ScreenSettings)
+}
+
+// changedFields returns the fields whose draft value differs from the loaded configuration
+func (s settingsEditor) changedFields(current *config.Config) []config.Field {
+	var changed []config.Field
+	for _, f := range s.fields {
+		if f.Value(&s.draft) != f.Value(current) {
+			changed = append(changed, f)
+		}
+	}
+	return changed
+}
+
+// handleSettingsKey handles keys on the settings screen
+func (m Model) handleSettingsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	s := &m.settings
+	if len(s.fields) == 0 {
+		return m.changeScreen(ScreenMain)
+	}
+	f := s.fields[s.cursor]
+
+	if s.editing {
+		switch msg.String() {
+		case keyEsc:
+			s.editing = false
+			s.input.Blur()
+		case keyEnter:
+			s.editing = false
+			s.input.Blur()
+			s.errs[s.cursor] = ""
+			if err := f.Set(&s.draft, s.input.Value()); err != nil {
+				s.errs[s.cursor] = err.Error()
+			}
+		default:
+			var cmd tea.Cmd
+			s.input, cmd = s.input.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+	}
+
+	if msg.String() != keyEsc {
+		s.discard = false
+	}
+	switch msg.String() {
+	case "q":
+		return m.quit()
+	case keyEsc:
+		if len(s.changedFields(m.config)) > 0 && !s.discard {
+			s.discard = true
+			return m, nil
+		}
+		return m.changeScreen(ScreenMain)
+	case "up", "k":
+		if s.cursor > 0 {
+			s.cursor--
+		}
+	case "down", "j":
+		if s.cursor < len(s.fields)-1 {
+			s.cursor++
+		}
+	case "home", "g":
+		s.cursor = 0
+	case "end", "G":
+		s.cursor = len(s.fields) - 1
+	case keyEnter, " ":
+		// Writing a value would replace the reference with the resolved secret
+		if ref := m.config.Reference(f); ref != "" {
+			s.message, s.failed = fmt.Sprintf("%s is set by %s, change it there", f.Key, ref), true
+			return m, nil
+		}
+		// The include, profile or environment would win over the edited line
+		if origin := m.config.Overridden(f); origin != "" {
+			s.message, s.failed = fmt.Sprintf("%s is set in %s, change it there", f.Key, origin), true
+			return m, nil
+		}
+		switch f.Kind {
+		case config.KindBool:
+			next := "true"
+			if f.Value(&s.draft) == "true" {
+				next = "false"
+			}
+			_ = f.Set(&s.draft, next)
+		case config.KindChoice:
+			current := f.Value(&s.draft)
+			next := f.Choices[0]
+			for i, choice := range f.Choices {
+				if choice == current && i+1 < len(f.Choices) {
+					next = f.Choices[i+1]
+				}
+			}
+			_ = f.Set(&s.draft, next)
+		default:
+			s.input.SetValue(f.Value(&s.draft))
+			s.input.CursorEnd()
+			s.input.Prompt = f.Key + "="
+			s.input.EchoMode = textinput.EchoNormal
+			if f.Secret {
+				s.input.EchoMode = textinput.EchoPassword
+			}
+			s.editing = true
+			return m, s.input.Focus()
+		}
+	case "u":
+		// Undo the edit of the highlighted field
+		_ = f.Set(&s.draft, f.Value(m.config))
+		s.errs[s.cursor] = ""
+	case "s", "ctrl+s":
+		return m.saveSettings()
+	}
+
+	return m, nil
+}
+
+// saveSettings writes the changed fields to config.ini and reloads the configuration
+func (m Model) saveSettings() (tea.Model, tea.Cmd) {
+	s := &m.settings
+	changed := s.changedFields(m.config)
+	if len(changed) == 0 {
+		s.message, s.failed = "No changes to save", false
+		return m, nil
+	}
+	if err := s.draft.Validate(); err != nil {
+		s.message, s.failed = "Fix the configuration errors before saving", true
+		return m, nil
+	}
+	if m.events != nil {
+		s.message, s.failed = "An operation is still running, save once it has finished", true
+		return m, nil
+	}
+
+	path := m.config.ConfigFile
+	if err := config.SaveFields(path, &s.draft, changed); err != nil {
+		s.message, s.failed = err.Error(), true
+		return m, nil
+	}
+	loaded, err := config.LoadProfile(path, m.config.Profile)
+	if err != nil {
+		s.message, s.failed = fmt.Sprintf("Saved, but cannot reload: %v", err), true
+		return m, nil
+	}
+
+	// Swap the pointer rather than updating in place: operations still running in the
+	// background keep reading the configuration they were started with
+	stacksChanged := loaded.Docker.StacksDir != m.config.Docker.StacksDir
+	if loaded.LocalBackup.Repository != m.config.LocalBackup.Repository {
+		m.dashboard.sizeKnown = false
+	}
+	m.config = loaded
+	s.draft = *loaded
+	if stacksChanged {
+		m.dirlist = dirlist.NewManager(m.config.DirlistFile, m.config.LockDir, m.config.Docker.StacksDir)
+		_ = m.dirlist.Load()
+		_, _, _ = m.dirlist.Sync()
+	}
+
+	s.message, s.failed = fmt.Sprintf("Saved %d setting(s) to %s", len(changed), path), false
+	return m, nil
+}
+
+// viewSettings renders the configuration editor
+func (m Model) viewSettings() string {
+	s := m.settings
+	title := TitleStyle.Render("Settings")
+	instructions := MutedStyle.Render("↑/↓: Navigate  ENTER: Edit/Toggle  U: Undo Field  S: Save  ESC: Back  Q: Quit")
+
+	// One line per field below a header per section
+	var lines []string
+	cursorLine := 0
+	section := ""
+	labelWidth := 20
+	for i, f := range s.fields {
+		if f.Section != section {
+			section = f.Section
+			if len(lines) > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, CyanStyle.Render("["+section+"]"))
+		}
+
+		cursor := "  "
+		if i == s.cursor {
+			cursor = "> "
+			cursorLine = len(lines)
+		}
+		value := f.Value(&s.draft)
+		switch {
+		case s.editing && i == s.cursor:
+			value = s.input.View()
+		case m.config.Reference(f) != "" && f.Secret:
+			value = CyanStyle.Render(m.config.Reference(f))
+		case m.config.Reference(f) != "":
+			value += "  " + CyanStyle.Render("← "+m.config.Reference(f))
+		case f.Secret && value != "":
+			value = strings.Repeat("•", 8)
+		case value == "":
+			value = MutedStyle.Render("(not set)")
+		}
+		changed := " "
+		if f.Value(&s.draft) != f.Value(m.config) {
+			changed = WarningStyle.Render("*")
+		}
+
+		line := fmt.Sprintf("%s%s %-*s %s", cursor, changed, labelWidth, f.Label, value)
+		if i == s.cursor && !s.editing {
+			line = lipgloss.NewStyle().Bold(true).Render(line)
+		}
+		if s.errs[i] != "" {
+			line += "  " + ErrorStyle.Render(s.errs[i])
+		}
+		lines = append(lines, line)
+	}
+
+	// Keep the cursor in view when the terminal is short
+	visible := m.height - 14
+	if visible < 5 {
+		visible = 5
+	}
+	start := 0
+	if cursorLine >= visible {
+		start = cursorLine - visible + 1
+	}
+	end := start + visible
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	var help string
+	if len(s.fields) > 0 {
+		f := s.fields[s.cursor]
+		help = MutedStyle.Render(fmt.Sprintf("%s: %s", f.Key, f.Help))
+	}
+
+	// Live validation of the draft
+	check := func(name string, err error) string {
+		if err == nil {
+			return SuccessStyle.Render("✓ " + name)
+		}
+		return ErrorStyle.Render("✗ "+name+": ") + strings.ReplaceAll(strings.TrimPrefix(err.Error(), "configuration errors:\n  - "), "\n  - ", "; ")
+	}
+	backupErr := s.draft.Validate()
+	validation := check("Backup", backupErr)
+	if backupErr == nil {
+		validation += "   " + check("Cloud sync", s.draft.ValidateForCloudSync())
+	}
+
+	var status string
+	switch {
+	case s.discard:
+		status = WarningStyle.Render("Unsaved changes, press ESC again to discard them")
+	case s.message != "" && s.failed:
+		status = ErrorStyle.Render(s.message)
+	case s.message != "":
+		status = SuccessStyle.Render(s.message)
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		instructions,
+		MutedStyle.Render(m.config.ConfigFile),
+		"",
+		strings.Join(lines[start:end], "\n"),
+		"",
+		help,
+		validation,
+		status,
+	)
+}
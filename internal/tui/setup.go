@@ -0,0 +1,209 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"backup-tui/internal/setup"
+)
+
+// setupDoneMsg is sent when the wizard has written the configuration
+type setupDoneMsg struct {
+	output string
+	err    error
+}
+
+// setupWizard is the first-run screen shown when there is no config file.
+// It asks the questions of the setup package one at a time.
+type setupWizard struct {
+	ctx        context.Context
+	configPath string
+	questions  []setup.Question
+	current    int
+	answers    setup.Answers
+	answered   []string // "Prompt: answer" of the questions asked so far
+	input      textinput.Model
+	err        string // Why the last answer was rejected
+
+	running bool
+	output  string
+	result  error
+	done    bool // The configuration was written
+	start   bool // ENTER was pressed after the configuration was written
+}
+
+// newSetupWizard starts the wizard at the first question
+func newSetupWizard(ctx context.Context, configPath string) setupWizard {
+	w := setupWizard{
+		ctx:        ctx,
+		configPath: configPath,
+		questions:  setup.Questions(ctx),
+		input:      textinput.New(),
+		current:    -1,
+	}
+	w.next()
+	return w
+}
+
+// next moves to the next question that applies, returning false after the last one
+func (w *setupWizard) next() bool {
+	for w.current++; w.current < len(w.questions); w.current++ {
+		q := w.questions[w.current]
+		if q.Skip(w.answers) {
+			continue
+		}
+		w.input.Reset()
+		w.input.Placeholder = q.Default(w.answers)
+		w.input.EchoMode = textinput.EchoNormal
+		if q.Secret {
+			w.input.EchoMode = textinput.EchoPassword
+		}
+		w.input.Focus()
+		return true
+	}
+	return false
+}
+
+func (w setupWizard) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (w setupWizard) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case setupDoneMsg:
+		w.running = false
+		w.output, w.result = msg.output, msg.err
+		w.done = msg.err == nil
+		return w, nil
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return w, tea.Quit
+		}
+		if w.running {
+			return w, nil
+		}
+		if w.done || w.result != nil {
+			// Finished: ENTER continues to the main menu, ESC leaves
+			switch msg.String() {
+			case keyEnter:
+				w.start = w.done
+				return w, tea.Quit
+			case keyEsc:
+				return w, tea.Quit
+			}
+			return w, nil
+		}
+
+		switch msg.String() {
+		case keyEsc:
+			return w, tea.Quit
+		case keyEnter:
+			return w.answer()
+		}
+	}
+
+	var cmd tea.Cmd
+	w.input, cmd = w.input.Update(msg)
+	return w, cmd
+}
+
+// answer stores the typed answer and asks the next question, or writes the
+// configuration after the last one
+func (w setupWizard) answer() (tea.Model, tea.Cmd) {
+	q := w.questions[w.current]
+	value, err := q.Answer(&w.answers, w.input.Value())
+	if err != nil {
+		w.err = err.Error()
+		return w, nil
+	}
+	w.err = ""
+
+	switch {
+	case q.Secret:
+		value = strings.Repeat("•", 8)
+	case value == "":
+		value = MutedStyle.Render("(none)")
+	}
+	w.answered = append(w.answered, fmt.Sprintf("%s: %s", q.Prompt, value))
+	if w.next() {
+		return w, nil
+	}
+
+	w.running = true
+	w.input.Blur()
+	ctx, path, answers := w.ctx, w.configPath, w.answers
+	return w, func() tea.Msg {
+		var out bytes.Buffer
+		err := setup.Run(ctx, path, answers, &out)
+		return setupDoneMsg{output: out.String(), err: err}
+	}
+}
+
+func (w setupWizard) View() string {
+	title := TitleStyle.Render("First-run Setup")
+	intro := MutedStyle.Render("No configuration found, the answers are written to " + w.configPath)
+
+	var lines []string
+	for _, a := range w.answered {
+		lines = append(lines, SuccessStyle.Render("✓ ")+a)
+	}
+	lines = append(lines, "")
+
+	var footer string
+	switch {
+	case w.running:
+		lines = append(lines, WarningStyle.Render("Writing the configuration..."))
+	case w.result != nil:
+		lines = append(lines, strings.TrimRight(w.output, "\n"), ErrorStyle.Render("Setup failed: "+w.result.Error()))
+		footer = "ESC: Quit"
+	case w.done:
+		lines = append(lines, strings.TrimRight(w.output, "\n"), SuccessStyle.Render("Setup complete"))
+		footer = "ENTER: Continue to the main menu  ESC: Quit"
+	default:
+		q := w.questions[w.current]
+		if q.Help != "" {
+			lines = append(lines, MutedStyle.Render(q.Help))
+		}
+		prompt := q.Prompt
+		if len(q.Choices) > 0 {
+			prompt += " (" + strings.Join(q.Choices, "/") + ")"
+		}
+		lines = append(lines, lipgloss.NewStyle().Bold(true).Render(prompt), w.input.View())
+		if w.err != "" {
+			lines = append(lines, ErrorStyle.Render(w.err))
+		}
+		footer = "ENTER: Next (empty for the default)  ESC: Quit"
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		title,
+		intro,
+		"",
+		strings.Join(lines, "\n"),
+		"",
+		Footer(footer),
+	)
+}
+
+// RunSetup runs the first-run wizard, which writes a new configuration to
+// configPath. It reports whether the user asked to continue to the main menu.
+func RunSetup(ctx context.Context, configPath string) (bool, error) {
+	p := tea.NewProgram(newSetupWizard(ctx, configPath), tea.WithAltScreen())
+
+	stop := context.AfterFunc(ctx, p.Quit)
+	defer stop()
+
+	final, err := p.Run()
+	if err != nil {
+		return false, err
+	}
+	w, _ := final.(setupWizard)
+	return w.start, nil
+}
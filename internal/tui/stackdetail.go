@@ -0,0 +1,350 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"backup-tui/internal/backup"
+	"backup-tui/internal/config"
+	"backup-tui/internal/dirlist"
+	"backup-tui/internal/util"
+)
+
+// stackDetailDelay is how long the cursor has to rest on an entry before its details are loaded
+const stackDetailDelay = 300 * time.Millisecond
+
+// Stack actions offered in the detail pane
+const (
+	stackActionStart   = "start"
+	stackActionStop    = "stop"
+	stackActionRestart = "restart"
+)
+
+// stackDetail is what the dirlist detail pane shows for one entry
+type stackDetail struct {
+	ID           string
+	Path         string
+	ComposeFile  string
+	State        backup.StackState
+	Services     []string
+	Containers   []string
+	Size         int64
+	LastSnapshot time.Time
+	SnapshotID   string
+	Errors       []string // Details that could not be gathered
+}
+
+// stackDetailState caches loaded details per entry and tracks stack actions
+type stackDetailState struct {
+	details   map[string]*stackDetail
+	loading   map[string]bool
+	pending   string // Action waiting for confirmation
+	running   string // Action in progress
+	result    string // Outcome of the last action
+	resultErr bool
+}
+
+// stackDetailRequestMsg asks for the details of an entry once the cursor has rested on it
+type stackDetailRequestMsg struct{ ID string }
+
+// stackDetailMsg carries freshly gathered details of an entry
+type stackDetailMsg struct{ Detail stackDetail }
+
+// stackActionMsg reports the result of a start, stop or restart
+type stackActionMsg struct {
+	ID     string
+	Action string
+	Err    error
+}
+
+// resetStackDetails drops all cached details
+func (m *Model) resetStackDetails() {
+	m.detail = stackDetailState{
+		details: make(map[string]*stackDetail),
+		loading: make(map[string]bool),
+	}
+}
+
+// currentDirlistEntry returns the identifier under the dirlist cursor
+func (m Model) currentDirlistEntry() string {
+	if len(m.dirlistDirs) == 0 {
+		return ""
+	}
+	return m.dirlistDirs[m.dirlistCursor]
+}
+
+// scheduleStackDetail requests the details of the highlighted entry after stackDetailDelay,
+// so scrolling through the list does not start a docker and restic call per entry
+func (m Model) scheduleStackDetail() tea.Cmd {
+	id := m.currentDirlistEntry()
+	if id == "" || m.detail.details[id] != nil || m.detail.loading[id] {
+		return nil
+	}
+	return tea.Tick(stackDetailDelay, func(time.Time) tea.Msg {
+		return stackDetailRequestMsg{ID: id}
+	})
+}
+
+// handleStackDetailRequest loads the requested details if the entry is still highlighted
+func (m Model) handleStackDetailRequest(msg stackDetailRequestMsg) (tea.Model, tea.Cmd) {
+	if m.screen != ScreenDirlist || m.currentDirlistEntry() != msg.ID {
+		return m, nil
+	}
+	return m, m.loadStackDetail(msg.ID)
+}
+
+// loadStackDetail gathers the details of an entry in the background
+func (m *Model) loadStackDetail(id string) tea.Cmd {
+	if m.detail.loading[id] {
+		return nil
+	}
+	m.detail.loading[id] = true
+	ctx, cfg := m.ctx, m.config
+	path, tag := m.dirlist.GetFullPath(id), m.dirlist.SnapshotTag(id)
+
+	return func() tea.Msg {
+		return stackDetailMsg{Detail: gatherStackDetail(ctx, cfg, id, path, tag)}
+	}
+}
+
+// gatherStackDetail collects compose, Docker, size and snapshot details of one stack
+func gatherStackDetail(ctx context.Context, cfg *config.Config, id, path, tag string) stackDetail {
+	d := stackDetail{ID: id, Path: path, ComposeFile: dirlist.GetComposeFile(path), State: backup.StateUnknown}
+	fail := func(what string, err error) {
+		d.Errors = append(d.Errors, fmt.Sprintf("%s: %v", what, err))
+	}
+
+	docker := backup.NewDockerManager(cfg.Docker.Timeout, false, io.Discard)
+	// Services and containers are only asked for once Docker answered at all
+	if state, err := docker.CheckStackStatus(ctx, path); err != nil {
+		fail("docker", err)
+	} else {
+		d.State = state
+		if services, err := docker.GetStackServices(ctx, path); err != nil {
+			fail("services", err)
+		} else {
+			d.Services = services
+		}
+		if containers, err := docker.GetStackContainers(ctx, path); err != nil {
+			fail("containers", err)
+		} else {
+			d.Containers = containers
+		}
+	}
+
+	if size, err := util.DirSize(path); err != nil {
+		fail("size", err)
+	} else {
+		d.Size = size
+	}
+
+	restic := backup.NewResticManager(&cfg.LocalBackup, false, nil)
+	if err := restic.SetupEnv(); err != nil {
+		fail("snapshots", err)
+		return d
+	}
+	defer restic.Cleanup()
+	snapshots, err := restic.ListSnapshots(ctx, tag, 1)
+	if err != nil {
+		fail("snapshots", err)
+		return d
+	}
+	for _, snap := range snapshots {
+		t, err := time.Parse(time.RFC3339Nano, snap.Time)
+		if err == nil && t.After(d.LastSnapshot) {
+			d.LastSnapshot, d.SnapshotID = t, snap.ShortID
+		}
+	}
+
+	return d
+}
+
+// handleStackDetail caches gathered details
+func (m Model) handleStackDetail(msg stackDetailMsg) (tea.Model, tea.Cmd) {
+	if m.detail.details == nil {
+		return m, nil
+	}
+	delete(m.detail.loading, msg.Detail.ID)
+	detail := msg.Detail
+	m.detail.details[detail.ID] = &detail
+	return m, nil
+}
+
+// requestStackAction asks for confirmation of an action on the highlighted stack
+func (m *Model) requestStackAction(action string) {
+	if m.currentDirlistEntry() == "" || m.detail.running != "" {
+		return
+	}
+	m.detail.pending = action
+	m.detail.result = ""
+}
+
+// confirmStackAction runs the pending action on the highlighted stack
+func (m *Model) confirmStackAction() tea.Cmd {
+	action, id := m.detail.pending, m.currentDirlistEntry()
+	m.detail.pending = ""
+	if action == "" || id == "" {
+		return nil
+	}
+	m.detail.running = action
+	ctx, cfg, path := m.ctx, m.config, m.dirlist.GetFullPath(id)
+
+	return func() tea.Msg {
+		// Compose output and progress would be drawn over the screen, the result
+		// is shown by handleStackAction instead
+		docker := backup.NewDockerManager(cfg.Docker.Timeout, false, io.Discard)
+		docker.SetQuiet(true)
+		var err error
+		switch action {
+		case stackActionStart:
+			err = docker.Start(ctx, id, path)
+		case stackActionStop:
+			err = docker.Stop(ctx, id, path)
+		case stackActionRestart:
+			err = docker.Restart(ctx, id, path)
+		}
+		return stackActionMsg{ID: id, Action: action, Err: err}
+	}
+}
+
+// handleStackAction records the outcome of an action and reloads the stack's details
+func (m Model) handleStackAction(msg stackActionMsg) (tea.Model, tea.Cmd) {
+	m.detail.running = ""
+	if msg.Err != nil {
+		m.detail.result = fmt.Sprintf("%s %s failed: %v", msg.Action, msg.ID, msg.Err)
+		m.detail.resultErr = true
+	} else {
+		m.detail.result = fmt.Sprintf("%s %s: done", msg.Action, msg.ID)
+		m.detail.resultErr = false
+	}
+	if m.detail.details == nil {
+		return m, nil
+	}
+	delete(m.detail.details, msg.ID)
+	return m, m.loadStackDetail(msg.ID)
+}
+
+// refreshStackDetail reloads the details of the highlighted entry
+func (m *Model) refreshStackDetail() tea.Cmd {
+	id := m.currentDirlistEntry()
+	if id == "" {
+		return nil
+	}
+	delete(m.detail.details, id)
+	return m.loadStackDetail(id)
+}
+
+// viewStackDetail renders the detail pane for the highlighted entry within width
+func (m Model) viewStackDetail(width int) string {
+	id := m.currentDirlistEntry()
+	if id == "" {
+		return ""
+	}
+	label := func(s string) string { return CyanStyle.Render(fmt.Sprintf("%-12s", s)) }
+	entry := m.dirlist.GetEntry(id)
+
+	lines := []string{TitleStyle.Render(truncateLeft(id, width))}
+
+	d := m.detail.details[id]
+	switch {
+	case d == nil && m.detail.loading[id]:
+		lines = append(lines, MutedStyle.Render("Loading details..."))
+	case d == nil:
+		lines = append(lines, label("Path")+truncateLeft(m.dirlist.GetFullPath(id), width-12))
+	default:
+		compose := d.ComposeFile
+		if compose == "" {
+			compose = ErrorStyle.Render("not found")
+		} else {
+			compose = truncateLeft(compose, width-12)
+		}
+		state := string(d.State)
+		switch d.State {
+		case backup.StateRunning:
+			state = SuccessStyle.Render(state)
+		case backup.StateStopped:
+			state = WarningStyle.Render(state)
+		default:
+			state = MutedStyle.Render(state)
+		}
+		snapshot := ErrorStyle.Render("never")
+		if !d.LastSnapshot.IsZero() {
+			snapshot = fmt.Sprintf("%s  %s (%s ago)", d.SnapshotID,
+				d.LastSnapshot.Local().Format("2006-01-02 15:04"), formatAge(time.Since(d.LastSnapshot)))
+		}
+
+		lines = append(lines,
+			label("Path")+truncateLeft(d.Path, width-12),
+			label("Compose")+compose,
+			label("Docker")+state,
+			label("Size")+util.FormatSize(d.Size),
+			label("Last backup")+snapshot,
+			"",
+			CyanStyle.Render(fmt.Sprintf("Services (%d)", len(d.Services))),
+		)
+		for _, svc := range d.Services {
+			lines = append(lines, "  "+truncateRight(svc, width-2))
+		}
+		lines = append(lines, CyanStyle.Render(fmt.Sprintf("Containers (%d)", len(d.Containers))))
+		for _, c := range d.Containers {
+			lines = append(lines, "  "+truncateRight(c, width-2))
+		}
+		for _, e := range d.Errors {
+			lines = append(lines, ErrorStyle.Render(truncateRight(e, width)))
+		}
+	}
+
+	// Settings that apply to this stack
+	lb := &m.config.LocalBackup
+	enabled := DisabledStyle.Render("no")
+	if m.dirlistSelections[id] {
+		enabled = SuccessStyle.Render("yes")
+	}
+	kind := "discovered"
+	if entry != nil && entry.IsExternal {
+		kind = "external"
+	}
+	verify := "off"
+	if lb.EnableVerification {
+		verify = lb.VerificationDepth
+	}
+	lines = append(lines, "",
+		CyanStyle.Render("Settings"),
+		label("Enabled")+enabled+MutedStyle.Render("  ("+kind+")"),
+		label("Tag")+m.dirlist.SnapshotTag(id),
+		label("Retention")+fmt.Sprintf("%dd %dw %dm %dy", lb.KeepDaily, lb.KeepWeekly, lb.KeepMonthly, lb.KeepYearly),
+		label("Verify")+verify,
+		label("Stop wait")+fmt.Sprintf("%ds", m.config.Docker.Timeout),
+	)
+
+	// Action line
+	s := m.detail
+	lines = append(lines, "")
+	switch {
+	case s.pending != "":
+		lines = append(lines, WarningStyle.Render(fmt.Sprintf("%s %s? Y: Confirm  any other key: Cancel", capitalize(s.pending), id)))
+	case s.running != "":
+		lines = append(lines, WarningStyle.Render(fmt.Sprintf("Running %s...", s.running)))
+	case s.result != "" && s.resultErr:
+		lines = append(lines, ErrorStyle.Render(truncateRight(s.result, width)))
+	case s.result != "":
+		lines = append(lines, SuccessStyle.Render(truncateRight(s.result, width)))
+	}
+	lines = append(lines, MutedStyle.Render("U: Start  T: Stop  R: Restart  I: Refresh"))
+
+	return lipgloss.NewStyle().Width(width).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// capitalize upper-cases the first letter of s
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,183 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"backup-tui/internal/backup"
+	"backup-tui/internal/stats"
+	"backup-tui/internal/util"
+)
+
+// statsCollectedMsg reports the end of a repository measurement
+type statsCollectedMsg struct {
+	err error
+}
+
+// statsState is the state of the repository statistics screen
+type statsState struct {
+	samples []stats.Sample // Oldest first
+	loading bool           // restic stats is running
+	err     string
+}
+
+// sparkBars are the levels of the stored data trend line
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// openStats shows the recorded statistics, measuring the repository when there are none yet
+func (m Model) openStats() (tea.Model, tea.Cmd) {
+	m.loadStats()
+	updated, cmd := m.changeScreen(ScreenStats)
+	if len(m.repoStats.samples) > 0 || m.repoStats.err != "" {
+		return updated, cmd
+	}
+	next := updated.(Model)
+	return next, tea.Batch(cmd, next.collectStats())
+}
+
+// loadStats reads the recorded samples
+func (m *Model) loadStats() {
+	samples, err := stats.Load(m.config.LogDir)
+	m.repoStats.samples, m.repoStats.err = samples, ""
+	if err != nil {
+		m.repoStats.err = err.Error()
+	}
+}
+
+// collectStats measures the repository in the background and records the sample
+func (m *Model) collectStats() tea.Cmd {
+	m.repoStats.loading, m.repoStats.err = true, ""
+	ctx, cfg := m.ctx, m.config
+	return func() tea.Msg {
+		_, err := backup.NewService(cfg, false, false).CollectStats(ctx, nil)
+		return statsCollectedMsg{err: err}
+	}
+}
+
+// handleStatsCollected shows the new sample or the error
+func (m Model) handleStatsCollected(msg statsCollectedMsg) (tea.Model, tea.Cmd) {
+	m.loadStats()
+	m.repoStats.loading = false
+	if msg.err != nil {
+		m.repoStats.err = msg.err.Error()
+	}
+	return m, nil
+}
+
+// handleStatsKey handles keys on the statistics screen
+func (m Model) handleStatsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q":
+		return m.quit()
+	case keyEsc:
+		return m.changeScreen(ScreenRestic)
+	case "r":
+		if !m.repoStats.loading {
+			cmd := m.collectStats()
+			return m, cmd
+		}
+	}
+	return m, nil
+}
+
+// sparkline draws values as a line of bars scaled between their minimum and maximum
+func sparkline(values []int64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo, hi = min(lo, v), max(hi, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		level := len(sparkBars) - 1
+		if hi > lo {
+			level = int((v - lo) * int64(len(sparkBars)-1) / (hi - lo))
+		}
+		b.WriteRune(sparkBars[level])
+	}
+	return b.String()
+}
+
+// viewStats renders the repository statistics screen
+func (m Model) viewStats() string {
+	const day = 24 * time.Hour
+	st := m.repoStats
+	title := TitleStyle.Render("Repository Statistics")
+	instructions := MutedStyle.Render("R: Measure Now  ESC: Back  Q: Quit")
+
+	var status string
+	switch {
+	case st.loading:
+		status = WarningStyle.Render("Measuring the repository with restic stats, this can take a while...")
+	case st.err != "":
+		status = ErrorStyle.Render(st.err)
+	}
+
+	if len(st.samples) == 0 {
+		body := MutedStyle.Render("  No statistics recorded yet")
+		return lipgloss.JoinVertical(lipgloss.Left, title, instructions, "", body, "", status)
+	}
+
+	last := st.samples[len(st.samples)-1]
+	repo := last.Repository
+	lines := []string{
+		fmt.Sprintf("Measured:      %s (%d measurements recorded)", last.Time.Format("2006-01-02 15:04"), len(st.samples)),
+		fmt.Sprintf("Snapshots:     %d", repo.Snapshots),
+		fmt.Sprintf("Restore size:  %s in %d files", util.FormatSize(repo.RestoreSize), repo.FileCount),
+		fmt.Sprintf("Stored:        %s", util.FormatSize(repo.RawData)),
+	}
+	if repo.CompressionRatio > 0 {
+		lines = append(lines, fmt.Sprintf("Uncompressed:  %s (compression ratio %.2fx)", util.FormatSize(repo.Uncompressed), repo.CompressionRatio))
+	}
+	lines = append(lines, fmt.Sprintf("Growth:        %s over 7 days, %s over 30 days",
+		stats.FormatGrowth(stats.GrowthPerDay(st.samples, 7*day, stats.RepositoryRawData)),
+		stats.FormatGrowth(stats.GrowthPerDay(st.samples, 30*day, stats.RepositoryRawData))))
+
+	// Trend of the stored data over the most recent measurements
+	recent := st.samples[max(len(st.samples)-40, 0):]
+	if len(recent) > 1 {
+		values := make([]int64, len(recent))
+		for i, s := range recent {
+			values[i] = s.Repository.RawData
+		}
+		lines = append(lines, fmt.Sprintf("Trend:         %s  since %s", SuccessStyle.Render(sparkline(values)), recent[0].Time.Format("2006-01-02")))
+	}
+
+	// Stacks, largest first, with a bar relative to the largest
+	tags := stats.Largest(last)
+	table := []string{MutedStyle.Render(fmt.Sprintf("  %-24s %-20s %12s %12s %6s %16s", "STACK", "", "SIZE", "STORED", "SNAPS", "GROWTH (30D)"))}
+	var largest int64
+	if len(tags) > 0 {
+		largest = last.Stacks[tags[0]].RestoreSize
+	}
+	for _, tag := range tags {
+		u := last.Stacks[tag]
+		width := 0
+		if largest > 0 {
+			width = int(u.RestoreSize * 20 / largest)
+		}
+		bar := strings.Repeat("█", width) + strings.Repeat(" ", 20-width)
+		growth := stats.FormatGrowth(stats.GrowthPerDay(st.samples, 30*day, stats.StackRawData(tag)))
+		table = append(table, fmt.Sprintf("  %-24s %s %12s %12s %6d %16s",
+			truncateRight(tag, 24), SuccessStyle.Render(bar), util.FormatSize(u.RestoreSize), util.FormatSize(u.RawData), u.Snapshots, growth))
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		instructions,
+		"",
+		strings.Join(lines, "\n"),
+		"",
+		strings.Join(table, "\n"),
+		"",
+		MutedStyle.Render("Stored data of stacks overlaps where they share files. Run 'stats' from cron to build up the trend."),
+		status,
+	)
+}
@@ -0,0 +1,158 @@
+package tui
+
+import (
+	"context"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"backup-tui/internal/util"
+)
+
+// streamEventMsg wraps a message produced by a background operation
+type streamEventMsg struct {
+	ch  chan tea.Msg
+	msg tea.Msg
+}
+
+// streamClosedMsg is sent once a background operation has finished
+type streamClosedMsg struct {
+	ch chan tea.Msg
+}
+
+// shutdownMsg asks the TUI to cancel any running operation and quit
+type shutdownMsg struct{}
+
+// emitFunc sends a message from a background operation to the update loop
+type emitFunc func(tea.Msg)
+
+// startStream runs an operation in a goroutine and streams its messages into the update loop.
+// Log output is forwarded while the operation runs; the message returned by run is sent last.
+// The operation's context is cancelled by cancelOperation or when the TUI shuts down.
+// Only one operation runs at a time, callers check busy before resetting the output.
+func (m *Model) startStream(run func(ctx context.Context, emit emitFunc) tea.Msg) tea.Cmd {
+	ch := make(chan tea.Msg, 256)
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.events = ch
+	m.cancel = cancel
+	m.cancelling = false
+
+	go func() {
+		defer close(ch)
+		defer cancel()
+		// Once the operation is cancelled the update loop may be gone, so a full
+		// buffer drops messages instead of blocking the operation
+		emit := func(msg tea.Msg) {
+			select {
+			case ch <- msg:
+				return
+			default:
+			}
+			select {
+			case ch <- msg:
+			case <-ctx.Done():
+			}
+		}
+
+		util.SetLogOutputFunc(func(s string) {
+			emit(CommandOutputMsg{Output: styleLogLine(s)})
+		})
+		done := run(ctx, emit)
+		util.ClearLogOutputFunc()
+
+		emit(done)
+	}()
+
+	return waitForEvent(ch)
+}
+
+// busy reports whether an operation is still running. Its output is shown again with
+// a notice, as a second operation would mix its output and log lines into it.
+func (m *Model) busy() bool {
+	if m.events == nil {
+		return false
+	}
+	m.outputContent.WriteString(WarningStyle.Render("Another operation is still running: wait for it to finish or press X to cancel it") + "\n")
+	if m.screen != ScreenOutput {
+		m.prevScreen = m.screen
+		m.screen = ScreenOutput
+	}
+	if m.outputReady {
+		m.outputViewport.SetContent(m.outputContent.String())
+		m.outputViewport.GotoBottom()
+	}
+	return true
+}
+
+// waitForEvent returns a command that waits for the next message on the stream
+func waitForEvent(ch chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-ch
+		if !ok {
+			return streamClosedMsg{ch: ch}
+		}
+		return streamEventMsg{ch: ch, msg: msg}
+	}
+}
+
+// quit leaves the TUI. A running operation is cancelled first and the TUI quits once
+// it has returned, so its child processes are stopped and stacks are restarted.
+func (m Model) quit() (tea.Model, tea.Cmd) {
+	if m.events != nil {
+		m.quitWhenDone = true
+		return m.cancelOperation()
+	}
+	m.quitting = true
+	return m, tea.Quit
+}
+
+// cancelOperation asks the streamed operation to stop
+func (m Model) cancelOperation() (tea.Model, tea.Cmd) {
+	if m.events == nil || m.cancel == nil || m.cancelling {
+		return m, nil
+	}
+	m.cancelling = true
+	m.cancel()
+
+	notice := "Cancelling..."
+	if m.backupRun.running {
+		notice = "Cancelling: the current stack is interrupted and restarted, remaining stacks are skipped"
+	}
+	m.outputContent.WriteString(WarningStyle.Render(notice) + "\n")
+	if m.outputReady {
+		m.outputViewport.SetContent(m.outputContent.String())
+		m.outputViewport.GotoBottom()
+	}
+	return m, nil
+}
+
+// streamWriter implements io.Writer by emitting command output messages
+type streamWriter struct {
+	emit emitFunc
+}
+
+func (w *streamWriter) Write(p []byte) (n int, err error) {
+	w.emit(CommandOutputMsg{Output: string(p)})
+	return len(p), nil
+}
+
+// styleLogLine colors a log line according to its level
+func styleLogLine(line string) string {
+	trailing := ""
+	if strings.HasSuffix(line, "\n") {
+		line = strings.TrimSuffix(line, "\n")
+		trailing = "\n"
+	}
+
+	switch {
+	case strings.Contains(line, "[ERROR]"):
+		line = ErrorStyle.Render(line)
+	case strings.Contains(line, "[WARN]"):
+		line = WarningStyle.Render(line)
+	case strings.Contains(line, "[SUCCESS]"):
+		line = SuccessStyle.Render(line)
+	case strings.Contains(line, "[PROGRESS]"):
+		line = CyanStyle.Render(line)
+	}
+	return line + trailing
+}
@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"backup-tui/internal/cloud"
+	"backup-tui/internal/util"
+)
+
+// maxTransferErrors limits how many per-file errors are kept for the progress panel
+const maxTransferErrors = 50
+
+// transferState holds the progress panel state for a running sync or restore
+type transferState struct {
+	active    bool
+	operation string
+	hasStats  bool
+	stats     cloud.TransferStats
+	errors    []cloud.TransferError
+}
+
+// newTransferState creates progress panel state for an operation
+func newTransferState(operation string) transferState {
+	return transferState{active: true, operation: operation}
+}
+
+// transferProgressFunc converts rclone progress events into TUI messages
+func transferProgressFunc(emit emitFunc) cloud.ProgressFunc {
+	return func(ev cloud.ProgressEvent) {
+		switch {
+		case ev.Stats != nil:
+			emit(TransferStatsMsg{Stats: *ev.Stats})
+		case ev.Error != nil:
+			emit(TransferErrorMsg{Error: *ev.Error})
+		}
+	}
+}
+
+// addError records a per-file error, keeping only the most recent ones
+func (t *transferState) addError(e cloud.TransferError) {
+	t.errors = append(t.errors, e)
+	if len(t.errors) > maxTransferErrors {
+		t.errors = t.errors[len(t.errors)-maxTransferErrors:]
+	}
+}
+
+// viewTransferPanel renders the progress panel shown above the output viewport
+func (m Model) viewTransferPanel() string {
+	t := m.transfer
+	width := m.width - 4
+	if width < 30 {
+		width = 30
+	}
+
+	if !t.hasStats {
+		return MenuBoxStyle.Width(width).Render(CyanStyle.Render(t.operation) + "  " + MutedStyle.Render("waiting for rclone statistics..."))
+	}
+
+	s := t.stats
+	barWidth := width - 12
+	if barWidth > 60 {
+		barWidth = 60
+	}
+	header := fmt.Sprintf("%s  %s %3.0f%%", CyanStyle.Render(t.operation), progressBar(s.Percent(), barWidth), s.Percent()*100)
+
+	eta := "-"
+	if s.ETA >= 0 {
+		eta = s.ETA.Round(time.Second).String()
+	}
+	errCount := fmt.Sprintf("Errors %d", s.Errors)
+	if s.Errors > 0 {
+		errCount = ErrorStyle.Render(errCount)
+	}
+	details := fmt.Sprintf("%s / %s  •  %s/s  •  ETA %s  •  Files %d/%d  •  %s",
+		util.FormatSize(s.Bytes), util.FormatSize(s.TotalBytes),
+		util.FormatSize(int64(s.Speed)), eta,
+		s.Transfers, s.TotalTransfers, errCount)
+
+	lines := []string{header, details}
+
+	for i, name := range s.Transferring {
+		if i == 3 {
+			lines = append(lines, MutedStyle.Render(fmt.Sprintf("  … and %d more", len(s.Transferring)-3)))
+			break
+		}
+		lines = append(lines, MutedStyle.Render("  ↳ "+truncateLeft(name, width-6)))
+	}
+
+	if n := len(t.errors); n > 0 {
+		start := n - 3
+		if start < 0 {
+			start = 0
+		}
+		for _, e := range t.errors[start:] {
+			msg := e.Message
+			if e.Object != "" {
+				msg = e.Object + ": " + msg
+			}
+			lines = append(lines, ErrorStyle.Render("  ✗ "+truncateRight(msg, width-6)))
+		}
+	}
+
+	return MenuBoxStyle.Width(width).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// progressBar renders a simple text progress bar
+func progressBar(percent float64, width int) string {
+	if width < 1 {
+		return ""
+	}
+	filled := int(percent * float64(width))
+	if filled > width {
+		filled = width
+	}
+	return SuccessStyle.Render(strings.Repeat("━", filled)) + MutedStyle.Render(strings.Repeat("─", width-filled))
+}
+
+// truncateLeft shortens s from the left, keeping the end visible
+func truncateLeft(s string, maxLen int) string {
+	if maxLen < 4 || len(s) <= maxLen {
+		return s
+	}
+	return "..." + s[len(s)-maxLen+3:]
+}
+
+// truncateRight shortens s from the right, keeping the start visible
+func truncateRight(s string, maxLen int) string {
+	if maxLen < 4 || len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen-3] + "..."
+}
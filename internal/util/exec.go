@@ -14,11 +14,12 @@ import (
 
 // CommandResult holds the result of a command execution
 type CommandResult struct {
-	ExitCode int
-	Stdout   string
-	Stderr   string
-	Duration time.Duration
-	TimedOut bool
+	ExitCode  int
+	Stdout    string
+	Stderr    string
+	Duration  time.Duration
+	TimedOut  bool
+	Cancelled bool // The caller's context was cancelled before the command finished
 }
 
 // IsSuccess returns true if the command succeeded (exit code 0)
@@ -50,6 +51,9 @@ type CommandOptions struct {
 	OutputWriter io.Writer         // Custom writer for output (if set, used instead of os.Stdout/Stderr)
 }
 
+// cancelGracePeriod is how long a cancelled command may take to exit after SIGINT before it is killed
+const cancelGracePeriod = 10 * time.Second
+
 // DefaultOptions returns default command options
 func DefaultOptions() CommandOptions {
 	return CommandOptions{
@@ -88,17 +92,25 @@ func setupOutputWriters(opts CommandOptions) (stdout, stderr *bytes.Buffer, stdo
 	return stdout, stderr, stdoutWriters, stderrWriters
 }
 
-// RunCommand executes a command with the given options
-func RunCommand(name string, args []string, opts CommandOptions) (*CommandResult, error) {
+// RunCommand executes a command with the given options.
+// When ctx is cancelled the process group receives SIGINT so tools like restic and rclone
+// can exit cleanly; it is killed if it is still running after cancelGracePeriod.
+func RunCommand(ctx context.Context, name string, args []string, opts CommandOptions) (*CommandResult, error) {
 	start := time.Now()
 	result := &CommandResult{}
 
-	// Create context with timeout if specified
-	ctx := context.Background()
-	var cancel context.CancelFunc
+	if err := ctx.Err(); err != nil {
+		result.ExitCode = -1
+		result.Cancelled = true
+		return result, fmt.Errorf("command cancelled: %w", err)
+	}
+
+	// Create timer for the timeout if specified
+	var timeout <-chan time.Time
 	if opts.Timeout > 0 {
-		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
-		defer cancel()
+		timer := time.NewTimer(opts.Timeout)
+		defer timer.Stop()
+		timeout = timer.C
 	}
 
 	// Create command (not using CommandContext - we'll handle timeout manually for process group support)
@@ -136,33 +148,40 @@ func RunCommand(name string, args []string, opts CommandOptions) (*CommandResult
 		return result, fmt.Errorf("failed to start command: %w", err)
 	}
 
-	// Wait for command completion or timeout
+	// Wait for command completion, timeout or cancellation
 	done := make(chan error, 1)
 	go func() {
 		done <- cmd.Wait()
 	}()
 
+	finish := func() {
+		result.ExitCode = -1
+		result.Duration = time.Since(start)
+		result.Stdout = strings.TrimSpace(stdout.String())
+		result.Stderr = strings.TrimSpace(stderr.String())
+	}
+
 	var err error
 	select {
-	case <-ctx.Done():
+	case <-timeout:
 		// Timeout occurred - kill the entire process group
-		if cmd.Process != nil {
-			// Kill process group (negative PID kills all processes in the group)
-			pgid, pgidErr := syscall.Getpgid(cmd.Process.Pid)
-			if pgidErr == nil {
-				_ = syscall.Kill(-pgid, syscall.SIGKILL)
-			} else {
-				// Fallback to killing just the process
-				_ = cmd.Process.Kill()
-			}
-		}
+		signalProcessGroup(cmd, syscall.SIGKILL)
 		<-done // Wait for the process to actually exit
 		result.TimedOut = true
-		result.ExitCode = -1
-		result.Duration = time.Since(start)
-		result.Stdout = strings.TrimSpace(stdout.String())
-		result.Stderr = strings.TrimSpace(stderr.String())
+		finish()
 		return result, fmt.Errorf("command timed out after %v", opts.Timeout)
+	case <-ctx.Done():
+		// Cancelled - interrupt first, kill if the process does not exit in time
+		signalProcessGroup(cmd, syscall.SIGINT)
+		select {
+		case <-done:
+		case <-time.After(cancelGracePeriod):
+			signalProcessGroup(cmd, syscall.SIGKILL)
+			<-done
+		}
+		result.Cancelled = true
+		finish()
+		return result, fmt.Errorf("command cancelled: %w", ctx.Err())
 	case err = <-done:
 		// Command completed normally
 	}
@@ -184,30 +203,43 @@ func RunCommand(name string, args []string, opts CommandOptions) (*CommandResult
 	return result, nil
 }
 
+// signalProcessGroup sends sig to the command's process group, or to the process alone if the group is unknown
+func signalProcessGroup(cmd *exec.Cmd, sig syscall.Signal) {
+	if cmd.Process == nil {
+		return
+	}
+	// Negative PID signals all processes in the group
+	if pgid, err := syscall.Getpgid(cmd.Process.Pid); err == nil {
+		_ = syscall.Kill(-pgid, sig)
+		return
+	}
+	_ = cmd.Process.Signal(sig)
+}
+
 // Run is a simple wrapper to run a command and get the result
 func Run(name string, args ...string) (*CommandResult, error) {
-	return RunCommand(name, args, DefaultOptions())
+	return RunCommand(context.Background(), name, args, DefaultOptions())
 }
 
 // RunWithTimeout runs a command with a timeout
 func RunWithTimeout(timeout time.Duration, name string, args ...string) (*CommandResult, error) {
 	opts := DefaultOptions()
 	opts.Timeout = timeout
-	return RunCommand(name, args, opts)
+	return RunCommand(context.Background(), name, args, opts)
 }
 
 // RunInDir runs a command in a specific directory
 func RunInDir(dir, name string, args ...string) (*CommandResult, error) {
 	opts := DefaultOptions()
 	opts.Dir = dir
-	return RunCommand(name, args, opts)
+	return RunCommand(context.Background(), name, args, opts)
 }
 
 // RunWithEnv runs a command with additional environment variables
 func RunWithEnv(env map[string]string, name string, args ...string) (*CommandResult, error) {
 	opts := DefaultOptions()
 	opts.Env = env
-	return RunCommand(name, args, opts)
+	return RunCommand(context.Background(), name, args, opts)
 }
 
 // RunStreaming runs a command with output streamed to console
@@ -215,7 +247,7 @@ func RunStreaming(name string, args ...string) (*CommandResult, error) {
 	opts := DefaultOptions()
 	opts.StreamOut = true
 	opts.StreamErr = true
-	return RunCommand(name, args, opts)
+	return RunCommand(context.Background(), name, args, opts)
 }
 
 // CommandExists checks if a command exists in PATH
@@ -0,0 +1,25 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRunCommandCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	result, err := RunCommand(ctx, "sleep", []string{"10"}, DefaultOptions())
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected cancellation error, got %v", err)
+	}
+	if !result.Cancelled || result.TimedOut {
+		t.Fatalf("Expected cancelled result, got %+v", result)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatalf("Command was not interrupted promptly")
+	}
+}
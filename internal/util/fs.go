@@ -0,0 +1,27 @@
+package util
+
+import (
+	"io/fs"
+	"path/filepath"
+)
+
+// DirSize returns the total size of all regular files below path.
+// Entries that cannot be read are skipped.
+func DirSize(path string) (int64, error) {
+	var total int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if d == nil {
+				return err // Root itself is unreadable
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total, err
+}
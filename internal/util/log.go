@@ -133,25 +133,9 @@ func (l *Logger) log(level, color, format string, args ...interface{}) {
 		level == LevelSuccess
 
 	if showConsole {
-		// If custom output function is set (TUI mode), use it
+		// If custom output function is set (TUI mode), hand it the plain line
 		if l.outputFunc != nil {
-			// Convert to tview color tags
-			var tuiColor string
-			switch level {
-			case LevelError:
-				tuiColor = "[red]"
-			case LevelWarn:
-				tuiColor = "[yellow]"
-			case LevelSuccess:
-				tuiColor = "[green]"
-			case LevelProgress:
-				tuiColor = "[cyan]"
-			case LevelInfo:
-				tuiColor = "[blue]"
-			default:
-				tuiColor = "[white]"
-			}
-			l.outputFunc(fmt.Sprintf("%s%s[-:-:-]\n", tuiColor, logLine))
+			l.outputFunc(logLine + "\n")
 			return
 		}
 
@@ -172,6 +156,17 @@ func (l *Logger) log(level, color, format string, args ...interface{}) {
 	}
 }
 
+// FileOnly writes a log entry to the log file without showing it on the console
+func (l *Logger) FileOnly(level, format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file != nil {
+		timestamp := time.Now().Format("2006-01-02 15:04:05")
+		fmt.Fprintf(l.file, "[%s] [%s] %s\n", timestamp, level, fmt.Sprintf(format, args...))
+	}
+}
+
 // Debug logs a debug message (only shown in verbose mode)
 func (l *Logger) Debug(format string, args ...interface{}) {
 	l.log(LevelDebug, ColorGray, format, args...)
@@ -281,6 +276,14 @@ func LogError(format string, args ...interface{}) {
 	}
 }
 
+// LogFileOnly writes to the log file of the default logger only, for callers
+// whose console belongs to something else
+func LogFileOnly(level, format string, args ...interface{}) {
+	if defaultLogger != nil {
+		defaultLogger.FileOnly(level, format, args...)
+	}
+}
+
 func LogHeader(title string) {
 	if defaultLogger != nil {
 		defaultLogger.PrintHeader(title)
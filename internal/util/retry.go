@@ -0,0 +1,229 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Retry defaults used when a policy field is left at zero
+const (
+	DefaultRetryAttempts   = 3
+	DefaultRetryBaseDelay  = 30 * time.Second
+	DefaultRetryMaxDelay   = 10 * time.Minute
+	DefaultRetryMultiplier = 2.0
+	DefaultRetryJitter     = 0.2
+)
+
+// RetryPolicy describes how a failing operation is retried
+type RetryPolicy struct {
+	MaxAttempts int           // Total attempts including the first one
+	BaseDelay   time.Duration // Wait before the first retry
+	MaxDelay    time.Duration // Upper bound for any single wait
+	Multiplier  float64       // Growth factor applied per attempt
+	Jitter      float64       // Fraction of the delay randomized (0..1)
+
+	sleep func(context.Context, time.Duration) error // Replaced in tests
+}
+
+// NewRetryPolicy creates a policy with exponential backoff and jitter.
+// Zero or negative values fall back to the package defaults.
+func NewRetryPolicy(attempts int, baseDelay, maxDelay time.Duration) RetryPolicy {
+	if attempts < 1 {
+		attempts = DefaultRetryAttempts
+	}
+	if baseDelay <= 0 {
+		baseDelay = DefaultRetryBaseDelay
+	}
+	if maxDelay <= 0 {
+		maxDelay = DefaultRetryMaxDelay
+	}
+	if maxDelay < baseDelay {
+		maxDelay = baseDelay
+	}
+	return RetryPolicy{
+		MaxAttempts: attempts,
+		BaseDelay:   baseDelay,
+		MaxDelay:    maxDelay,
+		Multiplier:  DefaultRetryMultiplier,
+		Jitter:      DefaultRetryJitter,
+	}
+}
+
+// Delay returns the wait before the retry that follows the given attempt (1-based).
+// The result grows exponentially, is capped at MaxDelay and randomized by Jitter.
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(p.BaseDelay) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		// Spread the delay uniformly over [delay*(1-jitter), delay*(1+jitter)]
+		delay *= 1 - jitter + rand.Float64()*2*jitter // #nosec G404 -- jitter does not need crypto randomness
+		if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+			delay = float64(p.MaxDelay)
+		}
+	}
+
+	return time.Duration(delay)
+}
+
+// Do runs fn until it succeeds, returns a non-retryable error, or the attempts are exhausted.
+// Cancelling ctx stops retrying and interrupts the wait between attempts.
+// The operation name is used for log messages ("Sync attempt 1 of 3").
+func (p RetryPolicy) Do(ctx context.Context, operation string, fn func(attempt int) error) error {
+	attempts := p.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	sleep := p.sleep
+	if sleep == nil {
+		sleep = SleepContext
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		LogProgress("%s attempt %d of %d", operation, attempt, attempts)
+
+		err := fn(attempt)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			LogWarn("%s cancelled", operation)
+			return err
+		}
+		LogWarn("%s attempt %d failed: %v", operation, attempt, err)
+
+		if !IsRetryable(err) {
+			LogError("%s failed with a non-retryable error, giving up", operation)
+			return err
+		}
+
+		if attempt < attempts {
+			wait := p.Delay(attempt)
+			LogInfo("Waiting %v before retry...", wait.Round(time.Second))
+			if err := sleep(ctx, wait); err != nil {
+				LogWarn("%s cancelled while waiting to retry", operation)
+				return fmt.Errorf("%s cancelled: %w", operation, err)
+			}
+		}
+	}
+
+	return fmt.Errorf("%s failed after %d attempts: %w", operation, attempts, lastErr)
+}
+
+// SleepContext waits for d or until ctx is cancelled, returning the context's error in that case
+func SleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// IsRetryable reports whether an error is worth retrying.
+// Errors that carry a Retryable() method decide for themselves; all others are retried.
+func IsRetryable(err error) bool {
+	var r interface{ Retryable() bool }
+	if errors.As(err, &r) {
+		return r.Retryable()
+	}
+	return true
+}
+
+// rclone exit codes (see https://rclone.org/docs/#exit-code)
+const (
+	RcloneExitSuccess         = 0
+	RcloneExitUsage           = 1
+	RcloneExitUncategorised   = 2
+	RcloneExitDirNotFound     = 3
+	RcloneExitFileNotFound    = 4
+	RcloneExitTemporary       = 5
+	RcloneExitNoRetry         = 6
+	RcloneExitFatal           = 7
+	RcloneExitTransferLimit   = 8
+	RcloneExitNoFilesTransfer = 9
+	RcloneExitDurationLimit   = 10
+)
+
+// RcloneExitError is returned when rclone exits with a non-zero status
+type RcloneExitError struct {
+	Operation string
+	ExitCode  int
+	TimedOut  bool
+}
+
+func (e *RcloneExitError) Error() string {
+	if e.TimedOut {
+		return fmt.Sprintf("%s timed out", e.Operation)
+	}
+	return fmt.Sprintf("%s exited with code %d (%s)", e.Operation, e.ExitCode, RcloneExitDescription(e.ExitCode))
+}
+
+// Retryable classifies the exit code: configuration and fatal errors fail fast,
+// transfer and temporary errors are retried.
+func (e *RcloneExitError) Retryable() bool {
+	if e.TimedOut {
+		return true
+	}
+	return RcloneExitRetryable(e.ExitCode)
+}
+
+// RcloneExitRetryable reports whether an rclone exit code is worth retrying
+func RcloneExitRetryable(code int) bool {
+	switch code {
+	case RcloneExitUsage, RcloneExitDirNotFound, RcloneExitFileNotFound,
+		RcloneExitNoRetry, RcloneExitFatal, RcloneExitTransferLimit:
+		return false
+	default:
+		return true
+	}
+}
+
+// RcloneExitDescription returns a short description of an rclone exit code
+func RcloneExitDescription(code int) string {
+	switch code {
+	case RcloneExitSuccess:
+		return "success"
+	case RcloneExitUsage:
+		return "syntax or usage error"
+	case RcloneExitUncategorised:
+		return "uncategorised error"
+	case RcloneExitDirNotFound:
+		return "directory not found"
+	case RcloneExitFileNotFound:
+		return "file not found"
+	case RcloneExitTemporary:
+		return "temporary error"
+	case RcloneExitNoRetry:
+		return "less serious error, not retried"
+	case RcloneExitFatal:
+		return "fatal error"
+	case RcloneExitTransferLimit:
+		return "transfer limit exceeded"
+	case RcloneExitNoFilesTransfer:
+		return "no files transferred"
+	case RcloneExitDurationLimit:
+		return "duration limit exceeded"
+	default:
+		return "unknown error"
+	}
+}
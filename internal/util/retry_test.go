@@ -0,0 +1,122 @@
+package util
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	t.Run("DelayGrowsAndCaps", func(t *testing.T) {
+		p := NewRetryPolicy(5, time.Second, 5*time.Second)
+		p.Jitter = 0
+
+		want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+		for i, w := range want {
+			if got := p.Delay(i + 1); got != w {
+				t.Fatalf("Delay(%d): got %v, want %v", i+1, got, w)
+			}
+		}
+	})
+
+	t.Run("JitterStaysInRange", func(t *testing.T) {
+		p := NewRetryPolicy(3, 10*time.Second, time.Minute)
+		for i := 0; i < 100; i++ {
+			d := p.Delay(1)
+			if d < 8*time.Second || d > 12*time.Second {
+				t.Fatalf("Delay with jitter out of range: %v", d)
+			}
+		}
+	})
+
+	t.Run("RetriesUntilSuccess", func(t *testing.T) {
+		p := NewRetryPolicy(3, time.Second, time.Second)
+		var waits []time.Duration
+		p.sleep = func(_ context.Context, d time.Duration) error {
+			waits = append(waits, d)
+			return nil
+		}
+
+		calls := 0
+		err := p.Do(context.Background(), "Test", func(_ int) error {
+			calls++
+			if calls < 3 {
+				return errors.New("transient")
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Do error: %v", err)
+		}
+		if calls != 3 || len(waits) != 2 {
+			t.Fatalf("Expected 3 calls and 2 waits, got %d calls and %d waits", calls, len(waits))
+		}
+	})
+
+	t.Run("FatalErrorFailsFast", func(t *testing.T) {
+		p := NewRetryPolicy(5, time.Second, time.Second)
+		p.sleep = func(context.Context, time.Duration) error {
+			t.Fatalf("Should not wait after a fatal error")
+			return nil
+		}
+
+		calls := 0
+		err := p.Do(context.Background(), "Test", func(_ int) error {
+			calls++
+			return &RcloneExitError{Operation: "sync", ExitCode: RcloneExitUsage}
+		})
+		if err == nil || calls != 1 {
+			t.Fatalf("Expected one failing call, got %d calls, err=%v", calls, err)
+		}
+	})
+
+	t.Run("CancelStopsRetrying", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		p := NewRetryPolicy(5, time.Hour, time.Hour)
+
+		calls := 0
+		err := p.Do(ctx, "Test", func(_ int) error {
+			calls++
+			cancel()
+			return errors.New("interrupted")
+		})
+		if err == nil || calls != 1 {
+			t.Fatalf("Expected one failing call, got %d calls, err=%v", calls, err)
+		}
+	})
+
+	t.Run("CancelInterruptsWait", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(10*time.Millisecond, cancel)
+		p := NewRetryPolicy(5, time.Hour, time.Hour)
+
+		err := p.Do(ctx, "Test", func(_ int) error {
+			return errors.New("transient")
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected cancellation error, got %v", err)
+		}
+	})
+}
+
+func TestRcloneExitRetryable(t *testing.T) {
+	retryable := []int{RcloneExitUncategorised, RcloneExitTemporary, RcloneExitDurationLimit, -1}
+	fatal := []int{RcloneExitUsage, RcloneExitDirNotFound, RcloneExitFileNotFound, RcloneExitNoRetry, RcloneExitFatal, RcloneExitTransferLimit}
+
+	for _, code := range retryable {
+		if !RcloneExitRetryable(code) {
+			t.Fatalf("Exit code %d should be retryable", code)
+		}
+	}
+	for _, code := range fatal {
+		if RcloneExitRetryable(code) {
+			t.Fatalf("Exit code %d should not be retryable", code)
+		}
+	}
+
+	timedOut := &RcloneExitError{Operation: "sync", ExitCode: -1, TimedOut: true}
+	if !IsRetryable(timedOut) {
+		t.Fatalf("Timed out rclone run should be retryable")
+	}
+}